@@ -0,0 +1,385 @@
+// Package config loads the settings of the app from, in increasing order of
+// precedence, built-in defaults, a YAML or TOML file, environment variables
+// and command line flags.
+package config
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml"
+	"github.com/rtsncs/remitly-swift-api/database"
+	"github.com/rtsncs/remitly-swift-api/ratelimit"
+	"gopkg.in/yaml.v3"
+)
+
+// redacted replaces secrets in printed configs.
+const redacted = "REDACTED"
+
+type Config struct {
+	Server    Server           `yaml:"server" toml:"server"`
+	Database  database.Options `yaml:"database" toml:"database"`
+	Log       Log              `yaml:"log" toml:"log"`
+	Auth      Auth             `yaml:"auth" toml:"auth"`
+	Loader    Loader           `yaml:"loader" toml:"loader"`
+	RateLimit RateLimit        `yaml:"rate_limit" toml:"rate_limit"`
+}
+
+type Server struct {
+	Host string `yaml:"host" toml:"host"`
+	Port int    `yaml:"port" toml:"port"`
+	// GRPCPort is the port of the gRPC API, which is disabled when it's 0.
+	GRPCPort int `yaml:"grpc_port" toml:"grpc_port"`
+	// ShutdownDelay is how long the server reports itself as not ready
+	// before it stops accepting connections.
+	ShutdownDelay Duration `yaml:"shutdown_delay" toml:"shutdown_delay"`
+	// DrainTimeout bounds the time in-flight requests and imports get to
+	// finish on shutdown.
+	DrainTimeout Duration `yaml:"drain_timeout" toml:"drain_timeout"`
+	// ReadYourWrites is how long clients read from the primary database
+	// after a write, so that they don't miss it on a lagging replica.
+	ReadYourWrites Duration `yaml:"read_your_writes" toml:"read_your_writes"`
+}
+
+// Address is the address the server listens on.
+func (s Server) Address() string {
+	return fmt.Sprintf("%s:%d", s.Host, s.Port)
+}
+
+// GRPCAddress is the address the gRPC server listens on.
+func (s Server) GRPCAddress() string {
+	return fmt.Sprintf("%s:%d", s.Host, s.GRPCPort)
+}
+
+type Log struct {
+	// Level is one of debug, info, warn, error or off.
+	Level string `yaml:"level" toml:"level"`
+}
+
+type Auth struct {
+	// AdminAPIKey authorizes requests to the admin routes, which are
+	// disabled when it's empty.
+	AdminAPIKey string `yaml:"admin_api_key" toml:"admin_api_key"`
+}
+
+type Loader struct {
+	// Partial loads only add and update the codes in a file, instead of
+	// closing out the codes missing from it.
+	Partial bool `yaml:"partial" toml:"partial"`
+}
+
+// RateLimit sets how many requests each client, told apart by its API key or
+// IP address, can make to each route group.
+type RateLimit struct {
+	// Store is "memory" to limit clients on each server separately, or
+	// "postgres" to share the limits between servers through the database.
+	Store  string          `yaml:"store" toml:"store"`
+	Lookup ratelimit.Limit `yaml:"lookup" toml:"lookup"`
+	Write  ratelimit.Limit `yaml:"write" toml:"write"`
+	Admin  ratelimit.Limit `yaml:"admin" toml:"admin"`
+	// Keys override the limits of clients sending one of the API keys in
+	// the X-API-Key header. They can only be set in the config file.
+	Keys map[string]ratelimit.Limit `yaml:"keys" toml:"keys"`
+}
+
+// stringList is a comma-separated list of strings set by a flag.
+type stringList []string
+
+func (l *stringList) String() string {
+	if l == nil {
+		return ""
+	}
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = nil
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			*l = append(*l, item)
+		}
+	}
+	return nil
+}
+
+// Duration is a time.Duration written like "10s" in config files.
+type Duration time.Duration
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	parsed, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+func Default() Config {
+	return Config{
+		Server: Server{
+			Port:           8080,
+			DrainTimeout:   Duration(10 * time.Second),
+			ReadYourWrites: Duration(5 * time.Second),
+		},
+		Database: database.Options{StatementTimeout: 5 * time.Second},
+		Log:      Log{Level: "info"},
+		RateLimit: RateLimit{
+			Store:  "memory",
+			Lookup: ratelimit.Limit{Requests: 100, Per: time.Second},
+			Write:  ratelimit.Limit{Requests: 10, Per: time.Second},
+			Admin:  ratelimit.Limit{Requests: 10, Per: time.Minute},
+		},
+	}
+}
+
+// Groups of settings, so that commands only get the flags they use.
+const (
+	GroupServer    = "server"
+	GroupDatabase  = "database"
+	GroupLog       = "log"
+	GroupAuth      = "auth"
+	GroupLoader    = "loader"
+	GroupRateLimit = "rate_limit"
+)
+
+type setting struct {
+	group string
+	flag  string
+	env   string
+	usage string
+	// field returns a pointer to the setting in c.
+	field func(c *Config) any
+}
+
+var settings = []setting{
+	{GroupServer, "host", "HOST", "Host to listen on", func(c *Config) any { return &c.Server.Host }},
+	{GroupServer, "port", "PORT", "Port to listen on", func(c *Config) any { return &c.Server.Port }},
+	{GroupServer, "grpc-port", "GRPC_PORT", "Port of the gRPC API, 0 to disable it", func(c *Config) any { return &c.Server.GRPCPort }},
+	{GroupServer, "shutdown-delay", "SHUTDOWN_DELAY", "How long to report the server as not ready before shutting down", func(c *Config) any { return &c.Server.ShutdownDelay }},
+	{GroupServer, "drain-timeout", "DRAIN_TIMEOUT", "Time in-flight requests and imports get to finish on shutdown", func(c *Config) any { return &c.Server.DrainTimeout }},
+	{GroupDatabase, "database-url", "DATABASE_URL", "PostgreSQL connection string, or sqlite://path", func(c *Config) any { return &c.Database.URL }},
+	{GroupServer, "read-your-writes", "READ_YOUR_WRITES", "How long clients read from the primary database after a write", func(c *Config) any { return &c.Server.ReadYourWrites }},
+	{GroupDatabase, "db-replica-urls", "DATABASE_REPLICA_URLS", "Comma-separated connection strings of read replicas", func(c *Config) any { return &c.Database.ReplicaURLs }},
+	{GroupDatabase, "storage", "STORAGE", `Set to "memory" to keep data in memory instead of a database`, func(c *Config) any { return &c.Database.Storage }},
+	{GroupDatabase, "db-max-conns", "DATABASE_MAX_CONNS", "Maximum size of the connection pool (0 for the default)", func(c *Config) any { return &c.Database.MaxConns }},
+	{GroupDatabase, "db-min-conns", "DATABASE_MIN_CONNS", "Minimum size of the connection pool", func(c *Config) any { return &c.Database.MinConns }},
+	{GroupDatabase, "db-max-conn-lifetime", "DATABASE_MAX_CONN_LIFETIME", "Time after which pooled connections are closed (0 for the default)", func(c *Config) any { return &c.Database.MaxConnLifetime }},
+	{GroupDatabase, "db-max-conn-idle-time", "DATABASE_MAX_CONN_IDLE_TIME", "Time after which idle pooled connections are closed (0 for the default)", func(c *Config) any { return &c.Database.MaxConnIdleTime }},
+	{GroupDatabase, "db-health-check-period", "DATABASE_HEALTH_CHECK_PERIOD", "How often idle pooled connections are checked (0 for the default)", func(c *Config) any { return &c.Database.HealthCheckPeriod }},
+	{GroupDatabase, "db-statement-timeout", "DATABASE_STATEMENT_TIMEOUT", "Time limit of each database operation (0 for none)", func(c *Config) any { return &c.Database.StatementTimeout }},
+	{GroupLog, "log-level", "LOG_LEVEL", "Log level: debug, info, warn, error or off", func(c *Config) any { return &c.Log.Level }},
+	{GroupAuth, "admin-api-key", "ADMIN_API_KEY", "API key of the admin routes, which are disabled when empty", func(c *Config) any { return &c.Auth.AdminAPIKey }},
+	{GroupRateLimit, "rate-limit-store", "RATE_LIMIT_STORE", `Where rate limits are kept: "memory" or "postgres" to share them between servers`, func(c *Config) any { return &c.RateLimit.Store }},
+	{GroupRateLimit, "rate-limit-lookup", "RATE_LIMIT_LOOKUP", "Requests each client can make to the lookup routes, like 100/1s (0 for no limit)", func(c *Config) any { return &c.RateLimit.Lookup }},
+	{GroupRateLimit, "rate-limit-write", "RATE_LIMIT_WRITE", "Requests each client can make to the write routes, like 10/1s (0 for no limit)", func(c *Config) any { return &c.RateLimit.Write }},
+	{GroupRateLimit, "rate-limit-admin", "RATE_LIMIT_ADMIN", "Requests each client can make to the admin routes, like 10/1m (0 for no limit)", func(c *Config) any { return &c.RateLimit.Admin }},
+	{GroupLoader, "partial", "LOADER_PARTIAL", "Only add and update the codes in the file, instead of closing out codes missing from it", func(c *Config) any { return &c.Loader.Partial }},
+}
+
+// bind registers the settings of the groups as flags of fs that set the
+// fields of c. All settings are registered when no groups are given.
+func bind(fs *flag.FlagSet, c *Config, groups []string) {
+	for _, s := range settings {
+		if len(groups) > 0 && !slices.Contains(groups, s.group) {
+			continue
+		}
+		usage := fmt.Sprintf("%s (env %s)", s.usage, s.env)
+		switch p := s.field(c).(type) {
+		case *string:
+			fs.StringVar(p, s.flag, *p, usage)
+		case *int:
+			fs.IntVar(p, s.flag, *p, usage)
+		case *bool:
+			fs.BoolVar(p, s.flag, *p, usage)
+		case *Duration:
+			fs.DurationVar((*time.Duration)(p), s.flag, time.Duration(*p), usage)
+		case *time.Duration:
+			fs.DurationVar(p, s.flag, *p, usage)
+		case *[]string:
+			fs.Var((*stringList)(p), s.flag, usage)
+		case *ratelimit.Limit:
+			fs.TextVar(p, s.flag, *p, usage)
+		}
+	}
+}
+
+// Load parses the command line flags in args and returns the effective
+// config. fs gets a -config flag naming the config file, which defaults to
+// the CONFIG_FILE environment variable, and flags for the settings in
+// groups, or all settings when no groups are given.
+func Load(fs *flag.FlagSet, args []string, groups ...string) (Config, error) {
+	fromFlags := Default()
+	bind(fs, &fromFlags, groups)
+	path := fs.String("config", os.Getenv("CONFIG_FILE"), "Path to a YAML or TOML config file (env CONFIG_FILE)")
+	if err := fs.Parse(args); err != nil {
+		return Config{}, err
+	}
+
+	c := Default()
+	if *path != "" {
+		if err := readFile(*path, &c); err != nil {
+			return Config{}, err
+		}
+	}
+
+	values := flag.NewFlagSet("config", flag.ContinueOnError)
+	bind(values, &c, nil)
+	for _, s := range settings {
+		if value := os.Getenv(s.env); value != "" {
+			if err := values.Set(s.flag, value); err != nil {
+				return Config{}, fmt.Errorf("Invalid %s: %w", s.env, err)
+			}
+		}
+	}
+	// Flags of the command that aren't settings, such as -config, are
+	// skipped.
+	var err error
+	fs.Visit(func(f *flag.Flag) {
+		if values.Lookup(f.Name) != nil && err == nil {
+			err = values.Set(f.Name, f.Value.String())
+		}
+	})
+	if err != nil {
+		return Config{}, err
+	}
+
+	return c, c.Validate()
+}
+
+func readFile(path string, c *Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("Failed to read config file: %w", err)
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		err = dec.Decode(c)
+		if err == io.EOF {
+			err = nil
+		}
+	case ".toml":
+		err = toml.NewDecoder(bytes.NewReader(data)).Strict(true).Decode(c)
+	default:
+		return fmt.Errorf("Config file must be a .yaml, .yml or .toml file")
+	}
+	if err != nil {
+		return fmt.Errorf("Failed to parse config file: %w", err)
+	}
+	return nil
+}
+
+func (c Config) Validate() error {
+	if c.Server.Port < 1 || c.Server.Port > 65535 {
+		return fmt.Errorf("port must be between 1 and 65535")
+	}
+	if c.Server.GRPCPort < 0 || c.Server.GRPCPort > 65535 {
+		return fmt.Errorf("gRPC port must be between 0 and 65535")
+	}
+	if c.Server.GRPCPort == c.Server.Port {
+		return fmt.Errorf("gRPC port must differ from port")
+	}
+	if c.Server.ShutdownDelay < 0 || c.Server.DrainTimeout < 0 || c.Server.ReadYourWrites < 0 {
+		return fmt.Errorf("shutdown delay, drain timeout and read-your-writes window must not be negative")
+	}
+	if c.Database.Storage != "" && c.Database.Storage != "memory" {
+		return fmt.Errorf(`storage must be empty or "memory"`)
+	}
+	if c.Database.MaxConns < 0 || c.Database.MinConns < 0 {
+		return fmt.Errorf("connection pool sizes must not be negative")
+	}
+	if c.Database.MaxConns > 0 && c.Database.MinConns > c.Database.MaxConns {
+		return fmt.Errorf("db-min-conns must not exceed db-max-conns")
+	}
+	if c.Database.MaxConnLifetime < 0 || c.Database.MaxConnIdleTime < 0 || c.Database.HealthCheckPeriod < 0 || c.Database.StatementTimeout < 0 {
+		return fmt.Errorf("database durations must not be negative")
+	}
+	switch c.RateLimit.Store {
+	case "memory":
+	case "postgres":
+		if c.Database.Storage != "" || strings.HasPrefix(c.Database.URL, "sqlite://") {
+			return fmt.Errorf("the postgres rate limit store needs a PostgreSQL database")
+		}
+	default:
+		return fmt.Errorf(`rate limit store must be "memory" or "postgres"`)
+	}
+	switch c.Log.Level {
+	case "debug", "info", "warn", "error", "off":
+	default:
+		return fmt.Errorf("log level must be one of debug, info, warn, error or off")
+	}
+	return nil
+}
+
+// Redacted returns a copy of the config with secrets replaced, safe to print.
+func (c Config) Redacted() Config {
+	if c.Auth.AdminAPIKey != "" {
+		c.Auth.AdminAPIKey = redacted
+	}
+	c.Database.URL = redactURL(c.Database.URL)
+	if len(c.RateLimit.Keys) > 0 {
+		keys := make(map[string]ratelimit.Limit, len(c.RateLimit.Keys))
+		for i, key := range slices.Sorted(maps.Keys(c.RateLimit.Keys)) {
+			keys[fmt.Sprintf("%s%d", redacted, i+1)] = c.RateLimit.Keys[key]
+		}
+		c.RateLimit.Keys = keys
+	}
+	if len(c.Database.ReplicaURLs) > 0 {
+		replicas := make([]string, len(c.Database.ReplicaURLs))
+		for i, url := range c.Database.ReplicaURLs {
+			replicas[i] = redactURL(url)
+		}
+		c.Database.ReplicaURLs = replicas
+	}
+	return c
+}
+
+// redactURL replaces the password in a connection string.
+func redactURL(s string) string {
+	u, err := url.Parse(s)
+	if err != nil || u.Scheme == "" {
+		// Keyword/value connection strings aren't URLs.
+		if strings.Contains(s, "password") {
+			return redacted
+		}
+		return s
+	}
+	if _, ok := u.User.Password(); ok {
+		u.User = url.UserPassword(u.User.Username(), redacted)
+	}
+	if query := u.Query(); query.Has("password") {
+		query.Set("password", redacted)
+		u.RawQuery = query.Encode()
+	}
+	return u.String()
+}
+
+// Write prints the config as YAML or TOML.
+func (c Config) Write(w io.Writer, format string) error {
+	switch format {
+	case "yaml":
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(c); err != nil {
+			return err
+		}
+		return enc.Close()
+	case "toml":
+		return toml.NewEncoder(w).Encode(c)
+	}
+	return fmt.Errorf("unsupported format %q", format)
+}
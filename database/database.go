@@ -2,28 +2,138 @@ package database
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/rtsncs/remitly-swift-api/models"
 )
 
 type Database struct {
 	pool *pgxpool.Pool
+	// replicas serve the lookups that don't have to see the latest writes,
+	// when there are any.
+	replicas *replicas
+	// timeout bounds every operation, unless it's zero.
+	timeout time.Duration
 }
 
-func Connect(c context.Context) (Database, error) {
-	connStr := os.Getenv("DATABASE_URL")
-	if connStr == "" {
-		return Database{}, fmt.Errorf("DATABASE_URL is not set")
+// Options select and tune the storage backend.
+type Options struct {
+	// URL is a PostgreSQL connection string, or sqlite://path for an SQLite
+	// database file.
+	URL string `yaml:"url" toml:"url"`
+	// ReplicaURLs are connection strings of PostgreSQL read replicas of the
+	// database at URL.
+	ReplicaURLs []string `yaml:"replica_urls" toml:"replica_urls"`
+	// Storage is "memory" to keep the data in memory instead of a database.
+	Storage string `yaml:"storage" toml:"storage"`
+	// MaxConns and MinConns size the PostgreSQL connection pool. Zero keeps
+	// the pgx defaults.
+	MaxConns int `yaml:"max_conns" toml:"max_conns"`
+	MinConns int `yaml:"min_conns" toml:"min_conns"`
+	// MaxConnLifetime and MaxConnIdleTime close pooled connections that are
+	// too old or unused for too long, and HealthCheckPeriod is how often
+	// the pool checks them. Zero keeps the pgx defaults.
+	MaxConnLifetime   time.Duration `yaml:"max_conn_lifetime" toml:"max_conn_lifetime"`
+	MaxConnIdleTime   time.Duration `yaml:"max_conn_idle_time" toml:"max_conn_idle_time"`
+	HealthCheckPeriod time.Duration `yaml:"health_check_period" toml:"health_check_period"`
+	// StatementTimeout bounds every PostgreSQL operation, except for
+	// ForEachCode, which streams whole tables. Zero disables it.
+	StatementTimeout time.Duration `yaml:"statement_timeout" toml:"statement_timeout"`
+}
+
+// Connect connects to the storage set by the STORAGE and DATABASE_URL
+// environment variables.
+func Connect(c context.Context) (Store, error) {
+	return ConnectWithOptions(c, Options{URL: os.Getenv("DATABASE_URL"), Storage: os.Getenv("STORAGE")})
+}
+
+func ConnectWithOptions(c context.Context, opts Options) (Store, error) {
+	if opts.Storage == "memory" {
+		return NewMemory(), nil
+	}
+
+	if opts.URL == "" {
+		return nil, fmt.Errorf("DATABASE_URL is not set")
+	}
+	if path, ok := strings.CutPrefix(opts.URL, "sqlite://"); ok {
+		db, err := ConnectSQLite(c, path)
+		if err != nil {
+			return nil, err
+		}
+		return &db, nil
 	}
-	return ConnectWithConnString(c, connStr)
+
+	config, err := poolConfig(opts.URL, opts)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid database URL: %w", err)
+	}
+	var replicaConfigs []*pgxpool.Config
+	for _, url := range opts.ReplicaURLs {
+		replicaConfig, err := poolConfig(url, opts)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid replica URL: %w", err)
+		}
+		replicaConfigs = append(replicaConfigs, replicaConfig)
+	}
+
+	db, err := connectWithConfig(c, config)
+	if err != nil {
+		return nil, err
+	}
+	db.timeout = opts.StatementTimeout
+	if len(replicaConfigs) > 0 {
+		db.replicas, err = newReplicas(c, replicaConfigs, opts.HealthCheckPeriod)
+		if err != nil {
+			db.Close()
+			return nil, fmt.Errorf("Unable to create replica connection pool: %w", err)
+		}
+	}
+	return &db, nil
+}
+
+// poolConfig parses a PostgreSQL connection string and applies the pool
+// settings of opts to it.
+func poolConfig(connStr string, opts Options) (*pgxpool.Config, error) {
+	config, err := pgxpool.ParseConfig(connStr)
+	if err != nil {
+		return nil, err
+	}
+	if opts.MaxConns > 0 {
+		config.MaxConns = int32(opts.MaxConns)
+	}
+	if opts.MinConns > 0 {
+		config.MinConns = int32(opts.MinConns)
+	}
+	if opts.MaxConnLifetime > 0 {
+		config.MaxConnLifetime = opts.MaxConnLifetime
+	}
+	if opts.MaxConnIdleTime > 0 {
+		config.MaxConnIdleTime = opts.MaxConnIdleTime
+	}
+	if opts.HealthCheckPeriod > 0 {
+		config.HealthCheckPeriod = opts.HealthCheckPeriod
+	}
+	return config, nil
 }
 
 func ConnectWithConnString(c context.Context, connStr string) (Database, error) {
-	pool, err := pgxpool.New(c, connStr)
+	config, err := pgxpool.ParseConfig(connStr)
+	if err != nil {
+		return Database{}, fmt.Errorf("Invalid database URL: %w", err)
+	}
+	return connectWithConfig(c, config)
+}
+
+func connectWithConfig(c context.Context, config *pgxpool.Config) (Database, error) {
+	pool, err := pgxpool.NewWithConfig(c, config)
 	if err != nil {
 		return Database{}, fmt.Errorf("Unable to create database connection pool: %w", err)
 	}
@@ -31,7 +141,7 @@ func ConnectWithConnString(c context.Context, connStr string) (Database, error)
 		return Database{}, fmt.Errorf("Failed to ping database: %w", err)
 	}
 
-	db := Database{pool}
+	db := Database{pool: pool}
 	if err = db.createTable(c); err != nil {
 		return Database{}, fmt.Errorf("Failed to create table: %w", err)
 	}
@@ -39,7 +149,37 @@ func ConnectWithConnString(c context.Context, connStr string) (Database, error)
 	return db, nil
 }
 
+func (db *Database) Ping(c context.Context) error {
+	return classify(db.pool.Ping(c))
+}
+
+// withTimeout bounds an operation by the statement timeout.
+func (db *Database) withTimeout(c context.Context) (context.Context, context.CancelFunc) {
+	if db.timeout <= 0 {
+		return c, func() {}
+	}
+	return context.WithTimeout(c, db.timeout)
+}
+
+// classify wraps timeouts in ErrTimeout and failures to reach the database
+// in ErrUnavailable.
+func classify(err error) error {
+	var pgErr *pgconn.PgError
+	var connectErr *pgconn.ConnectError
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, context.DeadlineExceeded), pgconn.Timeout(err),
+		errors.As(err, &pgErr) && pgErr.Code == "57014":
+		return fmt.Errorf("%w: %w", ErrTimeout, err)
+	case errors.As(err, &connectErr):
+		return fmt.Errorf("%w: %w", ErrUnavailable, err)
+	}
+	return err
+}
+
 func (db *Database) Close() {
+	db.replicas.close()
 	db.pool.Close()
 }
 
@@ -47,19 +187,72 @@ func (db *Database) createTable(c context.Context) error {
 	sql := `
 	CREATE TABLE IF NOT EXISTS swift_codes (
 		id SERIAL PRIMARY KEY,
-		swift_code VARCHAR(11) UNIQUE NOT NULL,
+		swift_code VARCHAR(11) NOT NULL,
 		bank_name TEXT NOT NULL,
 		address TEXT,
 		country_iso2 CHAR(2) NOT NULL,
 		country_name TEXT NOT NULL,
 		is_headquarter BOOLEAN NOT NULL
 	);
+	ALTER TABLE swift_codes
+		ADD COLUMN IF NOT EXISTS is_test BOOLEAN GENERATED ALWAYS AS (substr(swift_code, 8, 1) = '0') STORED,
+		ADD COLUMN IF NOT EXISTS is_passive BOOLEAN GENERATED ALWAYS AS (substr(swift_code, 8, 1) = '1') STORED,
+		ADD COLUMN IF NOT EXISTS is_reverse_billing BOOLEAN GENERATED ALWAYS AS (substr(swift_code, 8, 1) = '2') STORED;
+	CREATE TABLE IF NOT EXISTS bank_identifiers (
+		scheme TEXT NOT NULL,
+		identifier TEXT NOT NULL,
+		swift_code VARCHAR(11) NOT NULL,
+		PRIMARY KEY (scheme, identifier, swift_code)
+	);
+	DO $$
+	BEGIN
+		-- The bank codes of IBANs are stored with the other national
+		-- identifiers, under the scheme of models.BankCodeScheme.
+		IF to_regclass('bank_codes') IS NOT NULL THEN
+			INSERT INTO bank_identifiers (scheme, identifier, swift_code)
+				SELECT CASE country_iso2 WHEN 'DE' THEN 'blz' ELSE 'iban-' || lower(country_iso2) END, bank_code, swift_code
+				FROM bank_codes
+				ON CONFLICT DO NOTHING;
+			DROP TABLE bank_codes;
+		END IF;
+	END $$;
+	CREATE INDEX IF NOT EXISTS swift_codes_code_prefix_idx ON swift_codes (swift_code text_pattern_ops);
+	ALTER TABLE swift_codes
+		ADD COLUMN IF NOT EXISTS valid_from DATE,
+		ADD COLUMN IF NOT EXISTS valid_to DATE,
+		DROP CONSTRAINT IF EXISTS swift_codes_swift_code_key;
+	CREATE UNIQUE INDEX IF NOT EXISTS swift_codes_current_code_idx ON swift_codes (swift_code) WHERE valid_to IS NULL;
+	CREATE INDEX IF NOT EXISTS swift_codes_bank_name_prefix_idx ON swift_codes (upper(bank_name) text_pattern_ops);
+	ALTER TABLE swift_codes
+		ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1,
+		ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT now();
+	CREATE TABLE IF NOT EXISTS idempotency_records (
+		key TEXT PRIMARY KEY,
+		request_hash TEXT NOT NULL,
+		status INTEGER NOT NULL,
+		content_type TEXT NOT NULL,
+		body BYTEA,
+		created_at TIMESTAMPTZ NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idempotency_records_created_at_idx ON idempotency_records (created_at);
+	ALTER TABLE idempotency_records ADD COLUMN IF NOT EXISTS header JSONB;
+	CREATE TABLE IF NOT EXISTS swift_code_changes (
+		id BIGSERIAL PRIMARY KEY,
+		type TEXT NOT NULL,
+		swift_code VARCHAR(11) NOT NULL,
+		version INTEGER NOT NULL,
+		record JSONB,
+		changed_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	);
 	`
 	_, err := db.pool.Exec(c, sql)
 	return err
 }
 
 func (db *Database) InsertCode(c context.Context, code models.SwiftCode) error {
+	c, cancel := db.withTimeout(c)
+	defer cancel()
 	sql := `
 	INSERT INTO swift_codes (
 		swift_code,
@@ -67,16 +260,214 @@ func (db *Database) InsertCode(c context.Context, code models.SwiftCode) error {
 		address,
 		country_iso2,
 		country_name,
-		is_headquarter
+		is_headquarter,
+		valid_from,
+		valid_to,
+		version
 	) VALUES (
-		$1, $2, $3, $4, $5, $6
-	);
+		$1, $2, $3, $4, $5, $6, $7, $8,
+		COALESCE((SELECT max(version) FROM swift_codes WHERE swift_code = $1), 0) + 1
+	) RETURNING version;
 	`
-	_, err := db.pool.Exec(c, sql, code.SwiftCode, code.BankName, code.Address, code.CountryISO2, code.CountryName, code.IsHeadquarter)
+	return db.changeTx(c, func(tx pgx.Tx) error {
+		var version int
+		err := tx.QueryRow(c, sql, code.SwiftCode, code.BankName, code.Address, code.CountryISO2, code.CountryName, code.IsHeadquarter, nullableDate(code.ValidFrom), nullableDate(code.ValidTo)).Scan(&version)
+		if err != nil {
+			return insertError(err)
+		}
+		return recordChange(c, tx, models.ChangeCreated, code.SwiftCode, version, changeRecord(code))
+	})
+}
+
+func insertError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return ErrDuplicate
+	}
+	return classify(err)
+}
+
+// changeTx runs fn in a transaction that may record changes.
+func (db *Database) changeTx(c context.Context, fn func(tx pgx.Tx) error) error {
+	return classify(pgx.BeginFunc(c, db.pool, fn))
+}
+
+// recordChange adds a change to the change feed. It must be the last write
+// of the transaction: it takes the feed's advisory lock, held until the
+// transaction ends, before the change gets its ID, so that changes commit in
+// the order of their IDs and consumers resuming after an ID never skip a
+// change committed later. Writers only wait on each other for the insert
+// and the commit, not for their other work.
+func recordChange(c context.Context, tx pgx.Tx, changeType models.ChangeType, code string, version int, record *models.SwiftCode) error {
+	var recordJSON any
+	if record != nil {
+		b, err := json.Marshal(record)
+		if err != nil {
+			return err
+		}
+		recordJSON = string(b)
+	}
+	if _, err := tx.Exec(c, `SELECT pg_advisory_xact_lock(hashtext('swift_code_changes'));`); err != nil {
+		return err
+	}
+	sql := `
+	INSERT INTO swift_code_changes (type, swift_code, version, record)
+	VALUES ($1, $2, $3, $4::jsonb);
+	`
+	_, err := tx.Exec(c, sql, string(changeType), code, version, recordJSON)
 	return err
 }
 
 func (db *Database) GetByCode(c context.Context, code string) (models.SwiftCode, error) {
+	return db.GetByCodeAsOf(c, code, time.Time{})
+}
+
+func (db *Database) GetByCodeAsOf(c context.Context, code string, asOf time.Time) (models.SwiftCode, error) {
+	c, cancel := db.withTimeout(c)
+	defer cancel()
+	sql := `
+	SELECT
+		swift_code,
+		bank_name,
+		address,
+		country_iso2,
+		country_name,
+		is_headquarter,
+		is_test,
+		is_passive,
+		is_reverse_billing,
+		COALESCE(to_char(valid_from, 'YYYY-MM-DD'), '') AS valid_from,
+		COALESCE(to_char(valid_to, 'YYYY-MM-DD'), '') AS valid_to,
+		version,
+		created_at,
+		updated_at
+	FROM swift_codes
+	WHERE swift_code = $1 AND CASE WHEN $2::date IS NULL THEN valid_to IS NULL
+		ELSE (valid_from IS NULL OR valid_from <= $2::date) AND (valid_to IS NULL OR valid_to > $2::date) END;
+	`
+	var swiftCode models.SwiftCode
+	err := db.read(c, func(pool *pgxpool.Pool) error {
+		rows, err := pool.Query(c, sql, code, dateArg(asOf))
+		if err != nil {
+			return classify(err)
+		}
+		swiftCode, err = pgx.CollectOneRow(rows, pgx.RowToStructByName[models.SwiftCode])
+		return classify(err)
+	})
+	return swiftCode, err
+}
+
+func (db *Database) GetByCodes(c context.Context, codes []string) ([]models.SwiftCode, error) {
+	c, cancel := db.withTimeout(c)
+	defer cancel()
+	sql := `
+	SELECT
+		swift_code,
+		bank_name,
+		address,
+		country_iso2,
+		country_name,
+		is_headquarter,
+		is_test,
+		is_passive,
+		is_reverse_billing,
+		COALESCE(to_char(valid_from, 'YYYY-MM-DD'), '') AS valid_from,
+		COALESCE(to_char(valid_to, 'YYYY-MM-DD'), '') AS valid_to,
+		version,
+		created_at,
+		updated_at
+	FROM swift_codes
+	WHERE swift_code = ANY($1) AND valid_to IS NULL
+	ORDER BY swift_code;
+	`
+	var swiftCodes []models.SwiftCode
+	err := db.read(c, func(pool *pgxpool.Pool) error {
+		rows, err := pool.Query(c, sql, codes)
+		if err != nil {
+			return classify(err)
+		}
+		swiftCodes, err = pgx.CollectRows(rows, pgx.RowToStructByName[models.SwiftCode])
+		return classify(err)
+	})
+	return swiftCodes, err
+}
+
+func (db *Database) GetBranches(c context.Context, headquaterCode string) ([]models.SwiftCode, error) {
+	return db.GetBranchesAsOf(c, headquaterCode, time.Time{})
+}
+
+func (db *Database) GetBranchesAsOf(c context.Context, headquaterCode string, asOf time.Time) ([]models.SwiftCode, error) {
+	c, cancel := db.withTimeout(c)
+	defer cancel()
+	sql := `
+	SELECT
+		swift_code,
+		bank_name,
+		address,
+		country_iso2,
+		is_headquarter,
+		is_test,
+		is_passive,
+		is_reverse_billing,
+		COALESCE(to_char(valid_from, 'YYYY-MM-DD'), '') AS valid_from,
+		COALESCE(to_char(valid_to, 'YYYY-MM-DD'), '') AS valid_to,
+		version,
+		created_at,
+		updated_at
+	FROM swift_codes
+	WHERE LEFT(swift_code, 8) = $1 AND NOT swift_code LIKE '%XXX'
+		AND CASE WHEN $2::date IS NULL THEN valid_to IS NULL
+		ELSE (valid_from IS NULL OR valid_from <= $2::date) AND (valid_to IS NULL OR valid_to > $2::date) END;
+	`
+	var codes []models.SwiftCode
+	err := db.read(c, func(pool *pgxpool.Pool) error {
+		rows, err := pool.Query(c, sql, headquaterCode[:8], dateArg(asOf))
+		if err != nil {
+			return classify(err)
+		}
+		codes, err = pgx.CollectRows(rows, pgx.RowToStructByNameLax[models.SwiftCode])
+		return classify(err)
+	})
+	return codes, err
+}
+
+func (db *Database) GetBranchesOf(c context.Context, headquarterCodes []string) ([]models.SwiftCode, error) {
+	c, cancel := db.withTimeout(c)
+	defer cancel()
+	sql := `
+	SELECT
+		swift_code,
+		bank_name,
+		address,
+		country_iso2,
+		is_headquarter,
+		is_test,
+		is_passive,
+		is_reverse_billing,
+		COALESCE(to_char(valid_from, 'YYYY-MM-DD'), '') AS valid_from,
+		COALESCE(to_char(valid_to, 'YYYY-MM-DD'), '') AS valid_to,
+		version,
+		created_at,
+		updated_at
+	FROM swift_codes
+	WHERE LEFT(swift_code, 8) = ANY($1) AND NOT swift_code LIKE '%XXX' AND valid_to IS NULL
+	ORDER BY swift_code;
+	`
+	var codes []models.SwiftCode
+	err := db.read(c, func(pool *pgxpool.Pool) error {
+		rows, err := pool.Query(c, sql, branchPrefixes(headquarterCodes))
+		if err != nil {
+			return classify(err)
+		}
+		codes, err = pgx.CollectRows(rows, pgx.RowToStructByNameLax[models.SwiftCode])
+		return classify(err)
+	})
+	return codes, err
+}
+
+func (db *Database) GetByCodePrefix(c context.Context, prefix string, limit int, filter Filter) ([]models.SwiftCode, error) {
+	c, cancel := db.withTimeout(c)
+	defer cancel()
 	sql := `
 	SELECT
 		swift_code,
@@ -84,73 +475,469 @@ func (db *Database) GetByCode(c context.Context, code string) (models.SwiftCode,
 		address,
 		country_iso2,
 		country_name,
-		is_headquarter
+		is_headquarter,
+		is_test,
+		is_passive,
+		is_reverse_billing,
+		COALESCE(to_char(valid_from, 'YYYY-MM-DD'), '') AS valid_from,
+		COALESCE(to_char(valid_to, 'YYYY-MM-DD'), '') AS valid_to
 	FROM swift_codes
-	WHERE swift_code = $1;
+	WHERE swift_code LIKE $1 || '%'
+		AND ($3::boolean IS NULL OR is_test = $3)
+		AND ($4::boolean IS NULL OR is_passive = $4)
+		AND ($5::boolean IS NULL OR is_reverse_billing = $5)
+		AND CASE WHEN $6::date IS NULL THEN valid_to IS NULL
+		ELSE (valid_from IS NULL OR valid_from <= $6::date) AND (valid_to IS NULL OR valid_to > $6::date) END
+	ORDER BY swift_code
+	LIMIT $2;
 	`
-	rows, err := db.pool.Query(c, sql, code)
+	rows, err := db.pool.Query(c, sql, escapeLike(prefix), limit, filter.IsTest, filter.IsPassive, filter.IsReverseBilling, dateArg(filter.AsOf))
 	if err != nil {
-		return models.SwiftCode{}, err
+		return nil, classify(err)
 	}
 
-	return pgx.CollectOneRow(rows, pgx.RowToStructByName[models.SwiftCode])
+	codes, err := pgx.CollectRows(rows, pgx.RowToStructByNameLax[models.SwiftCode])
+	return codes, classify(err)
 }
 
-func (db *Database) GetBranches(c context.Context, headquaterCode string) ([]models.SwiftCode, error) {
+func (db *Database) GetByBankNamePrefix(c context.Context, prefix string, limit int, filter Filter) ([]models.SwiftCode, error) {
+	c, cancel := db.withTimeout(c)
+	defer cancel()
 	sql := `
 	SELECT
 		swift_code,
 		bank_name,
 		address,
 		country_iso2,
-		is_headquarter
+		country_name,
+		is_headquarter,
+		is_test,
+		is_passive,
+		is_reverse_billing,
+		COALESCE(to_char(valid_from, 'YYYY-MM-DD'), '') AS valid_from,
+		COALESCE(to_char(valid_to, 'YYYY-MM-DD'), '') AS valid_to
 	FROM swift_codes
-	WHERE LEFT(swift_code, 8) = $1 AND NOT swift_code LIKE '%XXX';
+	WHERE upper(bank_name) LIKE upper($1) || '%'
+		AND ($3::boolean IS NULL OR is_test = $3)
+		AND ($4::boolean IS NULL OR is_passive = $4)
+		AND ($5::boolean IS NULL OR is_reverse_billing = $5)
+		AND CASE WHEN $6::date IS NULL THEN valid_to IS NULL
+		ELSE (valid_from IS NULL OR valid_from <= $6::date) AND (valid_to IS NULL OR valid_to > $6::date) END
+	ORDER BY upper(bank_name), swift_code
+	LIMIT $2;
 	`
-	rows, err := db.pool.Query(c, sql, headquaterCode[:8])
+	rows, err := db.pool.Query(c, sql, escapeLike(prefix), limit, filter.IsTest, filter.IsPassive, filter.IsReverseBilling, dateArg(filter.AsOf))
 	if err != nil {
-		return nil, err
+		return nil, classify(err)
 	}
 
-	return pgx.CollectRows(rows, pgx.RowToStructByNameLax[models.SwiftCode])
+	codes, err := pgx.CollectRows(rows, pgx.RowToStructByNameLax[models.SwiftCode])
+	return codes, classify(err)
 }
 
 func (db *Database) GetCountryName(c context.Context, countryCode string) (string, error) {
+	c, cancel := db.withTimeout(c)
+	defer cancel()
 	sql := `
 	SELECT country_name
 	FROM swift_codes
-	WHERE country_iso2 = $1
+	WHERE country_iso2 = $1 AND valid_to IS NULL
 	LIMIT 1;
 	`
 	var name string
-	err := db.pool.QueryRow(c, sql, countryCode).Scan(&name)
+	err := db.read(c, func(pool *pgxpool.Pool) error {
+		return classify(pool.QueryRow(c, sql, countryCode).Scan(&name))
+	})
 	return name, err
 }
 
-func (db *Database) GetByCountryCode(c context.Context, countryCode string) ([]models.SwiftCode, error) {
+func (db *Database) GetCountryNames(c context.Context, countryCodes []string) (map[string]string, error) {
+	c, cancel := db.withTimeout(c)
+	defer cancel()
+	sql := `
+	SELECT DISTINCT ON (country_iso2) country_iso2, country_name
+	FROM swift_codes
+	WHERE country_iso2 = ANY($1) AND valid_to IS NULL;
+	`
+	names := map[string]string{}
+	err := db.read(c, func(pool *pgxpool.Pool) error {
+		rows, err := pool.Query(c, sql, countryCodes)
+		if err != nil {
+			return classify(err)
+		}
+		var iso2, name string
+		_, err = pgx.ForEachRow(rows, []any{&iso2, &name}, func() error {
+			names[iso2] = name
+			return nil
+		})
+		return classify(err)
+	})
+	return names, err
+}
+
+func (db *Database) GetByCountryCode(c context.Context, countryCode string, filter Filter) ([]models.SwiftCode, error) {
+	c, cancel := db.withTimeout(c)
+	defer cancel()
+	sql := `
+	SELECT
+		swift_code,
+		bank_name,
+		address,
+		country_iso2,
+		is_headquarter,
+		is_test,
+		is_passive,
+		is_reverse_billing,
+		COALESCE(to_char(valid_from, 'YYYY-MM-DD'), '') AS valid_from,
+		COALESCE(to_char(valid_to, 'YYYY-MM-DD'), '') AS valid_to,
+		version,
+		created_at,
+		updated_at
+	FROM swift_codes
+	WHERE country_iso2 = $1
+		AND ($2::boolean IS NULL OR is_test = $2)
+		AND ($3::boolean IS NULL OR is_passive = $3)
+		AND ($4::boolean IS NULL OR is_reverse_billing = $4)
+		AND CASE WHEN $5::date IS NULL THEN valid_to IS NULL
+		ELSE (valid_from IS NULL OR valid_from <= $5::date) AND (valid_to IS NULL OR valid_to > $5::date) END;
+	`
+	var codes []models.SwiftCode
+	err := db.read(c, func(pool *pgxpool.Pool) error {
+		rows, err := pool.Query(c, sql, countryCode, filter.IsTest, filter.IsPassive, filter.IsReverseBilling, dateArg(filter.AsOf))
+		if err != nil {
+			return classify(err)
+		}
+		codes, err = pgx.CollectRows(rows, pgx.RowToStructByNameLax[models.SwiftCode])
+		return classify(err)
+	})
+	return codes, err
+}
+
+func (db *Database) DeleteByCode(c context.Context, code string) (int64, error) {
+	return db.CloseByCode(c, code, today())
+}
+
+func (db *Database) CloseByCode(c context.Context, code string, validTo time.Time) (int64, error) {
+	c, cancel := db.withTimeout(c)
+	defer cancel()
+	sql := `
+	SELECT version
+	FROM swift_codes
+	WHERE swift_code = $1 AND valid_to IS NULL
+	FOR UPDATE;
+	`
+	var closed int64
+	err := db.changeTx(c, func(tx pgx.Tx) error {
+		var current int
+		err := tx.QueryRow(c, sql, code).Scan(&current)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		version, err := closeReplaced(c, tx, code, current, validTo)
+		if err != nil {
+			return err
+		}
+		closed = 1
+		return recordChange(c, tx, models.ChangeDeleted, code, version, nil)
+	})
+	return closed, err
+}
+
+func (db *Database) CloseByCodeAtVersion(c context.Context, code string, version int, validTo time.Time) error {
+	c, cancel := db.withTimeout(c)
+	defer cancel()
+	return db.changeTx(c, func(tx pgx.Tx) error {
+		closed, err := closeAtVersion(c, tx, code, version, validTo)
+		if err != nil {
+			return err
+		}
+		return recordChange(c, tx, models.ChangeDeleted, code, closed, nil)
+	})
+}
+
+func (db *Database) ReplaceCode(c context.Context, code models.SwiftCode, version int) error {
+	validTo, err := replacedUntil(code)
+	if err != nil {
+		return err
+	}
+	c, cancel := db.withTimeout(c)
+	defer cancel()
+	sql := `
+	INSERT INTO swift_codes (
+		swift_code,
+		bank_name,
+		address,
+		country_iso2,
+		country_name,
+		is_headquarter,
+		valid_from,
+		valid_to,
+		version
+	) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	RETURNING version;
+	`
+	return db.changeTx(c, func(tx pgx.Tx) error {
+		closed, err := closeReplaced(c, tx, code.SwiftCode, version, validTo)
+		if err != nil {
+			return err
+		}
+		var inserted int
+		err = tx.QueryRow(c, sql, code.SwiftCode, code.BankName, code.Address, code.CountryISO2, code.CountryName, code.IsHeadquarter, nullableDate(code.ValidFrom), nullableDate(code.ValidTo), closed+1).Scan(&inserted)
+		if err != nil {
+			return insertError(err)
+		}
+		return recordChange(c, tx, models.ChangeUpdated, code.SwiftCode, inserted, changeRecord(code))
+	})
+}
+
+// closeReplaced retires the current record of code as of validTo, after
+// locking it and checking that it is still at version and became valid by
+// then, and returns the version of the retired record.
+func closeReplaced(c context.Context, tx pgx.Tx, code string, version int, validTo time.Time) (int, error) {
+	var validFrom string
+	var current int
+	sql := `
+	SELECT COALESCE(to_char(valid_from, 'YYYY-MM-DD'), ''), version
+	FROM swift_codes
+	WHERE swift_code = $1 AND valid_to IS NULL
+	FOR UPDATE;
+	`
+	if err := tx.QueryRow(c, sql, code).Scan(&validFrom, &current); err != nil {
+		return 0, err
+	}
+	if current != version {
+		return 0, ErrVersionMismatch
+	}
+	if err := checkReplaced(validFrom, validTo); err != nil {
+		return 0, err
+	}
+	sql = `
+	UPDATE swift_codes SET valid_to = $2::date, version = version + 1, updated_at = now()
+	WHERE swift_code = $1 AND valid_to IS NULL
+	RETURNING version;
+	`
+	var closed int
+	err := tx.QueryRow(c, sql, code, dateArg(validTo)).Scan(&closed)
+	return closed, err
+}
+
+// closeAtVersion retires the current record of code if it is still at
+// version, and returns the version of the retired record.
+func closeAtVersion(c context.Context, tx pgx.Tx, code string, version int, validTo time.Time) (int, error) {
+	sql := `
+	UPDATE swift_codes SET valid_to = $3::date, version = version + 1, updated_at = now()
+	WHERE swift_code = $1 AND valid_to IS NULL AND version = $2
+	RETURNING version;
+	`
+	var closed int
+	err := tx.QueryRow(c, sql, code, version, dateArg(validTo)).Scan(&closed)
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return closed, err
+	}
+	// Tell a changed record apart from a missing one.
+	var current int
+	sql = `SELECT version FROM swift_codes WHERE swift_code = $1 AND valid_to IS NULL;`
+	if err := tx.QueryRow(c, sql, code).Scan(&current); err != nil {
+		return 0, err
+	}
+	return 0, ErrVersionMismatch
+}
+
+func (db *Database) GetChanges(c context.Context, since int64, limit int) ([]models.Change, error) {
+	c, cancel := db.withTimeout(c)
+	defer cancel()
+	// Changes are read from the primary, which replicas may lag behind,
+	// since a consumer would otherwise skip the changes it hasn't seen yet.
+	sql := `
+	SELECT id, type, swift_code, version, record, changed_at
+	FROM swift_code_changes
+	WHERE id > $1
+	ORDER BY id
+	LIMIT $2;
+	`
+	rows, err := db.pool.Query(c, sql, since, limit)
+	if err != nil {
+		return nil, classify(err)
+	}
+	changes, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.Change, error) {
+		var change models.Change
+		err := row.Scan(&change.ID, &change.Type, &change.SwiftCode, &change.Version, &change.Record, &change.ChangedAt)
+		return change, err
+	})
+	return changes, classify(err)
+}
+
+func (db *Database) LastModified(c context.Context, countryCode, codePrefix string) (time.Time, error) {
+	c, cancel := db.withTimeout(c)
+	defer cancel()
+	sql := `
+	SELECT max(updated_at)
+	FROM swift_codes
+	WHERE country_iso2 = $1 AND swift_code LIKE $2 || '%';
+	`
+	var modified *time.Time
+	err := db.read(c, func(pool *pgxpool.Pool) error {
+		return classify(pool.QueryRow(c, sql, countryCode, escapeLike(codePrefix)).Scan(&modified))
+	})
+	if err != nil {
+		return time.Time{}, err
+	}
+	if modified == nil {
+		return time.Time{}, ErrNotFound
+	}
+	return *modified, nil
+}
+
+func (db *Database) ForEachCode(c context.Context, countryCode string, fn func(models.SwiftCode) error) error {
 	sql := `
 	SELECT
 		swift_code,
 		bank_name,
 		address,
 		country_iso2,
-		is_headquarter
+		country_name,
+		is_headquarter,
+		is_test,
+		is_passive,
+		is_reverse_billing,
+		COALESCE(to_char(valid_from, 'YYYY-MM-DD'), '') AS valid_from,
+		COALESCE(to_char(valid_to, 'YYYY-MM-DD'), '') AS valid_to
 	FROM swift_codes
-	WHERE country_iso2 = $1;
+	WHERE ($1::text = '' OR country_iso2 = $1::text) AND valid_to IS NULL
+	ORDER BY id;
 	`
 	rows, err := db.pool.Query(c, sql, countryCode)
 	if err != nil {
-		return nil, err
+		return classify(err)
 	}
 
-	return pgx.CollectRows(rows, pgx.RowToStructByNameLax[models.SwiftCode])
+	var code models.SwiftCode
+	_, err = pgx.ForEachRow(rows, []any{
+		&code.SwiftCode,
+		&code.BankName,
+		&code.Address,
+		&code.CountryISO2,
+		&code.CountryName,
+		&code.IsHeadquarter,
+		&code.IsTest,
+		&code.IsPassive,
+		&code.IsReverseBilling,
+		&code.ValidFrom,
+		&code.ValidTo,
+	}, func() error {
+		return fn(code)
+	})
+	return classify(err)
 }
 
-func (db *Database) DeleteByCode(c context.Context, code string) (int64, error) {
-	sql := `DELETE FROM swift_codes WHERE swift_code = $1;`
-	tag, err := db.pool.Exec(c, sql, code)
+func (db *Database) InsertNationalID(c context.Context, id models.NationalID) error {
+	c, cancel := db.withTimeout(c)
+	defer cancel()
+	sql := `
+	INSERT INTO bank_identifiers (
+		scheme,
+		identifier,
+		swift_code
+	) VALUES (
+		$1, $2, $3
+	);
+	`
+	_, err := db.pool.Exec(c, sql, id.Scheme, id.Identifier, id.SwiftCode)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return ErrDuplicate
+	}
+	return classify(err)
+}
+
+func (db *Database) GetByNationalID(c context.Context, scheme string, ids ...string) ([]models.SwiftCode, error) {
+	c, cancel := db.withTimeout(c)
+	defer cancel()
+	sql := `
+	SELECT DISTINCT
+		s.swift_code,
+		s.bank_name,
+		s.address,
+		s.country_iso2,
+		s.country_name,
+		s.is_headquarter,
+		s.is_test,
+		s.is_passive,
+		s.is_reverse_billing,
+		COALESCE(to_char(s.valid_from, 'YYYY-MM-DD'), '') AS valid_from,
+		COALESCE(to_char(s.valid_to, 'YYYY-MM-DD'), '') AS valid_to
+	FROM bank_identifiers b
+	JOIN swift_codes s ON s.swift_code = b.swift_code AND s.valid_to IS NULL
+	WHERE b.scheme = $1 AND b.identifier = ANY($2)
+	ORDER BY s.swift_code;
+	`
+	rows, err := db.pool.Query(c, sql, scheme, ids)
 	if err != nil {
-		return 0, err
+		return nil, classify(err)
+	}
+
+	codes, err := pgx.CollectRows(rows, pgx.RowToStructByNameLax[models.SwiftCode])
+	return codes, classify(err)
+}
+
+func (db *Database) InsertIdempotencyRecord(c context.Context, record models.IdempotencyRecord, expiredBefore time.Time) error {
+	c, cancel := db.withTimeout(c)
+	defer cancel()
+	if _, err := db.pool.Exec(c, `DELETE FROM idempotency_records WHERE created_at < $1;`, expiredBefore); err != nil {
+		return classify(err)
+	}
+	sql := `
+	INSERT INTO idempotency_records (
+		key,
+		request_hash,
+		status,
+		content_type,
+		body,
+		created_at
+	) VALUES (
+		$1, $2, $3, $4, $5, $6
+	);
+	`
+	_, err := db.pool.Exec(c, sql, record.Key, record.RequestHash, record.Status, record.ContentType, record.Body, record.CreatedAt)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return ErrDuplicate
 	}
-	return tag.RowsAffected(), nil
+	return classify(err)
+}
+
+func (db *Database) GetIdempotencyRecord(c context.Context, key string) (models.IdempotencyRecord, error) {
+	c, cancel := db.withTimeout(c)
+	defer cancel()
+	sql := `
+	SELECT key, request_hash, status, content_type, COALESCE(header, '{}'), body, created_at
+	FROM idempotency_records
+	WHERE key = $1;
+	`
+	var record models.IdempotencyRecord
+	err := db.pool.QueryRow(c, sql, key).Scan(&record.Key, &record.RequestHash, &record.Status, &record.ContentType, &record.Header, &record.Body, &record.CreatedAt)
+	return record, classify(err)
+}
+
+func (db *Database) CompleteIdempotencyRecord(c context.Context, record models.IdempotencyRecord) error {
+	c, cancel := db.withTimeout(c)
+	defer cancel()
+	sql := `UPDATE idempotency_records SET status = $2, content_type = $3, header = $4, body = $5 WHERE key = $1;`
+	_, err := db.pool.Exec(c, sql, record.Key, record.Status, record.ContentType, record.Header, record.Body)
+	return classify(err)
+}
+
+func (db *Database) DeleteIdempotencyRecord(c context.Context, key string) error {
+	c, cancel := db.withTimeout(c)
+	defer cancel()
+	_, err := db.pool.Exec(c, `DELETE FROM idempotency_records WHERE key = $1;`, key)
+	return classify(err)
+}
+
+// escapeLike escapes the LIKE wildcards in s.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
 }
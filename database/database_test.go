@@ -2,142 +2,797 @@ package database
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/rtsncs/remitly-swift-api/models"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/testcontainers/testcontainers-go"
 	"github.com/testcontainers/testcontainers-go/modules/postgres"
 	"github.com/testcontainers/testcontainers-go/wait"
 )
 
-var db Database
+var (
+	db      Database
+	connStr string
+	stores  []namedStore
+)
 
-func TestInsertAndGetByCode(t *testing.T) {
-	c := context.Background()
+type namedStore struct {
+	name  string
+	store Store
+}
 
-	code := models.SwiftCode{
-		SwiftCode:     "TESTUS33XXX",
-		BankName:      "Test Bank HQ",
-		Address:       "123 Wall Street",
-		CountryISO2:   "US",
-		CountryName:   "United States",
-		IsHeadquarter: true,
+func forEachStore(t *testing.T, f func(t *testing.T, db Store)) {
+	for _, s := range stores {
+		t.Run(s.name, func(t *testing.T) { f(t, s.store) })
 	}
+}
 
-	err := db.InsertCode(c, code)
-	assert.NoError(t, err)
+func TestInsertAndGetByCode(t *testing.T) {
+	forEachStore(t, func(t *testing.T, db Store) {
+		c := context.Background()
 
-	fetched, err := db.GetByCode(c, code.SwiftCode)
-	assert.NoError(t, err)
-	assert.Equal(t, code.SwiftCode, fetched.SwiftCode)
-	assert.Equal(t, code.BankName, fetched.BankName)
-	assert.Equal(t, code.IsHeadquarter, fetched.IsHeadquarter)
+		code := models.SwiftCode{
+			SwiftCode:     "TESTUS33XXX",
+			BankName:      "Test Bank HQ",
+			Address:       "123 Wall Street",
+			CountryISO2:   "US",
+			CountryName:   "United States",
+			IsHeadquarter: true,
+		}
+
+		err := db.InsertCode(c, code)
+		assert.NoError(t, err)
+
+		fetched, err := db.GetByCode(c, code.SwiftCode)
+		assert.NoError(t, err)
+		assert.Equal(t, code.SwiftCode, fetched.SwiftCode)
+		assert.Equal(t, code.BankName, fetched.BankName)
+		assert.Equal(t, code.IsHeadquarter, fetched.IsHeadquarter)
+	})
 }
 
 func TestGetBranches(t *testing.T) {
-	c := context.Background()
+	forEachStore(t, func(t *testing.T, db Store) {
+		c := context.Background()
 
-	hq := models.SwiftCode{
-		SwiftCode:     "BANKUS12XXX",
-		BankName:      "Bank HQ",
-		Address:       "1 HQ Street",
-		CountryISO2:   "US",
-		CountryName:   "United States",
-		IsHeadquarter: true,
-	}
-	branch1 := models.SwiftCode{
-		SwiftCode:     "BANKUS12NYC",
-		BankName:      "Bank NYC",
-		Address:       "2 NYC Ave",
-		CountryISO2:   "US",
-		CountryName:   "United States",
-		IsHeadquarter: false,
-	}
-	branch2 := models.SwiftCode{
-		SwiftCode:     "BANKUS12CHI",
-		BankName:      "Bank Chicago",
-		Address:       "3 CHI Blvd",
-		CountryISO2:   "US",
-		CountryName:   "United States",
-		IsHeadquarter: false,
-	}
+		hq := models.SwiftCode{
+			SwiftCode:     "BANKUS12XXX",
+			BankName:      "Bank HQ",
+			Address:       "1 HQ Street",
+			CountryISO2:   "US",
+			CountryName:   "United States",
+			IsHeadquarter: true,
+		}
+		branch1 := models.SwiftCode{
+			SwiftCode:     "BANKUS12NYC",
+			BankName:      "Bank NYC",
+			Address:       "2 NYC Ave",
+			CountryISO2:   "US",
+			CountryName:   "United States",
+			IsHeadquarter: false,
+		}
+		branch2 := models.SwiftCode{
+			SwiftCode:     "BANKUS12CHI",
+			BankName:      "Bank Chicago",
+			Address:       "3 CHI Blvd",
+			CountryISO2:   "US",
+			CountryName:   "United States",
+			IsHeadquarter: false,
+		}
 
-	_ = db.InsertCode(c, hq)
-	_ = db.InsertCode(c, branch1)
-	_ = db.InsertCode(c, branch2)
+		_ = db.InsertCode(c, hq)
+		_ = db.InsertCode(c, branch1)
+		_ = db.InsertCode(c, branch2)
 
-	branches, err := db.GetBranches(c, hq.SwiftCode)
-	assert.NoError(t, err)
-	assert.Len(t, branches, 2)
-	assert.NotEqual(t, "XXX", branches[0].SwiftCode[len(branches[0].SwiftCode)-3:])
+		branches, err := db.GetBranches(c, hq.SwiftCode)
+		assert.NoError(t, err)
+		assert.Len(t, branches, 2)
+		assert.NotEqual(t, "XXX", branches[0].SwiftCode[len(branches[0].SwiftCode)-3:])
+	})
+}
+
+func TestBatchLookups(t *testing.T) {
+	forEachStore(t, func(t *testing.T, db Store) {
+		c := context.Background()
+
+		for _, code := range []string{"MANYUS12XXX", "MANYUS12NYC", "MANYUS12CHI", "MANYGB2LXXX", "MANYGB2L001", "MANYDE33XXX"} {
+			require.NoError(t, db.InsertCode(c, models.SwiftCode{
+				SwiftCode:     code,
+				BankName:      "Many Bank",
+				CountryISO2:   code[4:6],
+				CountryName:   code[4:6],
+				IsHeadquarter: strings.HasSuffix(code, "XXX"),
+			}))
+		}
+		_, err := db.DeleteByCode(c, "MANYUS12NYC")
+		require.NoError(t, err)
+
+		branches, err := db.GetBranchesOf(c, []string{"MANYUS12XXX", "MANYGB2LXXX", "MANYDE33XXX", "NONEFR2PXXX"})
+		require.NoError(t, err)
+		var codes []string
+		for _, branch := range branches {
+			codes = append(codes, branch.SwiftCode)
+		}
+		assert.Equal(t, []string{"MANYGB2L001", "MANYUS12CHI"}, codes)
+
+		branches, err = db.GetBranchesOf(c, nil)
+		require.NoError(t, err)
+		assert.Empty(t, branches)
+
+		found, err := db.GetByCodes(c, []string{"MANYUS12CHI", "MANYUS12NYC", "NONEFR2PXXX", "MANYGB2LXXX", "MANYUS12CHI"})
+		require.NoError(t, err)
+		require.Len(t, found, 2)
+		assert.Equal(t, "MANYGB2LXXX", found[0].SwiftCode)
+		assert.Equal(t, "GB", found[0].CountryName)
+		assert.Equal(t, 1, found[0].Version)
+		assert.False(t, found[0].UpdatedAt.IsZero())
+		assert.Equal(t, "MANYUS12CHI", found[1].SwiftCode)
+	})
 }
 
 func TestGetCountryName(t *testing.T) {
-	c := context.Background()
+	forEachStore(t, func(t *testing.T, db Store) {
+		c := context.Background()
 
-	code := models.SwiftCode{
-		SwiftCode:     "TESTCA22XXX",
-		BankName:      "Test Canada Bank",
-		Address:       "123 Maple Road",
-		CountryISO2:   "CA",
-		CountryName:   "Canada",
-		IsHeadquarter: true,
-	}
+		code := models.SwiftCode{
+			SwiftCode:     "TESTCA22XXX",
+			BankName:      "Test Canada Bank",
+			Address:       "123 Maple Road",
+			CountryISO2:   "CA",
+			CountryName:   "Canada",
+			IsHeadquarter: true,
+		}
 
-	_ = db.InsertCode(c, code)
+		_ = db.InsertCode(c, code)
 
-	countryName, err := db.GetCountryName(c, "CA")
-	assert.NoError(t, err)
-	assert.Equal(t, "Canada", countryName)
+		countryName, err := db.GetCountryName(c, "CA")
+		assert.NoError(t, err)
+		assert.Equal(t, "Canada", countryName)
+
+		names, err := db.GetCountryNames(c, []string{"CA", "XX"})
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{"CA": "Canada"}, names)
+
+		_, err = db.DeleteByCode(c, code.SwiftCode)
+		assert.NoError(t, err)
+		_, err = db.GetCountryName(c, "CA")
+		assert.ErrorIs(t, err, ErrNotFound)
+		names, err = db.GetCountryNames(c, []string{"CA"})
+		assert.NoError(t, err)
+		assert.Empty(t, names)
+	})
 }
 
 func TestGetByCountryCode(t *testing.T) {
-	c := context.Background()
+	forEachStore(t, func(t *testing.T, db Store) {
+		c := context.Background()
 
-	code := models.SwiftCode{
-		SwiftCode:     "DEUTDEFFXXX",
-		BankName:      "Deutsche Bank",
-		Address:       "Berlin",
-		CountryISO2:   "DE",
-		CountryName:   "Germany",
-		IsHeadquarter: true,
-	}
+		code := models.SwiftCode{
+			SwiftCode:     "DEUTDEFFXXX",
+			BankName:      "Deutsche Bank",
+			Address:       "Berlin",
+			CountryISO2:   "DE",
+			CountryName:   "Germany",
+			IsHeadquarter: true,
+		}
 
-	_ = db.InsertCode(c, code)
+		_ = db.InsertCode(c, code)
 
-	results, err := db.GetByCountryCode(c, "DE")
-	assert.NoError(t, err)
-	assert.NotEmpty(t, results)
-	assert.Equal(t, "DE", results[0].CountryISO2)
+		results, err := db.GetByCountryCode(c, "DE", Filter{})
+		assert.NoError(t, err)
+		assert.NotEmpty(t, results)
+		assert.Equal(t, "DE", results[0].CountryISO2)
+	})
+}
+
+func TestGetByCountryCodeFilter(t *testing.T) {
+	forEachStore(t, func(t *testing.T, db Store) {
+		c := context.Background()
+
+		for _, swiftCode := range []string{"FLAGNL20XXX", "FLAGNL21XXX", "FLAGNL22XXX", "FLAGNL2AXXX"} {
+			_ = db.InsertCode(c, models.SwiftCode{
+				SwiftCode:     swiftCode,
+				BankName:      "Flag Bank",
+				Address:       "Amsterdam",
+				CountryISO2:   "NL",
+				CountryName:   "Netherlands",
+				IsHeadquarter: true,
+			})
+		}
+
+		fetched, err := db.GetByCode(c, "FLAGNL20XXX")
+		assert.NoError(t, err)
+		assert.True(t, fetched.IsTest)
+		assert.False(t, fetched.IsPassive)
+		assert.False(t, fetched.IsReverseBilling)
+
+		yes, no := true, false
+		results, err := db.GetByCountryCode(c, "NL", Filter{})
+		assert.NoError(t, err)
+		assert.Len(t, results, 4)
+
+		results, err = db.GetByCountryCode(c, "NL", Filter{IsTest: &no, IsPassive: &no})
+		assert.NoError(t, err)
+		assert.Len(t, results, 2)
+
+		results, err = db.GetByCountryCode(c, "NL", Filter{IsReverseBilling: &yes})
+		assert.NoError(t, err)
+		if assert.Len(t, results, 1) {
+			assert.Equal(t, "FLAGNL22XXX", results[0].SwiftCode)
+			assert.True(t, results[0].IsReverseBilling)
+		}
+	})
+}
+
+func TestGetByCodePrefix(t *testing.T) {
+	forEachStore(t, func(t *testing.T, db Store) {
+		c := context.Background()
+
+		for _, code := range []string{"PRFXFRPPXXX", "PRFXFRPP123", "PRFXFRPPABC", "PRFYFRPPXXX", "PRFXFRP0XXX"} {
+			assert.NoError(t, db.InsertCode(c, models.SwiftCode{
+				SwiftCode:     code,
+				BankName:      "Prefix Bank",
+				CountryISO2:   "FR",
+				CountryName:   "FRANCE",
+				IsHeadquarter: strings.HasSuffix(code, "XXX"),
+			}))
+		}
+
+		codes, err := db.GetByCodePrefix(c, "PRFXFRPP", 10, Filter{})
+		assert.NoError(t, err)
+		var got []string
+		for _, code := range codes {
+			got = append(got, code.SwiftCode)
+		}
+		assert.Equal(t, []string{"PRFXFRPP123", "PRFXFRPPABC", "PRFXFRPPXXX"}, got)
+		assert.Equal(t, "FRANCE", codes[0].CountryName)
+
+		codes, err = db.GetByCodePrefix(c, "PRF", 2, Filter{})
+		assert.NoError(t, err)
+		assert.Len(t, codes, 2)
+
+		isTest := true
+		codes, err = db.GetByCodePrefix(c, "PRFXFRP", 10, Filter{IsTest: &isTest})
+		assert.NoError(t, err)
+		if assert.Len(t, codes, 1) {
+			assert.Equal(t, "PRFXFRP0XXX", codes[0].SwiftCode)
+		}
+
+		for _, prefix := range []string{"PRF%", "PRF_", "PRF*", "PRF?"} {
+			codes, err = db.GetByCodePrefix(c, prefix, 10, Filter{})
+			assert.NoError(t, err)
+			assert.Empty(t, codes, prefix)
+		}
+	})
+}
+
+func TestGetByBankNamePrefix(t *testing.T) {
+	forEachStore(t, func(t *testing.T, db Store) {
+		c := context.Background()
+
+		for _, code := range []models.SwiftCode{
+			{SwiftCode: "NAMEITRRXXX", BankName: "Typeahead Bank Italy", CountryISO2: "IT", CountryName: "ITALY", IsHeadquarter: true},
+			{SwiftCode: "NAMEITRR123", BankName: "Typeahead Bank Italy", CountryISO2: "IT", CountryName: "ITALY"},
+			{SwiftCode: "AHEAITRRXXX", BankName: "TYPEAHEAD BANCA", CountryISO2: "IT", CountryName: "ITALY", IsHeadquarter: true},
+			{SwiftCode: "OTHRITRRXXX", BankName: "Other Typeahead", CountryISO2: "IT", CountryName: "ITALY", IsHeadquarter: true},
+			{SwiftCode: "LODZPLPWXXX", BankName: "Łódzki Bank Spółdzielczy", CountryISO2: "PL", CountryName: "POLAND", IsHeadquarter: true},
+		} {
+			assert.NoError(t, db.InsertCode(c, code))
+		}
+
+		codes, err := db.GetByBankNamePrefix(c, "typeahead ban", 10, Filter{})
+		assert.NoError(t, err)
+		var got []string
+		for _, code := range codes {
+			got = append(got, code.SwiftCode)
+		}
+		assert.Equal(t, []string{"AHEAITRRXXX", "NAMEITRR123", "NAMEITRRXXX"}, got)
+		assert.Equal(t, "ITALY", codes[0].CountryName)
+
+		codes, err = db.GetByBankNamePrefix(c, "TYPEAHEAD", 1, Filter{})
+		assert.NoError(t, err)
+		assert.Len(t, codes, 1)
+
+		codes, err = db.GetByBankNamePrefix(c, "Typeahead%", 10, Filter{})
+		assert.NoError(t, err)
+		assert.Empty(t, codes)
+
+		// Case is folded beyond ASCII.
+		codes, err = db.GetByBankNamePrefix(c, "łÓDZKI", 10, Filter{})
+		assert.NoError(t, err)
+		if assert.Len(t, codes, 1) {
+			assert.Equal(t, "LODZPLPWXXX", codes[0].SwiftCode)
+		}
+
+		isTest := true
+		codes, err = db.GetByBankNamePrefix(c, "łódzki", 10, Filter{IsTest: &isTest})
+		assert.NoError(t, err)
+		assert.Empty(t, codes)
+	})
 }
 
 func TestDeleteByCode(t *testing.T) {
+	forEachStore(t, func(t *testing.T, db Store) {
+		c := context.Background()
+
+		code := models.SwiftCode{
+			SwiftCode:     "DELETE01XXX",
+			BankName:      "To Be Deleted Bank",
+			Address:       "Delete St",
+			CountryISO2:   "XX",
+			CountryName:   "Nowhere",
+			IsHeadquarter: true,
+		}
+
+		_ = db.InsertCode(c, code)
+
+		affected, err := db.DeleteByCode(c, code.SwiftCode)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), affected)
+
+		_, err = db.GetByCode(c, code.SwiftCode)
+		assert.Error(t, err)
+	})
+}
+
+func TestValidity(t *testing.T) {
+	forEachStore(t, func(t *testing.T, db Store) {
+		c := context.Background()
+		date := func(s string) time.Time {
+			d, err := time.Parse(models.DateLayout, s)
+			assert.NoError(t, err)
+			return d
+		}
+
+		old := models.SwiftCode{SwiftCode: "VALDCHZZXXX", BankName: "Old Name", CountryISO2: "CH", CountryName: "SWITZERLAND", IsHeadquarter: true, ValidFrom: "2026-01-01"}
+		branch := models.SwiftCode{SwiftCode: "VALDCHZZ001", BankName: "Old Name", CountryISO2: "CH", CountryName: "SWITZERLAND", ValidFrom: "2026-01-01"}
+		assert.NoError(t, db.InsertCode(c, old))
+		assert.NoError(t, db.InsertCode(c, branch))
+		assert.ErrorIs(t, db.InsertCode(c, old), ErrDuplicate)
+
+		_, err := db.CloseByCode(c, old.SwiftCode, date("2025-12-31"))
+		assert.ErrorIs(t, err, ErrBackdated)
+		affected, err := db.CloseByCode(c, old.SwiftCode, date("2026-03-01"))
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), affected)
+		affected, err = db.CloseByCode(c, branch.SwiftCode, date("2026-03-01"))
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), affected)
+
+		renamed := models.SwiftCode{SwiftCode: "VALDCHZZXXX", BankName: "New Name", CountryISO2: "CH", CountryName: "SWITZERLAND", IsHeadquarter: true, ValidFrom: "2026-03-01"}
+		assert.NoError(t, db.InsertCode(c, renamed))
+
+		current, err := db.GetByCode(c, old.SwiftCode)
+		assert.NoError(t, err)
+		assert.Equal(t, "New Name", current.BankName)
+		assert.Equal(t, "2026-03-01", current.ValidFrom)
+		assert.Empty(t, current.ValidTo)
+
+		past, err := db.GetByCodeAsOf(c, old.SwiftCode, date("2026-02-28"))
+		assert.NoError(t, err)
+		assert.Equal(t, "Old Name", past.BankName)
+		assert.Equal(t, "2026-03-01", past.ValidTo)
+
+		onChange, err := db.GetByCodeAsOf(c, old.SwiftCode, date("2026-03-01"))
+		assert.NoError(t, err)
+		assert.Equal(t, "New Name", onChange.BankName)
+
+		_, err = db.GetByCodeAsOf(c, old.SwiftCode, date("2025-12-31"))
+		assert.ErrorIs(t, err, ErrNotFound)
+
+		_, err = db.GetByCode(c, branch.SwiftCode)
+		assert.ErrorIs(t, err, ErrNotFound)
+
+		branches, err := db.GetBranches(c, old.SwiftCode)
+		assert.NoError(t, err)
+		assert.Empty(t, branches)
+		branches, err = db.GetBranchesAsOf(c, old.SwiftCode, date("2026-02-01"))
+		assert.NoError(t, err)
+		assert.Len(t, branches, 1)
+
+		codes, err := db.GetByCountryCode(c, "CH", Filter{})
+		assert.NoError(t, err)
+		assert.Len(t, codes, 1)
+		codes, err = db.GetByCountryCode(c, "CH", Filter{AsOf: date("2026-02-01")})
+		assert.NoError(t, err)
+		assert.Len(t, codes, 2)
+
+		affected, err = db.DeleteByCode(c, old.SwiftCode)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), affected)
+		_, err = db.GetByCode(c, old.SwiftCode)
+		assert.ErrorIs(t, err, ErrNotFound)
+		kept, err := db.GetByCodeAsOf(c, old.SwiftCode, date("2026-03-02"))
+		assert.NoError(t, err)
+		assert.Equal(t, "New Name", kept.BankName)
+	})
+}
+
+func TestReplaceCode(t *testing.T) {
+	forEachStore(t, func(t *testing.T, db Store) {
+		c := context.Background()
+
+		code := models.SwiftCode{SwiftCode: "REPLAT2LXXX", BankName: "Old Name", CountryISO2: "AT", CountryName: "AUSTRIA", IsHeadquarter: true, ValidFrom: "2026-02-01"}
+		require.NoError(t, db.InsertCode(c, code))
+
+		missing := code
+		missing.SwiftCode = "MISSAT2LXXX"
+		assert.ErrorIs(t, db.ReplaceCode(c, missing, 1), ErrNotFound)
+
+		// The current record can't be retired before it became valid.
+		code.BankName = "New Name"
+		code.ValidFrom = "2026-01-01"
+		assert.ErrorIs(t, db.ReplaceCode(c, code, 1), ErrBackdated)
+		current, err := db.GetByCode(c, code.SwiftCode)
+		require.NoError(t, err)
+		assert.Equal(t, "Old Name", current.BankName)
+		assert.Empty(t, current.ValidTo)
+
+		code.ValidFrom = "2026-03-01"
+		assert.ErrorIs(t, db.ReplaceCode(c, code, 2), ErrVersionMismatch)
+		require.NoError(t, db.ReplaceCode(c, code, 1))
+		current, err = db.GetByCode(c, code.SwiftCode)
+		require.NoError(t, err)
+		assert.Equal(t, "New Name", current.BankName)
+		assert.Equal(t, "2026-03-01", current.ValidFrom)
+		assert.Equal(t, 3, current.Version)
+		past, err := db.GetByCodeAsOf(c, code.SwiftCode, time.Date(2026, 2, 15, 0, 0, 0, 0, time.UTC))
+		require.NoError(t, err)
+		assert.Equal(t, "Old Name", past.BankName)
+		assert.Equal(t, "2026-03-01", past.ValidTo)
+	})
+}
+
+func TestForEachCode(t *testing.T) {
+	forEachStore(t, func(t *testing.T, db Store) {
+		c := context.Background()
+
+		code := models.SwiftCode{
+			SwiftCode:     "EACHJPJTXXX",
+			BankName:      "Each Bank",
+			Address:       "Tokyo",
+			CountryISO2:   "JP",
+			CountryName:   "Japan",
+			IsHeadquarter: true,
+		}
+
+		_ = db.InsertCode(c, code)
+
+		var codes []models.SwiftCode
+		err := db.ForEachCode(c, "JP", func(code models.SwiftCode) error {
+			codes = append(codes, code)
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []models.SwiftCode{code}, codes)
+
+		total := 0
+		err = db.ForEachCode(c, "", func(code models.SwiftCode) error {
+			total++
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Greater(t, total, len(codes))
+	})
+}
+
+func TestGetByBankCode(t *testing.T) {
+	forEachStore(t, func(t *testing.T, db Store) {
+		c := context.Background()
+
+		hq := models.SwiftCode{SwiftCode: "IBANDEFFXXX", BankName: "IBAN Bank", Address: "Frankfurt", CountryISO2: "DE", CountryName: "GERMANY", IsHeadquarter: true}
+		branch := models.SwiftCode{SwiftCode: "IBANDEFF100", BankName: "IBAN Bank", Address: "Berlin", CountryISO2: "DE", CountryName: "GERMANY"}
+		assert.NoError(t, db.InsertCode(c, hq))
+		assert.NoError(t, db.InsertCode(c, branch))
+
+		insert := func(bankCode, swiftCode string) error {
+			return db.InsertNationalID(c, models.BankCode{CountryISO2: "DE", BankCode: bankCode, SwiftCode: swiftCode}.NationalID())
+		}
+		assert.NoError(t, insert("10010010", "IBANDEFF100"))
+		assert.NoError(t, insert("50010010", "IBANDEFFXXX"))
+		assert.NoError(t, insert("50010010", "IBANDEFF100"))
+		assert.ErrorIs(t, insert("10010010", "IBANDEFF100"), ErrDuplicate)
+		// German bank codes are the Bankleitzahl.
+		assert.ErrorIs(t, db.InsertNationalID(c, models.NationalID{Scheme: models.SchemeBLZ, Identifier: "10010010", SwiftCode: "IBANDEFF100"}), ErrDuplicate)
+
+		codes, err := db.GetByNationalID(c, models.BankCodeScheme("DE"), "10010010")
+		assert.NoError(t, err)
+		assert.Equal(t, []models.SwiftCode{branch}, codes)
+
+		codes, err = db.GetByNationalID(c, models.BankCodeScheme("DE"), "10010010", "50010010")
+		assert.NoError(t, err)
+		assert.Equal(t, []models.SwiftCode{branch, hq}, codes)
+
+		codes, err = db.GetByNationalID(c, models.BankCodeScheme("AT"), "10010010")
+		assert.NoError(t, err)
+		assert.Empty(t, codes)
+	})
+}
+
+func TestGetByNationalID(t *testing.T) {
+	forEachStore(t, func(t *testing.T, db Store) {
+		c := context.Background()
+
+		code := models.SwiftCode{SwiftCode: "NATLUS33XXX", BankName: "National Bank", Address: "New York", CountryISO2: "US", CountryName: "UNITED STATES", IsHeadquarter: true}
+		assert.NoError(t, db.InsertCode(c, code))
+
+		id := models.NationalID{Scheme: models.SchemeABA, Identifier: "021000021", SwiftCode: "NATLUS33XXX"}
+		assert.NoError(t, db.InsertNationalID(c, id))
+		assert.ErrorIs(t, db.InsertNationalID(c, id), ErrDuplicate)
+		assert.NoError(t, db.InsertNationalID(c, models.NationalID{Scheme: models.SchemeABA, Identifier: "011000015", SwiftCode: "MISSUS33XXX"}))
+
+		codes, err := db.GetByNationalID(c, models.SchemeABA, "021000021")
+		assert.NoError(t, err)
+		assert.Equal(t, []models.SwiftCode{code}, codes)
+
+		codes, err = db.GetByNationalID(c, models.SchemeABA, "011000015")
+		assert.NoError(t, err)
+		assert.Empty(t, codes)
+
+		codes, err = db.GetByNationalID(c, models.SchemeBLZ, "021000021")
+		assert.NoError(t, err)
+		assert.Empty(t, codes)
+	})
+}
+
+func TestRevisions(t *testing.T) {
+	forEachStore(t, func(t *testing.T, db Store) {
+		c := context.Background()
+		validTo := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+		hq := models.SwiftCode{SwiftCode: "REVSNZ22XXX", BankName: "Old Name", CountryISO2: "NZ", CountryName: "NEW ZEALAND", IsHeadquarter: true, ValidFrom: "2026-01-01"}
+		branch := models.SwiftCode{SwiftCode: "REVSNZ22001", BankName: "Old Name", CountryISO2: "NZ", CountryName: "NEW ZEALAND", ValidFrom: "2026-01-01"}
+		require.NoError(t, db.InsertCode(c, hq))
+		require.NoError(t, db.InsertCode(c, branch))
+
+		stored, err := db.GetByCode(c, hq.SwiftCode)
+		require.NoError(t, err)
+		assert.Equal(t, 1, stored.Version)
+		assert.False(t, stored.CreatedAt.IsZero())
+		assert.Equal(t, stored.CreatedAt, stored.UpdatedAt)
+		branches, err := db.GetBranches(c, hq.SwiftCode)
+		require.NoError(t, err)
+		require.Len(t, branches, 1)
+		assert.Equal(t, 1, branches[0].Version)
+		codes, err := db.GetByCountryCode(c, "NZ", Filter{})
+		require.NoError(t, err)
+		for _, code := range codes {
+			assert.Equal(t, 1, code.Version, code.SwiftCode)
+		}
+		created, err := db.LastModified(c, "NZ", "REVSNZ22")
+		require.NoError(t, err)
+
+		assert.ErrorIs(t, db.CloseByCodeAtVersion(c, hq.SwiftCode, 2, validTo), ErrVersionMismatch)
+		assert.ErrorIs(t, db.CloseByCodeAtVersion(c, "MISSNZ22XXX", 1, validTo), ErrNotFound)
+		time.Sleep(time.Millisecond)
+		require.NoError(t, db.CloseByCodeAtVersion(c, hq.SwiftCode, 1, validTo))
+		assert.ErrorIs(t, db.CloseByCodeAtVersion(c, hq.SwiftCode, 2, validTo), ErrNotFound)
+
+		// Retired records keep counting, so new ones follow them.
+		past, err := db.GetByCodeAsOf(c, hq.SwiftCode, validTo.AddDate(0, 0, -1))
+		require.NoError(t, err)
+		assert.Equal(t, 2, past.Version)
+		assert.True(t, past.UpdatedAt.After(past.CreatedAt))
+		hq.BankName = "New Name"
+		hq.ValidFrom = "2026-04-01"
+		require.NoError(t, db.InsertCode(c, hq))
+		stored, err = db.GetByCode(c, hq.SwiftCode)
+		require.NoError(t, err)
+		assert.Equal(t, 3, stored.Version)
+
+		modified, err := db.LastModified(c, "NZ", "REVSNZ22")
+		require.NoError(t, err)
+		assert.True(t, modified.After(created))
+		// The branch was created last and hasn't changed since.
+		branchModified, err := db.LastModified(c, "NZ", branch.SwiftCode)
+		require.NoError(t, err)
+		assert.True(t, branchModified.Equal(created))
+		_, err = db.LastModified(c, "NZ", "NONE")
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+}
+
+func TestChanges(t *testing.T) {
+	forEachStore(t, func(t *testing.T, db Store) {
+		c := context.Background()
+		// Other tests share the store, so the feed is read from its end.
+		var since int64
+		for {
+			changes, err := db.GetChanges(c, since, 1000)
+			require.NoError(t, err)
+			if len(changes) == 0 {
+				break
+			}
+			since = changes[len(changes)-1].ID
+		}
+
+		code := models.SwiftCode{SwiftCode: "FEEDJP21001", BankName: "Old Name", CountryISO2: "JP", CountryName: "JAPAN", ValidFrom: "2026-01-01"}
+		require.NoError(t, db.InsertCode(c, code))
+		assert.ErrorIs(t, db.InsertCode(c, code), ErrDuplicate)
+		code.BankName = "New Name"
+		code.ValidFrom = "2026-02-01"
+		assert.ErrorIs(t, db.ReplaceCode(c, code, 2), ErrVersionMismatch)
+		assert.ErrorIs(t, db.ReplaceCode(c, models.SwiftCode{SwiftCode: "MISSJP21001", BankName: "Missing"}, 1), ErrNotFound)
+		require.NoError(t, db.ReplaceCode(c, code, 1))
+		_, err := db.DeleteByCode(c, code.SwiftCode)
+		require.NoError(t, err)
+		deleted, err := db.DeleteByCode(c, code.SwiftCode)
+		require.NoError(t, err)
+		assert.Zero(t, deleted)
+
+		// The replaced record was retired as the new one became valid.
+		past, err := db.GetByCodeAsOf(c, code.SwiftCode, time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC))
+		require.NoError(t, err)
+		assert.Equal(t, "Old Name", past.BankName)
+		assert.Equal(t, "2026-02-01", past.ValidTo)
+
+		changes, err := db.GetChanges(c, since, 10)
+		require.NoError(t, err)
+		require.Len(t, changes, 3)
+		assert.Equal(t, models.ChangeCreated, changes[0].Type)
+		assert.Equal(t, 1, changes[0].Version)
+		require.NotNil(t, changes[0].Record)
+		assert.Equal(t, "Old Name", changes[0].Record.BankName)
+		assert.True(t, changes[0].Record.IsPassive)
+		assert.Equal(t, models.ChangeUpdated, changes[1].Type)
+		assert.Equal(t, 3, changes[1].Version)
+		require.NotNil(t, changes[1].Record)
+		assert.Equal(t, "New Name", changes[1].Record.BankName)
+		assert.Equal(t, "2026-02-01", changes[1].Record.ValidFrom)
+		assert.Equal(t, models.ChangeDeleted, changes[2].Type)
+		assert.Equal(t, 4, changes[2].Version)
+		assert.Nil(t, changes[2].Record)
+		for i, change := range changes {
+			assert.Equal(t, code.SwiftCode, change.SwiftCode)
+			assert.False(t, change.ChangedAt.IsZero())
+			if i > 0 {
+				assert.Greater(t, change.ID, changes[i-1].ID)
+			}
+		}
+
+		// Resuming after a change returns the ones that followed it.
+		rest, err := db.GetChanges(c, changes[0].ID, 1)
+		require.NoError(t, err)
+		assert.Equal(t, changes[1:2], rest)
+		rest, err = db.GetChanges(c, changes[2].ID, 10)
+		require.NoError(t, err)
+		assert.Empty(t, rest)
+	})
+}
+
+func TestIdempotencyRecords(t *testing.T) {
+	forEachStore(t, func(t *testing.T, db Store) {
+		c := context.Background()
+		now := time.Date(2025, 1, 2, 12, 0, 0, 0, time.UTC)
+		record := models.IdempotencyRecord{Key: "ip:192.0.2.1:retry", RequestHash: "hash", CreatedAt: now}
+
+		assert.NoError(t, db.InsertIdempotencyRecord(c, record, now.Add(-time.Hour)))
+		assert.ErrorIs(t, db.InsertIdempotencyRecord(c, record, now.Add(-time.Hour)), ErrDuplicate)
+		stored, err := db.GetIdempotencyRecord(c, record.Key)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, stored.Status)
+		assert.True(t, now.Equal(stored.CreatedAt))
+
+		record.Status = 201
+		record.ContentType = "application/json"
+		record.Header = http.Header{"Etag": {`"tag"`}, "Location": {"/v1/swift-codes/IDEMFRPPXXX"}}
+		record.Body = []byte(`{"message":"Created"}`)
+		assert.NoError(t, db.CompleteIdempotencyRecord(c, record))
+		stored, err = db.GetIdempotencyRecord(c, record.Key)
+		assert.NoError(t, err)
+		assert.Equal(t, record.Status, stored.Status)
+		assert.Equal(t, record.ContentType, stored.ContentType)
+		assert.Equal(t, record.Header, stored.Header)
+		assert.Equal(t, record.Body, stored.Body)
+
+		// Expired records make way for new ones.
+		renewed := models.IdempotencyRecord{Key: record.Key, RequestHash: "other", CreatedAt: now.Add(2 * time.Hour)}
+		assert.NoError(t, db.InsertIdempotencyRecord(c, renewed, now.Add(time.Hour)))
+		stored, err = db.GetIdempotencyRecord(c, record.Key)
+		assert.NoError(t, err)
+		assert.Equal(t, "other", stored.RequestHash)
+
+		assert.NoError(t, db.DeleteIdempotencyRecord(c, record.Key))
+		_, err = db.GetIdempotencyRecord(c, record.Key)
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+}
+
+func TestStatementTimeout(t *testing.T) {
+	requirePostgres(t)
 	c := context.Background()
+	timed := db
+	timed.timeout = time.Nanosecond
 
-	code := models.SwiftCode{
-		SwiftCode:     "DELETE01XXX",
-		BankName:      "To Be Deleted Bank",
-		Address:       "Delete St",
-		CountryISO2:   "XX",
-		CountryName:   "Nowhere",
-		IsHeadquarter: true,
-	}
+	_, err := timed.GetByCode(c, "TESTUS33XXX")
+	assert.ErrorIs(t, err, ErrTimeout)
+	assert.ErrorIs(t, timed.InsertCode(c, models.SwiftCode{SwiftCode: "TIMEUS33XXX", BankName: "Bank", CountryISO2: "US", CountryName: "UNITED STATES", IsHeadquarter: true}), ErrTimeout)
+
+	timed.timeout = time.Minute
+	_, err = timed.GetByCode(c, "NONEUS33XXX")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestReplicas(t *testing.T) {
+	requirePostgres(t)
+	c := context.Background()
+	store, err := ConnectWithOptions(c, Options{
+		URL:         connStr,
+		ReplicaURLs: []string{connStr, "postgres://127.0.0.1:1/swift?connect_timeout=1"},
+	})
+	require.NoError(t, err)
+	defer store.Close()
+	replicated := store.(*Database)
+	up, down := replicated.replicas.pools[0], replicated.replicas.pools[1]
+	assert.True(t, up.healthy.Load())
+	assert.False(t, down.healthy.Load())
+
+	code := models.SwiftCode{SwiftCode: "REPLUS33XXX", BankName: "Replicated Bank", CountryISO2: "US", CountryName: "UNITED STATES", IsHeadquarter: true}
+	require.NoError(t, replicated.InsertCode(c, code))
 
-	_ = db.InsertCode(c, code)
+	// A replica that went down since the last check fails over to the
+	// primary and is skipped afterwards.
+	down.healthy.Store(true)
+	for range 4 {
+		fetched, err := replicated.GetByCode(c, code.SwiftCode)
+		require.NoError(t, err)
+		assert.Equal(t, code.BankName, fetched.BankName)
+	}
+	assert.False(t, down.healthy.Load())
+	assert.Equal(t, up, replicated.replicas.pick())
 
-	affected, err := db.DeleteByCode(c, code.SwiftCode)
+	name, err := replicated.GetCountryName(WithPrimary(c), "US")
 	assert.NoError(t, err)
-	assert.Equal(t, int64(1), affected)
+	assert.Equal(t, "UNITED STATES", name)
+}
 
-	_, err = db.GetByCode(c, code.SwiftCode)
-	assert.Error(t, err)
+func TestClassify(t *testing.T) {
+	assert.NoError(t, classify(nil))
+	assert.ErrorIs(t, classify(context.DeadlineExceeded), ErrTimeout)
+	assert.ErrorIs(t, classify(&pgconn.PgError{Code: "57014"}), ErrTimeout)
+	_, err := pgconn.Connect(context.Background(), "postgres://127.0.0.1:1/swift")
+	assert.ErrorIs(t, classify(err), ErrUnavailable)
+	assert.Equal(t, ErrNotFound, classify(ErrNotFound))
+	assert.NotErrorIs(t, classify(context.Canceled), ErrTimeout)
 }
 
 func TestMain(m *testing.M) {
 	c := context.Background()
 
+	tmpDir, err := os.MkdirTemp("", "swiftcodes-*")
+	if err != nil {
+		log.Fatalf("Failed to create temp dir: %v\n", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	sqliteDB, err := ConnectSQLite(c, filepath.Join(tmpDir, "test.db"))
+	if err != nil {
+		log.Fatalf("Failed to open sqlite database: %v\n", err)
+	}
+	defer sqliteDB.Close()
+
+	stores = []namedStore{
+		{"memory", NewMemory()},
+		{"sqlite", &sqliteDB},
+	}
+
+	// Postgres runs in a container, so without Docker only the other stores
+	// are tested.
+	if err := dockerHealth(c); err != nil {
+		log.Printf("Skipping Postgres tests: %v\n", err)
+		m.Run()
+		return
+	}
+
 	pgContainer, err := postgres.Run(
 		c,
 		"postgres:17",
@@ -155,7 +810,7 @@ func TestMain(m *testing.M) {
 		}
 	}()
 
-	connStr, err := pgContainer.ConnectionString(c)
+	connStr, err = pgContainer.ConnectionString(c)
 	if err != nil {
 		log.Fatalf("Failed to get connection string: %v\n", err)
 	}
@@ -166,5 +821,32 @@ func TestMain(m *testing.M) {
 	}
 	defer db.Close()
 
+	stores = append([]namedStore{{"postgres", &db}}, stores...)
+
 	m.Run()
 }
+
+// dockerHealth reports whether testcontainers can reach Docker. It panics
+// rather than failing when it can't find it at all.
+func dockerHealth(c context.Context) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+
+	provider, err := testcontainers.ProviderDocker.GetProvider()
+	if err != nil {
+		return err
+	}
+	defer provider.Close()
+	return provider.Health(c)
+}
+
+// requirePostgres skips tests of the Postgres store when it isn't running.
+func requirePostgres(t *testing.T) {
+	t.Helper()
+	if connStr == "" {
+		t.Skip("Postgres needs Docker")
+	}
+}
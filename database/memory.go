@@ -0,0 +1,467 @@
+package database
+
+import (
+	"cmp"
+	"context"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rtsncs/remitly-swift-api/models"
+)
+
+type Memory struct {
+	mu sync.RWMutex
+	// codes holds current and retired records in insertion order, and
+	// revisions their versions and timestamps. index points to the current
+	// record of each code and versions holds its latest version.
+	codes       []models.SwiftCode
+	revisions   []revision
+	index       map[string]int
+	versions    map[string]int
+	nationalIDs map[models.NationalID]struct{}
+	idempotency map[string]models.IdempotencyRecord
+	changes     []models.Change
+}
+
+type revision struct {
+	version   int
+	createdAt time.Time
+	updatedAt time.Time
+}
+
+func NewMemory() *Memory {
+	return &Memory{
+		index:       make(map[string]int),
+		versions:    make(map[string]int),
+		nationalIDs: make(map[models.NationalID]struct{}),
+		idempotency: make(map[string]models.IdempotencyRecord),
+	}
+}
+
+func (m *Memory) Ping(c context.Context) error { return nil }
+
+func (m *Memory) Close() {}
+
+func (m *Memory) InsertCode(c context.Context, code models.SwiftCode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.index[code.SwiftCode]; ok && code.ValidTo == "" {
+		return ErrDuplicate
+	}
+	m.insert(code)
+	m.record(models.ChangeCreated, code.SwiftCode, changeRecord(code))
+	return nil
+}
+
+func (m *Memory) insert(code models.SwiftCode) {
+	setLocationFlags(&code)
+	if code.ValidTo == "" {
+		m.index[code.SwiftCode] = len(m.codes)
+	}
+	m.versions[code.SwiftCode]++
+	now := time.Now()
+	m.codes = append(m.codes, code)
+	m.revisions = append(m.revisions, revision{version: m.versions[code.SwiftCode], createdAt: now, updatedAt: now})
+}
+
+// record adds a change of code, at its latest version, to the change feed.
+func (m *Memory) record(changeType models.ChangeType, code string, record *models.SwiftCode) {
+	m.changes = append(m.changes, models.Change{
+		ID:        int64(len(m.changes)) + 1,
+		Type:      changeType,
+		SwiftCode: code,
+		Version:   m.versions[code],
+		Record:    record,
+		ChangedAt: time.Now(),
+	})
+}
+
+func (m *Memory) GetByCode(c context.Context, code string) (models.SwiftCode, error) {
+	return m.GetByCodeAsOf(c, code, time.Time{})
+}
+
+func (m *Memory) GetByCodeAsOf(c context.Context, code string, asOf time.Time) (models.SwiftCode, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if asOf.IsZero() {
+		i, ok := m.index[code]
+		if !ok {
+			return models.SwiftCode{}, ErrNotFound
+		}
+		return m.withRevision(i), nil
+	}
+	for i, stored := range m.codes {
+		if stored.SwiftCode == code && validOn(stored, asOf) {
+			return m.withRevision(i), nil
+		}
+	}
+	return models.SwiftCode{}, ErrNotFound
+}
+
+func (m *Memory) GetByCodes(c context.Context, codes []string) ([]models.SwiftCode, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	found := []models.SwiftCode{}
+	for _, code := range codes {
+		if i, ok := m.index[code]; ok {
+			found = append(found, m.withRevision(i))
+		}
+	}
+	slices.SortFunc(found, func(a, b models.SwiftCode) int { return strings.Compare(a.SwiftCode, b.SwiftCode) })
+	found = slices.CompactFunc(found, func(a, b models.SwiftCode) bool { return a.SwiftCode == b.SwiftCode })
+	return found, nil
+}
+
+func (m *Memory) GetBranches(c context.Context, headquaterCode string) ([]models.SwiftCode, error) {
+	return m.GetBranchesAsOf(c, headquaterCode, time.Time{})
+}
+
+func (m *Memory) GetBranchesAsOf(c context.Context, headquaterCode string, asOf time.Time) ([]models.SwiftCode, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	prefix := headquaterCode[:8]
+	branches := []models.SwiftCode{}
+	for i, code := range m.codes {
+		if strings.HasPrefix(code.SwiftCode, prefix) && !strings.HasSuffix(code.SwiftCode, "XXX") && validOn(code, asOf) {
+			code = m.withRevision(i)
+			code.CountryName = ""
+			branches = append(branches, code)
+		}
+	}
+	return branches, nil
+}
+
+func (m *Memory) GetBranchesOf(c context.Context, headquarterCodes []string) ([]models.SwiftCode, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	prefixes := make(map[string]struct{})
+	for _, prefix := range branchPrefixes(headquarterCodes) {
+		prefixes[prefix] = struct{}{}
+	}
+	branches := []models.SwiftCode{}
+	for i, code := range m.codes {
+		if _, ok := prefixes[code.SwiftCode[:min(len(code.SwiftCode), 8)]]; ok && !strings.HasSuffix(code.SwiftCode, "XXX") && code.ValidTo == "" {
+			code = m.withRevision(i)
+			code.CountryName = ""
+			branches = append(branches, code)
+		}
+	}
+	slices.SortFunc(branches, func(a, b models.SwiftCode) int { return strings.Compare(a.SwiftCode, b.SwiftCode) })
+	return branches, nil
+}
+
+func (m *Memory) GetByCodePrefix(c context.Context, prefix string, limit int, filter Filter) ([]models.SwiftCode, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	codes := []models.SwiftCode{}
+	for _, code := range m.codes {
+		if strings.HasPrefix(code.SwiftCode, prefix) && filter.matches(code) && validOn(code, filter.AsOf) {
+			codes = append(codes, code)
+		}
+	}
+	slices.SortFunc(codes, func(a, b models.SwiftCode) int { return strings.Compare(a.SwiftCode, b.SwiftCode) })
+	if len(codes) > limit {
+		codes = codes[:limit]
+	}
+	return codes, nil
+}
+
+func (m *Memory) GetByBankNamePrefix(c context.Context, prefix string, limit int, filter Filter) ([]models.SwiftCode, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	prefix = strings.ToUpper(prefix)
+	codes := []models.SwiftCode{}
+	for _, code := range m.codes {
+		if strings.HasPrefix(strings.ToUpper(code.BankName), prefix) && filter.matches(code) && validOn(code, filter.AsOf) {
+			codes = append(codes, code)
+		}
+	}
+	slices.SortFunc(codes, func(a, b models.SwiftCode) int {
+		return cmp.Or(strings.Compare(strings.ToUpper(a.BankName), strings.ToUpper(b.BankName)), strings.Compare(a.SwiftCode, b.SwiftCode))
+	})
+	if len(codes) > limit {
+		codes = codes[:limit]
+	}
+	return codes, nil
+}
+
+func (m *Memory) GetCountryName(c context.Context, countryCode string) (string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, code := range m.codes {
+		if code.CountryISO2 == countryCode && code.ValidTo == "" {
+			return code.CountryName, nil
+		}
+	}
+	return "", ErrNotFound
+}
+
+func (m *Memory) GetCountryNames(c context.Context, countryCodes []string) (map[string]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	names := map[string]string{}
+	for _, code := range m.codes {
+		if _, ok := names[code.CountryISO2]; !ok && code.ValidTo == "" && slices.Contains(countryCodes, code.CountryISO2) {
+			names[code.CountryISO2] = code.CountryName
+		}
+	}
+	return names, nil
+}
+
+func (m *Memory) GetByCountryCode(c context.Context, countryCode string, filter Filter) ([]models.SwiftCode, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	codes := []models.SwiftCode{}
+	for i, code := range m.codes {
+		if code.CountryISO2 == countryCode && filter.matches(code) && validOn(code, filter.AsOf) {
+			code = m.withRevision(i)
+			code.CountryName = ""
+			codes = append(codes, code)
+		}
+	}
+	return codes, nil
+}
+
+func (m *Memory) DeleteByCode(c context.Context, code string) (int64, error) {
+	return m.CloseByCode(c, code, today())
+}
+
+func (m *Memory) CloseByCode(c context.Context, code string, validTo time.Time) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i, ok := m.index[code]
+	if !ok {
+		return 0, nil
+	}
+	if err := checkReplaced(m.codes[i].ValidFrom, validTo); err != nil {
+		return 0, err
+	}
+	m.close(i, validTo)
+	m.record(models.ChangeDeleted, code, nil)
+	return 1, nil
+}
+
+func (m *Memory) CloseByCodeAtVersion(c context.Context, code string, version int, validTo time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i, ok := m.index[code]
+	if !ok {
+		return ErrNotFound
+	}
+	if m.revisions[i].version != version {
+		return ErrVersionMismatch
+	}
+	m.close(i, validTo)
+	m.record(models.ChangeDeleted, code, nil)
+	return nil
+}
+
+func (m *Memory) ReplaceCode(c context.Context, code models.SwiftCode, version int) error {
+	validTo, err := replacedUntil(code)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i, ok := m.index[code.SwiftCode]
+	if !ok {
+		return ErrNotFound
+	}
+	if m.revisions[i].version != version {
+		return ErrVersionMismatch
+	}
+	if err := checkReplaced(m.codes[i].ValidFrom, validTo); err != nil {
+		return err
+	}
+	m.close(i, validTo)
+	m.insert(code)
+	m.record(models.ChangeUpdated, code.SwiftCode, changeRecord(code))
+	return nil
+}
+
+func (m *Memory) GetChanges(c context.Context, since int64, limit int) ([]models.Change, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	// IDs are positions in the feed, counted from 1.
+	start := int(min(max(since, 0), int64(len(m.changes))))
+	end := min(start+limit, len(m.changes))
+	return slices.Clone(m.changes[start:end]), nil
+}
+
+func (m *Memory) close(i int, validTo time.Time) {
+	m.codes[i].ValidTo = validTo.Format(models.DateLayout)
+	m.versions[m.codes[i].SwiftCode]++
+	m.revisions[i].version = m.versions[m.codes[i].SwiftCode]
+	m.revisions[i].updatedAt = time.Now()
+	delete(m.index, m.codes[i].SwiftCode)
+}
+
+func (m *Memory) LastModified(c context.Context, countryCode, codePrefix string) (time.Time, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var modified time.Time
+	for i, code := range m.codes {
+		if code.CountryISO2 == countryCode && strings.HasPrefix(code.SwiftCode, codePrefix) && m.revisions[i].updatedAt.After(modified) {
+			modified = m.revisions[i].updatedAt
+		}
+	}
+	if modified.IsZero() {
+		return time.Time{}, ErrNotFound
+	}
+	return modified, nil
+}
+
+func (m *Memory) ForEachCode(c context.Context, countryCode string, fn func(models.SwiftCode) error) error {
+	m.mu.RLock()
+	codes := make([]models.SwiftCode, 0, len(m.codes))
+	for _, code := range m.codes {
+		if (countryCode == "" || code.CountryISO2 == countryCode) && code.ValidTo == "" {
+			codes = append(codes, code)
+		}
+	}
+	m.mu.RUnlock()
+
+	for _, code := range codes {
+		if err := fn(code); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *Memory) InsertNationalID(c context.Context, id models.NationalID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.nationalIDs[id]; ok {
+		return ErrDuplicate
+	}
+	m.nationalIDs[id] = struct{}{}
+	return nil
+}
+
+func (m *Memory) GetByNationalID(c context.Context, scheme string, ids ...string) ([]models.SwiftCode, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	codes := []models.SwiftCode{}
+	for _, code := range m.codes {
+		if code.ValidTo != "" {
+			continue
+		}
+		for _, id := range ids {
+			if _, ok := m.nationalIDs[models.NationalID{Scheme: scheme, Identifier: id, SwiftCode: code.SwiftCode}]; ok {
+				codes = append(codes, code)
+				break
+			}
+		}
+	}
+	slices.SortFunc(codes, func(a, b models.SwiftCode) int { return strings.Compare(a.SwiftCode, b.SwiftCode) })
+	return codes, nil
+}
+
+func (m *Memory) InsertIdempotencyRecord(c context.Context, record models.IdempotencyRecord, expiredBefore time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	maps.DeleteFunc(m.idempotency, func(key string, record models.IdempotencyRecord) bool {
+		return record.CreatedAt.Before(expiredBefore)
+	})
+	if _, ok := m.idempotency[record.Key]; ok {
+		return ErrDuplicate
+	}
+	record.Body = slices.Clone(record.Body)
+	m.idempotency[record.Key] = record
+	return nil
+}
+
+func (m *Memory) GetIdempotencyRecord(c context.Context, key string) (models.IdempotencyRecord, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	record, ok := m.idempotency[key]
+	if !ok {
+		return models.IdempotencyRecord{}, ErrNotFound
+	}
+	record.Header = record.Header.Clone()
+	record.Body = slices.Clone(record.Body)
+	return record, nil
+}
+
+func (m *Memory) CompleteIdempotencyRecord(c context.Context, record models.IdempotencyRecord) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored, ok := m.idempotency[record.Key]
+	if !ok {
+		return nil
+	}
+	stored.Status = record.Status
+	stored.ContentType = record.ContentType
+	stored.Header = record.Header.Clone()
+	stored.Body = slices.Clone(record.Body)
+	m.idempotency[record.Key] = stored
+	return nil
+}
+
+func (m *Memory) DeleteIdempotencyRecord(c context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.idempotency, key)
+	return nil
+}
+
+// withRevision returns the record at i with its version and timestamps.
+func (m *Memory) withRevision(i int) models.SwiftCode {
+	code := m.codes[i]
+	code.Version = m.revisions[i].version
+	code.CreatedAt = m.revisions[i].createdAt
+	code.UpdatedAt = m.revisions[i].updatedAt
+	return code
+}
+
+// setLocationFlags mirrors the generated columns of the SQL backends.
+func setLocationFlags(code *models.SwiftCode) {
+	var location byte
+	if len(code.SwiftCode) >= 8 {
+		location = code.SwiftCode[7]
+	}
+	code.IsTest = location == '0'
+	code.IsPassive = location == '1'
+	code.IsReverseBilling = location == '2'
+}
+
+// validOn reports whether the code is valid on asOf, or current when asOf is
+// zero.
+func validOn(code models.SwiftCode, asOf time.Time) bool {
+	if asOf.IsZero() {
+		return code.ValidTo == ""
+	}
+	date := asOf.Format(models.DateLayout)
+	return (code.ValidFrom == "" || code.ValidFrom <= date) && (code.ValidTo == "" || code.ValidTo > date)
+}
+
+func (f Filter) matches(code models.SwiftCode) bool {
+	return (f.IsTest == nil || *f.IsTest == code.IsTest) &&
+		(f.IsPassive == nil || *f.IsPassive == code.IsPassive) &&
+		(f.IsReverseBilling == nil || *f.IsReverseBilling == code.IsReverseBilling)
+}
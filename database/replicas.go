@@ -0,0 +1,129 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// defaultReplicaCheckPeriod is how often replicas are pinged when no health
+// check period is set.
+const defaultReplicaCheckPeriod = 10 * time.Second
+
+type primaryKey struct{}
+
+// WithPrimary returns a context whose lookups go to the primary database
+// rather than a replica, so that they see the writes made just before.
+func WithPrimary(c context.Context) context.Context {
+	return context.WithValue(c, primaryKey{}, true)
+}
+
+// ReadsPrimary reports whether lookups made with c go to the primary
+// database.
+func ReadsPrimary(c context.Context) bool {
+	primary, _ := c.Value(primaryKey{}).(bool)
+	return primary
+}
+
+type replica struct {
+	pool    *pgxpool.Pool
+	healthy atomic.Bool
+}
+
+// replicas are the read-only pools lookups are spread over. Replicas that
+// fail to answer are skipped until a health check finds them up again.
+type replicas struct {
+	pools []*replica
+	next  atomic.Uint64
+	done  chan struct{}
+	wg    sync.WaitGroup
+}
+
+func newReplicas(c context.Context, configs []*pgxpool.Config, checkPeriod time.Duration) (*replicas, error) {
+	r := &replicas{done: make(chan struct{})}
+	for _, config := range configs {
+		pool, err := pgxpool.NewWithConfig(c, config)
+		if err != nil {
+			r.close()
+			return nil, err
+		}
+		r.pools = append(r.pools, &replica{pool: pool})
+	}
+	r.check(c)
+
+	if checkPeriod <= 0 {
+		checkPeriod = defaultReplicaCheckPeriod
+	}
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		ticker := time.NewTicker(checkPeriod)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				r.check(context.Background())
+			case <-r.done:
+				return
+			}
+		}
+	}()
+	return r, nil
+}
+
+// check pings every replica and records whether it answered.
+func (r *replicas) check(c context.Context) {
+	for _, replica := range r.pools {
+		ctx, cancel := context.WithTimeout(c, defaultReplicaCheckPeriod)
+		replica.healthy.Store(replica.pool.Ping(ctx) == nil)
+		cancel()
+	}
+}
+
+// pick returns the next healthy replica, or nil if there is none.
+func (r *replicas) pick() *replica {
+	if r == nil {
+		return nil
+	}
+	for range r.pools {
+		replica := r.pools[r.next.Add(1)%uint64(len(r.pools))]
+		if replica.healthy.Load() {
+			return replica
+		}
+	}
+	return nil
+}
+
+func (r *replicas) close() {
+	if r == nil {
+		return
+	}
+	close(r.done)
+	r.wg.Wait()
+	for _, replica := range r.pools {
+		replica.pool.Close()
+	}
+}
+
+// read runs a lookup on a healthy replica, unless c asks for the primary.
+// A replica that can't be reached is marked as down and the lookup is
+// retried on the primary.
+func (db *Database) read(c context.Context, fn func(pool *pgxpool.Pool) error) error {
+	var replica *replica
+	if !ReadsPrimary(c) {
+		replica = db.replicas.pick()
+	}
+	if replica == nil {
+		return fn(db.pool)
+	}
+	err := fn(replica.pool)
+	if errors.Is(err, ErrUnavailable) {
+		replica.healthy.Store(false)
+		return fn(db.pool)
+	}
+	return err
+}
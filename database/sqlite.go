@@ -0,0 +1,1031 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rtsncs/remitly-swift-api/models"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+type SQLite struct {
+	db *sql.DB
+}
+
+// Each entry upgrades the schema by one version, tracked in PRAGMA user_version.
+var sqliteMigrations = []string{
+	`
+	CREATE TABLE swift_codes (
+		id INTEGER PRIMARY KEY,
+		swift_code TEXT UNIQUE NOT NULL,
+		bank_name TEXT NOT NULL,
+		address TEXT,
+		country_iso2 TEXT NOT NULL,
+		country_name TEXT NOT NULL,
+		is_headquarter INTEGER NOT NULL
+	);
+	`,
+	`
+	ALTER TABLE swift_codes ADD COLUMN is_test INTEGER GENERATED ALWAYS AS (substr(swift_code, 8, 1) = '0') VIRTUAL;
+	ALTER TABLE swift_codes ADD COLUMN is_passive INTEGER GENERATED ALWAYS AS (substr(swift_code, 8, 1) = '1') VIRTUAL;
+	ALTER TABLE swift_codes ADD COLUMN is_reverse_billing INTEGER GENERATED ALWAYS AS (substr(swift_code, 8, 1) = '2') VIRTUAL;
+	`,
+	`
+	CREATE TABLE bank_codes (
+		country_iso2 TEXT NOT NULL,
+		bank_code TEXT NOT NULL,
+		swift_code TEXT NOT NULL,
+		PRIMARY KEY (country_iso2, bank_code, swift_code)
+	);
+	`,
+	`
+	CREATE TABLE bank_identifiers (
+		scheme TEXT NOT NULL,
+		identifier TEXT NOT NULL,
+		swift_code TEXT NOT NULL,
+		PRIMARY KEY (scheme, identifier, swift_code)
+	);
+	`,
+	`
+	CREATE INDEX swift_codes_bank_name_prefix ON swift_codes (upper(bank_name));
+	`,
+	// SQLite can't drop a UNIQUE constraint, so the table is rebuilt to keep
+	// codes unique among current records only.
+	`
+	CREATE TABLE swift_codes_new (
+		id INTEGER PRIMARY KEY,
+		swift_code TEXT NOT NULL,
+		bank_name TEXT NOT NULL,
+		address TEXT,
+		country_iso2 TEXT NOT NULL,
+		country_name TEXT NOT NULL,
+		is_headquarter INTEGER NOT NULL,
+		is_test INTEGER GENERATED ALWAYS AS (substr(swift_code, 8, 1) = '0') VIRTUAL,
+		is_passive INTEGER GENERATED ALWAYS AS (substr(swift_code, 8, 1) = '1') VIRTUAL,
+		is_reverse_billing INTEGER GENERATED ALWAYS AS (substr(swift_code, 8, 1) = '2') VIRTUAL,
+		valid_from TEXT,
+		valid_to TEXT
+	);
+	INSERT INTO swift_codes_new (id, swift_code, bank_name, address, country_iso2, country_name, is_headquarter)
+		SELECT id, swift_code, bank_name, address, country_iso2, country_name, is_headquarter FROM swift_codes;
+	DROP TABLE swift_codes;
+	ALTER TABLE swift_codes_new RENAME TO swift_codes;
+	CREATE UNIQUE INDEX swift_codes_current_code ON swift_codes (swift_code) WHERE valid_to IS NULL;
+	CREATE INDEX swift_codes_code ON swift_codes (swift_code);
+	CREATE INDEX swift_codes_bank_name_prefix ON swift_codes (upper(bank_name));
+	`,
+	// created_at is a Unix time in nanoseconds.
+	`
+	CREATE TABLE idempotency_records (
+		key TEXT PRIMARY KEY,
+		request_hash TEXT NOT NULL,
+		status INTEGER NOT NULL,
+		content_type TEXT NOT NULL,
+		body BLOB,
+		created_at INTEGER NOT NULL
+	);
+	CREATE INDEX idempotency_records_created_at ON idempotency_records (created_at);
+	`,
+	// created_at and updated_at are Unix times in nanoseconds. Existing
+	// records count as created by the migration.
+	`
+	ALTER TABLE swift_codes ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+	ALTER TABLE swift_codes ADD COLUMN created_at INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE swift_codes ADD COLUMN updated_at INTEGER NOT NULL DEFAULT 0;
+	UPDATE swift_codes SET
+		created_at = CAST(strftime('%s', 'now') AS INTEGER) * 1000000000,
+		updated_at = CAST(strftime('%s', 'now') AS INTEGER) * 1000000000;
+	`,
+	// record is the JSON of created and updated codes, changed_at a Unix time
+	// in nanoseconds. AUTOINCREMENT keeps IDs from being reused.
+	`
+	CREATE TABLE swift_code_changes (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		type TEXT NOT NULL,
+		swift_code TEXT NOT NULL,
+		version INTEGER NOT NULL,
+		record TEXT,
+		changed_at INTEGER NOT NULL
+	);
+	`,
+	// The bank codes of IBANs are stored with the other national
+	// identifiers, under the scheme of models.BankCodeScheme.
+	`
+	INSERT OR IGNORE INTO bank_identifiers (scheme, identifier, swift_code)
+		SELECT CASE country_iso2 WHEN 'DE' THEN 'blz' ELSE 'iban-' || lower(country_iso2) END, bank_code, swift_code
+		FROM bank_codes;
+	DROP TABLE bank_codes;
+	`,
+	// Used to index bank names with a custom function, which other clients
+	// can't evaluate. The next migration replaces that index.
+	`
+	`,
+	// bank_name_upper is bank_name folded with strings.ToUpper, like the
+	// other stores; SQLite's upper only folds ASCII letters. Existing rows
+	// are filled in by sqliteMigrationSteps.
+	`
+	DROP INDEX swift_codes_bank_name_prefix;
+	ALTER TABLE swift_codes ADD COLUMN bank_name_upper TEXT NOT NULL DEFAULT '';
+	CREATE INDEX swift_codes_bank_name_prefix ON swift_codes (bank_name_upper);
+	`,
+	// header is the JSON of the replayed response headers.
+	`
+	ALTER TABLE idempotency_records ADD COLUMN header TEXT;
+	`,
+}
+
+// sqliteMigrationSteps holds the work of migrations that SQL can't do, keyed
+// by the index of the migration in sqliteMigrations and run after its SQL.
+var sqliteMigrationSteps = map[int]func(c context.Context, tx *sql.Tx) error{
+	11: fillSQLiteBankNameUpper,
+}
+
+func fillSQLiteBankNameUpper(c context.Context, tx *sql.Tx) error {
+	rows, err := tx.QueryContext(c, `SELECT id, bank_name FROM swift_codes;`)
+	if err != nil {
+		return err
+	}
+	names := map[int64]string{}
+	for rows.Next() {
+		var id int64
+		var name string
+		if err := rows.Scan(&id, &name); err != nil {
+			rows.Close()
+			return err
+		}
+		names[id] = name
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for id, name := range names {
+		if _, err := tx.ExecContext(c, `UPDATE swift_codes SET bank_name_upper = ? WHERE id = ?;`, strings.ToUpper(name), id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func ConnectSQLite(c context.Context, path string) (SQLite, error) {
+	sep := "?"
+	if strings.Contains(path, "?") {
+		sep = "&"
+	}
+	dsn := path + sep + "_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_txlock=immediate"
+
+	conn, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return SQLite{}, fmt.Errorf("Unable to open database: %w", err)
+	}
+	if err = conn.PingContext(c); err != nil {
+		conn.Close()
+		return SQLite{}, fmt.Errorf("Failed to ping database: %w", err)
+	}
+
+	db := SQLite{conn}
+	if err = db.migrate(c); err != nil {
+		conn.Close()
+		return SQLite{}, fmt.Errorf("Failed to migrate database: %w", err)
+	}
+
+	return db, nil
+}
+
+func (db *SQLite) Ping(c context.Context) error {
+	return db.db.PingContext(c)
+}
+
+func (db *SQLite) Close() {
+	db.db.Close()
+}
+
+func (db *SQLite) migrate(c context.Context) error {
+	var version int
+	if err := db.db.QueryRowContext(c, "PRAGMA user_version;").Scan(&version); err != nil {
+		return err
+	}
+
+	for ; version < len(sqliteMigrations); version++ {
+		tx, err := db.db.BeginTx(c, nil)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(c, sqliteMigrations[version]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %d: %w", version+1, err)
+		}
+		if step, ok := sqliteMigrationSteps[version]; ok {
+			if err := step(c, tx); err != nil {
+				tx.Rollback()
+				return fmt.Errorf("migration %d: %w", version+1, err)
+			}
+		}
+		if _, err := tx.ExecContext(c, fmt.Sprintf("PRAGMA user_version = %d;", version+1)); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (db *SQLite) InsertCode(c context.Context, code models.SwiftCode) error {
+	query := `
+	INSERT INTO swift_codes (
+		swift_code,
+		bank_name,
+		address,
+		country_iso2,
+		country_name,
+		is_headquarter,
+		valid_from,
+		valid_to,
+		version,
+		created_at,
+		updated_at,
+		bank_name_upper
+	) VALUES (
+		?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8,
+		COALESCE((SELECT max(version) FROM swift_codes WHERE swift_code = ?1), 0) + 1,
+		?9, ?9, ?10
+	) RETURNING version;
+	`
+	return db.changeTx(c, func(tx *sql.Tx) error {
+		var version int
+		err := tx.QueryRowContext(c, query, code.SwiftCode, code.BankName, code.Address, code.CountryISO2, code.CountryName, code.IsHeadquarter, nullableDate(code.ValidFrom), nullableDate(code.ValidTo), time.Now().UnixNano(), strings.ToUpper(code.BankName)).Scan(&version)
+		if err != nil {
+			return sqliteInsertError(err)
+		}
+		return recordSQLiteChange(c, tx, models.ChangeCreated, code.SwiftCode, version, changeRecord(code))
+	})
+}
+
+// changeTx runs fn in a transaction that may record changes. Transactions
+// take the write lock as they begin, so changes commit in the order of their
+// IDs.
+func (db *SQLite) changeTx(c context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := db.db.BeginTx(c, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// recordSQLiteChange adds a change to the change feed.
+func recordSQLiteChange(c context.Context, tx *sql.Tx, changeType models.ChangeType, code string, version int, record *models.SwiftCode) error {
+	var recordJSON any
+	if record != nil {
+		b, err := json.Marshal(record)
+		if err != nil {
+			return err
+		}
+		recordJSON = string(b)
+	}
+	query := `
+	INSERT INTO swift_code_changes (type, swift_code, version, record, changed_at)
+	VALUES (?, ?, ?, ?, ?);
+	`
+	_, err := tx.ExecContext(c, query, string(changeType), code, version, recordJSON, time.Now().UnixNano())
+	return err
+}
+
+func (db *SQLite) GetByCode(c context.Context, code string) (models.SwiftCode, error) {
+	return db.GetByCodeAsOf(c, code, time.Time{})
+}
+
+func (db *SQLite) GetByCodeAsOf(c context.Context, code string, asOf time.Time) (models.SwiftCode, error) {
+	sql := `
+	SELECT
+		swift_code,
+		bank_name,
+		address,
+		country_iso2,
+		country_name,
+		is_headquarter,
+		is_test,
+		is_passive,
+		is_reverse_billing,
+		COALESCE(valid_from, ''),
+		COALESCE(valid_to, ''),
+		version,
+		created_at,
+		updated_at
+	FROM swift_codes
+	WHERE swift_code = ?1 AND CASE WHEN ?2 IS NULL THEN valid_to IS NULL
+		ELSE (valid_from IS NULL OR valid_from <= ?2) AND (valid_to IS NULL OR valid_to > ?2) END;
+	`
+	var result models.SwiftCode
+	var createdAt, updatedAt int64
+	err := db.db.QueryRowContext(c, sql, code, dateArg(asOf)).Scan(
+		&result.SwiftCode,
+		&result.BankName,
+		&result.Address,
+		&result.CountryISO2,
+		&result.CountryName,
+		&result.IsHeadquarter,
+		&result.IsTest,
+		&result.IsPassive,
+		&result.IsReverseBilling,
+		&result.ValidFrom,
+		&result.ValidTo,
+		&result.Version,
+		&createdAt,
+		&updatedAt,
+	)
+	if err != nil {
+		return models.SwiftCode{}, sqliteError(err)
+	}
+	result.CreatedAt = time.Unix(0, createdAt).UTC()
+	result.UpdatedAt = time.Unix(0, updatedAt).UTC()
+	return result, nil
+}
+
+func (db *SQLite) GetByCodes(c context.Context, codes []string) ([]models.SwiftCode, error) {
+	sql := `
+	SELECT
+		swift_code,
+		bank_name,
+		address,
+		country_iso2,
+		country_name,
+		is_headquarter,
+		is_test,
+		is_passive,
+		is_reverse_billing,
+		COALESCE(valid_from, ''),
+		COALESCE(valid_to, ''),
+		version,
+		created_at,
+		updated_at
+	FROM swift_codes
+	WHERE swift_code IN (SELECT value FROM json_each(?)) AND valid_to IS NULL
+	ORDER BY swift_code;
+	`
+	codesJSON, err := json.Marshal(codes)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := db.db.QueryContext(c, sql, string(codesJSON))
+	if err != nil {
+		return nil, err
+	}
+
+	return collectSQLiteRevisionsWithCountry(rows)
+}
+
+func (db *SQLite) GetBranches(c context.Context, headquaterCode string) ([]models.SwiftCode, error) {
+	return db.GetBranchesAsOf(c, headquaterCode, time.Time{})
+}
+
+func (db *SQLite) GetBranchesAsOf(c context.Context, headquaterCode string, asOf time.Time) ([]models.SwiftCode, error) {
+	sql := `
+	SELECT
+		swift_code,
+		bank_name,
+		address,
+		country_iso2,
+		is_headquarter,
+		is_test,
+		is_passive,
+		is_reverse_billing,
+		COALESCE(valid_from, ''),
+		COALESCE(valid_to, ''),
+		version,
+		created_at,
+		updated_at
+	FROM swift_codes
+	WHERE substr(swift_code, 1, 8) = ?1 AND NOT swift_code GLOB '*XXX'
+		AND CASE WHEN ?2 IS NULL THEN valid_to IS NULL
+		ELSE (valid_from IS NULL OR valid_from <= ?2) AND (valid_to IS NULL OR valid_to > ?2) END;
+	`
+	rows, err := db.db.QueryContext(c, sql, headquaterCode[:8], dateArg(asOf))
+	if err != nil {
+		return nil, err
+	}
+
+	return collectSQLiteCodes(rows)
+}
+
+func (db *SQLite) GetBranchesOf(c context.Context, headquarterCodes []string) ([]models.SwiftCode, error) {
+	sql := `
+	SELECT
+		swift_code,
+		bank_name,
+		address,
+		country_iso2,
+		is_headquarter,
+		is_test,
+		is_passive,
+		is_reverse_billing,
+		COALESCE(valid_from, ''),
+		COALESCE(valid_to, ''),
+		version,
+		created_at,
+		updated_at
+	FROM swift_codes
+	WHERE substr(swift_code, 1, 8) IN (SELECT value FROM json_each(?))
+		AND NOT swift_code GLOB '*XXX' AND valid_to IS NULL
+	ORDER BY swift_code;
+	`
+	prefixesJSON, err := json.Marshal(branchPrefixes(headquarterCodes))
+	if err != nil {
+		return nil, err
+	}
+	rows, err := db.db.QueryContext(c, sql, string(prefixesJSON))
+	if err != nil {
+		return nil, err
+	}
+
+	return collectSQLiteCodes(rows)
+}
+
+func (db *SQLite) GetByCodePrefix(c context.Context, prefix string, limit int, filter Filter) ([]models.SwiftCode, error) {
+	sql := `
+	SELECT
+		swift_code,
+		bank_name,
+		address,
+		country_iso2,
+		country_name,
+		is_headquarter,
+		is_test,
+		is_passive,
+		is_reverse_billing,
+		COALESCE(valid_from, ''),
+		COALESCE(valid_to, '')
+	FROM swift_codes
+	WHERE swift_code GLOB ?1 || '*'
+		AND (?3 IS NULL OR is_test = ?3)
+		AND (?4 IS NULL OR is_passive = ?4)
+		AND (?5 IS NULL OR is_reverse_billing = ?5)
+		AND CASE WHEN ?6 IS NULL THEN valid_to IS NULL
+		ELSE (valid_from IS NULL OR valid_from <= ?6) AND (valid_to IS NULL OR valid_to > ?6) END
+	ORDER BY swift_code
+	LIMIT ?2;
+	`
+	rows, err := db.db.QueryContext(c, sql, escapeGlob(prefix), limit, filter.IsTest, filter.IsPassive, filter.IsReverseBilling, dateArg(filter.AsOf))
+	if err != nil {
+		return nil, err
+	}
+
+	return collectSQLiteCodesWithCountry(rows)
+}
+
+// GetByBankNamePrefix matches with a range over bank_name_upper rather than
+// LIKE, whose case folding only covers ASCII.
+func (db *SQLite) GetByBankNamePrefix(c context.Context, prefix string, limit int, filter Filter) ([]models.SwiftCode, error) {
+	sql := `
+	SELECT
+		swift_code,
+		bank_name,
+		address,
+		country_iso2,
+		country_name,
+		is_headquarter,
+		is_test,
+		is_passive,
+		is_reverse_billing,
+		COALESCE(valid_from, ''),
+		COALESCE(valid_to, '')
+	FROM swift_codes
+	WHERE bank_name_upper >= ?1 AND bank_name_upper < ?1 || char(1114111)
+		AND (?3 IS NULL OR is_test = ?3)
+		AND (?4 IS NULL OR is_passive = ?4)
+		AND (?5 IS NULL OR is_reverse_billing = ?5)
+		AND CASE WHEN ?6 IS NULL THEN valid_to IS NULL
+		ELSE (valid_from IS NULL OR valid_from <= ?6) AND (valid_to IS NULL OR valid_to > ?6) END
+	ORDER BY bank_name_upper, swift_code
+	LIMIT ?2;
+	`
+	rows, err := db.db.QueryContext(c, sql, strings.ToUpper(prefix), limit, filter.IsTest, filter.IsPassive, filter.IsReverseBilling, dateArg(filter.AsOf))
+	if err != nil {
+		return nil, err
+	}
+
+	return collectSQLiteCodesWithCountry(rows)
+}
+
+func (db *SQLite) GetCountryName(c context.Context, countryCode string) (string, error) {
+	sql := `
+	SELECT country_name
+	FROM swift_codes
+	WHERE country_iso2 = ? AND valid_to IS NULL
+	LIMIT 1;
+	`
+	var name string
+	err := db.db.QueryRowContext(c, sql, countryCode).Scan(&name)
+	return name, sqliteError(err)
+}
+
+func (db *SQLite) GetCountryNames(c context.Context, countryCodes []string) (map[string]string, error) {
+	query := `
+	SELECT country_iso2, min(country_name)
+	FROM swift_codes
+	WHERE country_iso2 IN (SELECT value FROM json_each(?)) AND valid_to IS NULL
+	GROUP BY country_iso2;
+	`
+	codesJSON, err := json.Marshal(countryCodes)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := db.db.QueryContext(c, query, string(codesJSON))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	names := map[string]string{}
+	for rows.Next() {
+		var iso2, name string
+		if err := rows.Scan(&iso2, &name); err != nil {
+			return nil, err
+		}
+		names[iso2] = name
+	}
+	return names, rows.Err()
+}
+
+func (db *SQLite) GetByCountryCode(c context.Context, countryCode string, filter Filter) ([]models.SwiftCode, error) {
+	sql := `
+	SELECT
+		swift_code,
+		bank_name,
+		address,
+		country_iso2,
+		is_headquarter,
+		is_test,
+		is_passive,
+		is_reverse_billing,
+		COALESCE(valid_from, ''),
+		COALESCE(valid_to, ''),
+		version,
+		created_at,
+		updated_at
+	FROM swift_codes
+	WHERE country_iso2 = ?1
+		AND (?2 IS NULL OR is_test = ?2)
+		AND (?3 IS NULL OR is_passive = ?3)
+		AND (?4 IS NULL OR is_reverse_billing = ?4)
+		AND CASE WHEN ?5 IS NULL THEN valid_to IS NULL
+		ELSE (valid_from IS NULL OR valid_from <= ?5) AND (valid_to IS NULL OR valid_to > ?5) END;
+	`
+	rows, err := db.db.QueryContext(c, sql, countryCode, filter.IsTest, filter.IsPassive, filter.IsReverseBilling, dateArg(filter.AsOf))
+	if err != nil {
+		return nil, err
+	}
+
+	return collectSQLiteCodes(rows)
+}
+
+func (db *SQLite) DeleteByCode(c context.Context, code string) (int64, error) {
+	return db.CloseByCode(c, code, today())
+}
+
+func (db *SQLite) CloseByCode(c context.Context, code string, validTo time.Time) (int64, error) {
+	query := `SELECT version FROM swift_codes WHERE swift_code = ? AND valid_to IS NULL;`
+	var closed int64
+	err := db.changeTx(c, func(tx *sql.Tx) error {
+		var current int
+		err := tx.QueryRowContext(c, query, code).Scan(&current)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		version, err := closeSQLiteReplaced(c, tx, code, current, validTo)
+		if err != nil {
+			return err
+		}
+		closed = 1
+		return recordSQLiteChange(c, tx, models.ChangeDeleted, code, version, nil)
+	})
+	return closed, err
+}
+
+func (db *SQLite) CloseByCodeAtVersion(c context.Context, code string, version int, validTo time.Time) error {
+	return db.changeTx(c, func(tx *sql.Tx) error {
+		closed, err := closeSQLiteAtVersion(c, tx, code, version, validTo)
+		if err != nil {
+			return err
+		}
+		return recordSQLiteChange(c, tx, models.ChangeDeleted, code, closed, nil)
+	})
+}
+
+func (db *SQLite) ReplaceCode(c context.Context, code models.SwiftCode, version int) error {
+	validTo, err := replacedUntil(code)
+	if err != nil {
+		return err
+	}
+	query := `
+	INSERT INTO swift_codes (
+		swift_code,
+		bank_name,
+		address,
+		country_iso2,
+		country_name,
+		is_headquarter,
+		valid_from,
+		valid_to,
+		version,
+		created_at,
+		updated_at,
+		bank_name_upper
+	) VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8, ?9, ?10, ?10, ?11)
+	RETURNING version;
+	`
+	return db.changeTx(c, func(tx *sql.Tx) error {
+		closed, err := closeSQLiteReplaced(c, tx, code.SwiftCode, version, validTo)
+		if err != nil {
+			return err
+		}
+		var inserted int
+		err = tx.QueryRowContext(c, query, code.SwiftCode, code.BankName, code.Address, code.CountryISO2, code.CountryName, code.IsHeadquarter, nullableDate(code.ValidFrom), nullableDate(code.ValidTo), closed+1, time.Now().UnixNano(), strings.ToUpper(code.BankName)).Scan(&inserted)
+		if err != nil {
+			return sqliteInsertError(err)
+		}
+		return recordSQLiteChange(c, tx, models.ChangeUpdated, code.SwiftCode, inserted, changeRecord(code))
+	})
+}
+
+// closeSQLiteReplaced retires the current record of code as of validTo,
+// after checking that it is still at version and became valid by then, and
+// returns the version of the retired record.
+func closeSQLiteReplaced(c context.Context, tx *sql.Tx, code string, version int, validTo time.Time) (int, error) {
+	var validFrom string
+	var current int
+	query := `SELECT COALESCE(valid_from, ''), version FROM swift_codes WHERE swift_code = ? AND valid_to IS NULL;`
+	if err := tx.QueryRowContext(c, query, code).Scan(&validFrom, &current); err != nil {
+		return 0, sqliteError(err)
+	}
+	if current != version {
+		return 0, ErrVersionMismatch
+	}
+	if err := checkReplaced(validFrom, validTo); err != nil {
+		return 0, err
+	}
+	query = `
+	UPDATE swift_codes SET valid_to = ?, version = version + 1, updated_at = ?
+	WHERE swift_code = ? AND valid_to IS NULL
+	RETURNING version;
+	`
+	var closed int
+	err := tx.QueryRowContext(c, query, dateArg(validTo), time.Now().UnixNano(), code).Scan(&closed)
+	return closed, err
+}
+
+// closeSQLiteAtVersion retires the current record of code if it is still at
+// version, and returns the version of the retired record.
+func closeSQLiteAtVersion(c context.Context, tx *sql.Tx, code string, version int, validTo time.Time) (int, error) {
+	query := `
+	UPDATE swift_codes SET valid_to = ?, version = version + 1, updated_at = ?
+	WHERE swift_code = ? AND valid_to IS NULL AND version = ?
+	RETURNING version;
+	`
+	var closed int
+	err := tx.QueryRowContext(c, query, dateArg(validTo), time.Now().UnixNano(), code, version).Scan(&closed)
+	if !errors.Is(err, sql.ErrNoRows) {
+		return closed, err
+	}
+	// Tell a changed record apart from a missing one.
+	var current int
+	query = `SELECT version FROM swift_codes WHERE swift_code = ? AND valid_to IS NULL;`
+	if err := tx.QueryRowContext(c, query, code).Scan(&current); err != nil {
+		return 0, sqliteError(err)
+	}
+	return 0, ErrVersionMismatch
+}
+
+func (db *SQLite) GetChanges(c context.Context, since int64, limit int) ([]models.Change, error) {
+	query := `
+	SELECT id, type, swift_code, version, record, changed_at
+	FROM swift_code_changes
+	WHERE id > ?
+	ORDER BY id
+	LIMIT ?;
+	`
+	rows, err := db.db.QueryContext(c, query, since, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	changes := []models.Change{}
+	for rows.Next() {
+		var change models.Change
+		var record *string
+		var changedAt int64
+		if err := rows.Scan(&change.ID, &change.Type, &change.SwiftCode, &change.Version, &record, &changedAt); err != nil {
+			return nil, err
+		}
+		if record != nil {
+			if err := json.Unmarshal([]byte(*record), &change.Record); err != nil {
+				return nil, err
+			}
+		}
+		change.ChangedAt = time.Unix(0, changedAt).UTC()
+		changes = append(changes, change)
+	}
+	return changes, rows.Err()
+}
+
+func (db *SQLite) LastModified(c context.Context, countryCode, codePrefix string) (time.Time, error) {
+	sql := `
+	SELECT max(updated_at)
+	FROM swift_codes
+	WHERE country_iso2 = ? AND swift_code GLOB ? || '*';
+	`
+	var modified *int64
+	if err := db.db.QueryRowContext(c, sql, countryCode, escapeGlob(codePrefix)).Scan(&modified); err != nil {
+		return time.Time{}, err
+	}
+	if modified == nil {
+		return time.Time{}, ErrNotFound
+	}
+	return time.Unix(0, *modified).UTC(), nil
+}
+
+func (db *SQLite) ForEachCode(c context.Context, countryCode string, fn func(models.SwiftCode) error) error {
+	sql := `
+	SELECT
+		swift_code,
+		bank_name,
+		address,
+		country_iso2,
+		country_name,
+		is_headquarter,
+		is_test,
+		is_passive,
+		is_reverse_billing,
+		COALESCE(valid_from, ''),
+		COALESCE(valid_to, '')
+	FROM swift_codes
+	WHERE (?1 = '' OR country_iso2 = ?1) AND valid_to IS NULL
+	ORDER BY id;
+	`
+	rows, err := db.db.QueryContext(c, sql, countryCode)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var code models.SwiftCode
+		err := rows.Scan(
+			&code.SwiftCode,
+			&code.BankName,
+			&code.Address,
+			&code.CountryISO2,
+			&code.CountryName,
+			&code.IsHeadquarter,
+			&code.IsTest,
+			&code.IsPassive,
+			&code.IsReverseBilling,
+			&code.ValidFrom,
+			&code.ValidTo,
+		)
+		if err != nil {
+			return err
+		}
+		if err := fn(code); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+func (db *SQLite) InsertNationalID(c context.Context, id models.NationalID) error {
+	sql := `
+	INSERT INTO bank_identifiers (
+		scheme,
+		identifier,
+		swift_code
+	) VALUES (
+		?, ?, ?
+	);
+	`
+	_, err := db.db.ExecContext(c, sql, id.Scheme, id.Identifier, id.SwiftCode)
+	return sqliteInsertError(err)
+}
+
+func (db *SQLite) GetByNationalID(c context.Context, scheme string, ids ...string) ([]models.SwiftCode, error) {
+	query := `
+	SELECT DISTINCT
+		s.swift_code,
+		s.bank_name,
+		s.address,
+		s.country_iso2,
+		s.country_name,
+		s.is_headquarter,
+		s.is_test,
+		s.is_passive,
+		s.is_reverse_billing,
+		COALESCE(s.valid_from, ''),
+		COALESCE(s.valid_to, '')
+	FROM bank_identifiers b
+	JOIN swift_codes s ON s.swift_code = b.swift_code AND s.valid_to IS NULL
+	WHERE b.scheme = ? AND b.identifier IN (SELECT value FROM json_each(?))
+	ORDER BY s.swift_code;
+	`
+	idsJSON, err := json.Marshal(ids)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := db.db.QueryContext(c, query, scheme, string(idsJSON))
+	if err != nil {
+		return nil, err
+	}
+
+	return collectSQLiteCodesWithCountry(rows)
+}
+
+// collectSQLiteCodes collects codes selected with their revision but without
+// a country name.
+func collectSQLiteCodes(rows *sql.Rows) ([]models.SwiftCode, error) {
+	defer rows.Close()
+
+	codes := []models.SwiftCode{}
+	for rows.Next() {
+		var code models.SwiftCode
+		var createdAt, updatedAt int64
+		err := rows.Scan(
+			&code.SwiftCode,
+			&code.BankName,
+			&code.Address,
+			&code.CountryISO2,
+			&code.IsHeadquarter,
+			&code.IsTest,
+			&code.IsPassive,
+			&code.IsReverseBilling,
+			&code.ValidFrom,
+			&code.ValidTo,
+			&code.Version,
+			&createdAt,
+			&updatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		code.CreatedAt = time.Unix(0, createdAt).UTC()
+		code.UpdatedAt = time.Unix(0, updatedAt).UTC()
+		codes = append(codes, code)
+	}
+
+	return codes, rows.Err()
+}
+
+// collectSQLiteRevisionsWithCountry collects codes selected with their
+// country name and revision.
+func collectSQLiteRevisionsWithCountry(rows *sql.Rows) ([]models.SwiftCode, error) {
+	defer rows.Close()
+
+	codes := []models.SwiftCode{}
+	for rows.Next() {
+		var code models.SwiftCode
+		var createdAt, updatedAt int64
+		err := rows.Scan(
+			&code.SwiftCode,
+			&code.BankName,
+			&code.Address,
+			&code.CountryISO2,
+			&code.CountryName,
+			&code.IsHeadquarter,
+			&code.IsTest,
+			&code.IsPassive,
+			&code.IsReverseBilling,
+			&code.ValidFrom,
+			&code.ValidTo,
+			&code.Version,
+			&createdAt,
+			&updatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		code.CreatedAt = time.Unix(0, createdAt).UTC()
+		code.UpdatedAt = time.Unix(0, updatedAt).UTC()
+		codes = append(codes, code)
+	}
+
+	return codes, rows.Err()
+}
+
+func collectSQLiteCodesWithCountry(rows *sql.Rows) ([]models.SwiftCode, error) {
+	defer rows.Close()
+
+	codes := []models.SwiftCode{}
+	for rows.Next() {
+		var code models.SwiftCode
+		err := rows.Scan(
+			&code.SwiftCode,
+			&code.BankName,
+			&code.Address,
+			&code.CountryISO2,
+			&code.CountryName,
+			&code.IsHeadquarter,
+			&code.IsTest,
+			&code.IsPassive,
+			&code.IsReverseBilling,
+			&code.ValidFrom,
+			&code.ValidTo,
+		)
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+	}
+
+	return codes, rows.Err()
+}
+
+func (db *SQLite) InsertIdempotencyRecord(c context.Context, record models.IdempotencyRecord, expiredBefore time.Time) error {
+	if _, err := db.db.ExecContext(c, `DELETE FROM idempotency_records WHERE created_at < ?;`, expiredBefore.UnixNano()); err != nil {
+		return err
+	}
+	sql := `
+	INSERT INTO idempotency_records (
+		key,
+		request_hash,
+		status,
+		content_type,
+		body,
+		created_at
+	) VALUES (
+		?, ?, ?, ?, ?, ?
+	);
+	`
+	_, err := db.db.ExecContext(c, sql, record.Key, record.RequestHash, record.Status, record.ContentType, record.Body, record.CreatedAt.UnixNano())
+	return sqliteInsertError(err)
+}
+
+func (db *SQLite) GetIdempotencyRecord(c context.Context, key string) (models.IdempotencyRecord, error) {
+	sql := `
+	SELECT key, request_hash, status, content_type, COALESCE(header, '{}'), body, created_at
+	FROM idempotency_records
+	WHERE key = ?;
+	`
+	var record models.IdempotencyRecord
+	var header string
+	var createdAt int64
+	err := db.db.QueryRowContext(c, sql, key).Scan(&record.Key, &record.RequestHash, &record.Status, &record.ContentType, &header, &record.Body, &createdAt)
+	if err != nil {
+		return models.IdempotencyRecord{}, sqliteError(err)
+	}
+	if err := json.Unmarshal([]byte(header), &record.Header); err != nil {
+		return models.IdempotencyRecord{}, err
+	}
+	record.CreatedAt = time.Unix(0, createdAt).UTC()
+	return record, nil
+}
+
+func (db *SQLite) CompleteIdempotencyRecord(c context.Context, record models.IdempotencyRecord) error {
+	header, err := json.Marshal(record.Header)
+	if err != nil {
+		return err
+	}
+	sql := `UPDATE idempotency_records SET status = ?, content_type = ?, header = ?, body = ? WHERE key = ?;`
+	_, err = db.db.ExecContext(c, sql, record.Status, record.ContentType, string(header), record.Body, record.Key)
+	return err
+}
+
+func (db *SQLite) DeleteIdempotencyRecord(c context.Context, key string) error {
+	_, err := db.db.ExecContext(c, `DELETE FROM idempotency_records WHERE key = ?;`, key)
+	return err
+}
+
+func sqliteInsertError(err error) error {
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) && (sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE || sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY) {
+		return ErrDuplicate
+	}
+	return err
+}
+
+func sqliteError(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// escapeGlob escapes the GLOB wildcards in s.
+func escapeGlob(s string) string {
+	return strings.NewReplacer("*", "[*]", "?", "[?]", "[", "[[]").Replace(s)
+}
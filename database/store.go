@@ -0,0 +1,173 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rtsncs/remitly-swift-api/models"
+)
+
+var (
+	ErrNotFound  = pgx.ErrNoRows
+	ErrDuplicate = errors.New("already exists")
+	// ErrBackdated is returned when a record would be retired, or replaced
+	// by one valid from an earlier date, before it became valid.
+	ErrBackdated = errors.New("valid from date is before that of the current record")
+	// ErrTimeout and ErrUnavailable wrap the errors of operations that ran
+	// out of time or couldn't reach the database.
+	ErrTimeout     = errors.New("database operation timed out")
+	ErrUnavailable = errors.New("database unavailable")
+	// ErrVersionMismatch is returned by conditional writes when the record
+	// was changed since it was read.
+	ErrVersionMismatch = errors.New("version mismatch")
+)
+
+// Filter narrows down listings. Nil fields don't filter.
+type Filter struct {
+	IsTest           *bool
+	IsPassive        *bool
+	IsReverseBilling *bool
+	// AsOf lists the codes valid on that date instead of the current ones
+	// when it is not zero.
+	AsOf time.Time
+}
+
+// Store is the storage backend used by the handlers and the loader.
+// Every implementation reports missing records with ErrNotFound and
+// duplicate inserts with ErrDuplicate.
+//
+// Codes are never removed. Retired codes get a ValidTo date and are left out
+// of everything but the AsOf lookups; only one current record per code may
+// exist. Each change to a code, whether a new record or a retired one,
+// increments its version and is recorded in the change feed in the same
+// transaction.
+type Store interface {
+	InsertCode(c context.Context, code models.SwiftCode) error
+	GetByCode(c context.Context, code string) (models.SwiftCode, error)
+	// GetByCodeAsOf returns the record of the code valid on asOf, or the
+	// current one when asOf is zero.
+	GetByCodeAsOf(c context.Context, code string, asOf time.Time) (models.SwiftCode, error)
+	// GetByCodes returns the current records of the given codes that are
+	// stored, ordered by code.
+	GetByCodes(c context.Context, codes []string) ([]models.SwiftCode, error)
+	GetBranches(c context.Context, headquaterCode string) ([]models.SwiftCode, error)
+	GetBranchesAsOf(c context.Context, headquaterCode string, asOf time.Time) ([]models.SwiftCode, error)
+	// GetBranchesOf returns the current branches of all the given
+	// headquarters in one lookup, ordered by code.
+	GetBranchesOf(c context.Context, headquarterCodes []string) ([]models.SwiftCode, error)
+	// GetCountryName returns the name of a country with current codes.
+	GetCountryName(c context.Context, countryCode string) (string, error)
+	// GetCountryNames returns the names of the given countries that have
+	// current codes, by ISO2 code.
+	GetCountryNames(c context.Context, countryCodes []string) (map[string]string, error)
+	GetByCountryCode(c context.Context, countryCode string, filter Filter) ([]models.SwiftCode, error)
+	// GetByCodePrefix returns up to limit codes starting with prefix and
+	// matching filter, in alphabetical order.
+	GetByCodePrefix(c context.Context, prefix string, limit int, filter Filter) ([]models.SwiftCode, error)
+	// GetByBankNamePrefix returns up to limit codes whose bank name starts
+	// with prefix, ignoring case, and matching filter, ordered by bank name.
+	GetByBankNamePrefix(c context.Context, prefix string, limit int, filter Filter) ([]models.SwiftCode, error)
+	// DeleteByCode retires the current record of the code as of today.
+	DeleteByCode(c context.Context, code string) (int64, error)
+	// CloseByCode retires the current record of the code as of validTo. It
+	// returns ErrBackdated when the record became valid after that date.
+	CloseByCode(c context.Context, code string, validTo time.Time) (int64, error)
+	// CloseByCodeAtVersion retires the current record of the code as of
+	// validTo if it is still at version, and returns ErrVersionMismatch
+	// otherwise.
+	CloseByCodeAtVersion(c context.Context, code string, version int, validTo time.Time) error
+	// ReplaceCode retires the current record of the code if it is still at
+	// version, as of the ValidFrom date of code or today when it is empty,
+	// and stores code in its place, all at once. It returns ErrNotFound when
+	// there is no current record, ErrVersionMismatch when it changed and
+	// ErrBackdated when it became valid after that date.
+	ReplaceCode(c context.Context, code models.SwiftCode, version int) error
+	// GetChanges returns up to limit changes made after the change with the
+	// ID since, in the order they were made.
+	GetChanges(c context.Context, since int64, limit int) ([]models.Change, error)
+	// LastModified returns when the records of the codes in countryCode
+	// starting with codePrefix last changed, retired records included.
+	LastModified(c context.Context, countryCode, codePrefix string) (time.Time, error)
+	// ForEachCode calls fn for every stored code, or only those in countryCode
+	// when it is not empty, without loading the whole table into memory.
+	ForEachCode(c context.Context, countryCode string, fn func(models.SwiftCode) error) error
+	// InsertNationalID maps a national bank identifier to a code. The bank
+	// codes of IBANs are stored as identifiers too, under the scheme
+	// returned by models.BankCodeScheme.
+	InsertNationalID(c context.Context, id models.NationalID) error
+	// GetByNationalID returns the stored codes mapped to any of the national
+	// bank identifiers of the scheme, such as UK sort codes.
+	GetByNationalID(c context.Context, scheme string, ids ...string) ([]models.SwiftCode, error)
+	// InsertIdempotencyRecord stores the record of a request that is being
+	// handled, after dropping the records created before expiredBefore.
+	// ErrDuplicate is returned when the key already has a record.
+	InsertIdempotencyRecord(c context.Context, record models.IdempotencyRecord, expiredBefore time.Time) error
+	GetIdempotencyRecord(c context.Context, key string) (models.IdempotencyRecord, error)
+	// CompleteIdempotencyRecord stores the response to the request of a
+	// record.
+	CompleteIdempotencyRecord(c context.Context, record models.IdempotencyRecord) error
+	DeleteIdempotencyRecord(c context.Context, key string) error
+	// Ping checks that the storage can be reached.
+	Ping(c context.Context) error
+	Close()
+}
+
+// dateArg converts a validity date to a query argument, nil when zero.
+func dateArg(t time.Time) any {
+	if t.IsZero() {
+		return nil
+	}
+	return t.Format(models.DateLayout)
+}
+
+// nullableDate converts an empty date to nil.
+func nullableDate(date string) any {
+	if date == "" {
+		return nil
+	}
+	return date
+}
+
+// branchPrefixes returns the 8 character prefixes shared by the given
+// headquarters and their branches.
+func branchPrefixes(headquarterCodes []string) []string {
+	prefixes := make([]string, 0, len(headquarterCodes))
+	for _, code := range headquarterCodes {
+		if len(code) >= 8 {
+			prefixes = append(prefixes, code[:8])
+		}
+	}
+	return prefixes
+}
+
+// changeRecord returns the record of a created or updated code in the
+// change feed, with the flags derived from its location code.
+func changeRecord(code models.SwiftCode) *models.SwiftCode {
+	setLocationFlags(&code)
+	code.Version, code.CreatedAt, code.UpdatedAt = 0, time.Time{}, time.Time{}
+	return &code
+}
+
+// replacedUntil returns the date the record replaced by code is retired on.
+func replacedUntil(code models.SwiftCode) (time.Time, error) {
+	if code.ValidFrom == "" {
+		return today(), nil
+	}
+	return time.Parse(models.DateLayout, code.ValidFrom)
+}
+
+// checkReplaced checks that a record valid from validFrom can be retired as
+// of validTo without ending before it started.
+func checkReplaced(validFrom string, validTo time.Time) error {
+	if validFrom != "" && validTo.Format(models.DateLayout) < validFrom {
+		return ErrBackdated
+	}
+	return nil
+}
+
+func today() time.Time {
+	t, _ := time.Parse(models.DateLayout, models.Today())
+	return t
+}
@@ -0,0 +1,179 @@
+package differ
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+
+	"github.com/rtsncs/remitly-swift-api/database"
+	"github.com/rtsncs/remitly-swift-api/loader"
+	"github.com/rtsncs/remitly-swift-api/models"
+)
+
+type Format string
+
+const (
+	Text Format = "text"
+	JSON Format = "json"
+)
+
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(s); f {
+	case Text, JSON:
+		return f, nil
+	}
+	return "", fmt.Errorf("unsupported format %q", s)
+}
+
+// FieldChange is a field whose value differs between the old and the new
+// version of a code.
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+type Modification struct {
+	SwiftCode string        `json:"swiftCode"`
+	Changes   []FieldChange `json:"changes"`
+}
+
+// Result lists the codes added, removed and modified between two versions of
+// the directory, ordered by SWIFT code.
+type Result struct {
+	Added    []models.SwiftCode `json:"added"`
+	Removed  []models.SwiftCode `json:"removed"`
+	Modified []Modification     `json:"modified"`
+}
+
+// DiffFiles compares two files of codes in any format the loader accepts.
+func DiffFiles(oldPath, newPath string) (Result, error) {
+	oldCodes, err := loader.ReadFile(oldPath)
+	if err != nil {
+		return Result{}, err
+	}
+	newCodes, err := loader.ReadFile(newPath)
+	if err != nil {
+		return Result{}, err
+	}
+	return Compare(oldCodes, newCodes), nil
+}
+
+func DiffFile(path string) (Result, error) {
+	c := context.Background()
+	db, err := database.Connect(c)
+	if err != nil {
+		return Result{}, err
+	}
+	defer db.Close()
+	return DiffFileWithDatabase(path, db)
+}
+
+// DiffFileWithDatabase compares the current codes in the database with a
+// file, showing what loading the file would change.
+func DiffFileWithDatabase(path string, db database.Store) (Result, error) {
+	newCodes, err := loader.ReadFile(path)
+	if err != nil {
+		return Result{}, err
+	}
+	var oldCodes []models.SwiftCode
+	err = db.ForEachCode(context.Background(), "", func(code models.SwiftCode) error {
+		oldCodes = append(oldCodes, code)
+		return nil
+	})
+	if err != nil {
+		return Result{}, err
+	}
+	return Compare(oldCodes, newCodes), nil
+}
+
+// Compare lists the differences between two sets of codes. Only the details
+// kept in the directory are compared; validity dates are ignored.
+func Compare(oldCodes, newCodes []models.SwiftCode) Result {
+	result := Result{Added: []models.SwiftCode{}, Removed: []models.SwiftCode{}, Modified: []Modification{}}
+
+	old := make(map[string]models.SwiftCode, len(oldCodes))
+	for _, code := range oldCodes {
+		old[code.SwiftCode] = code
+	}
+	seen := make(map[string]bool, len(newCodes))
+	for _, code := range newCodes {
+		seen[code.SwiftCode] = true
+		stored, ok := old[code.SwiftCode]
+		if !ok {
+			result.Added = append(result.Added, code)
+		} else if changes := compareFields(stored, code); len(changes) > 0 {
+			result.Modified = append(result.Modified, Modification{code.SwiftCode, changes})
+		}
+	}
+	for _, code := range oldCodes {
+		if !seen[code.SwiftCode] {
+			result.Removed = append(result.Removed, code)
+		}
+	}
+
+	byCode := func(a, b models.SwiftCode) int { return cmp.Compare(a.SwiftCode, b.SwiftCode) }
+	slices.SortFunc(result.Added, byCode)
+	slices.SortFunc(result.Removed, byCode)
+	slices.SortFunc(result.Modified, func(a, b Modification) int { return cmp.Compare(a.SwiftCode, b.SwiftCode) })
+	return result
+}
+
+func compareFields(a, b models.SwiftCode) []FieldChange {
+	fields := []struct {
+		name     string
+		old, new string
+	}{
+		{"bankName", a.BankName, b.BankName},
+		{"address", a.Address, b.Address},
+		{"countryISO2", a.CountryISO2, b.CountryISO2},
+		{"countryName", a.CountryName, b.CountryName},
+		{"isHeadquarter", strconv.FormatBool(a.IsHeadquarter), strconv.FormatBool(b.IsHeadquarter)},
+	}
+
+	var changes []FieldChange
+	for _, field := range fields {
+		if field.old != field.new {
+			changes = append(changes, FieldChange{field.name, field.old, field.new})
+		}
+	}
+	return changes
+}
+
+func (r Result) Write(w io.Writer, format Format) error {
+	if format == JSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(r)
+	}
+	return r.writeText(w)
+}
+
+func (r Result) writeText(w io.Writer) error {
+	for _, code := range r.Added {
+		if _, err := fmt.Fprintf(w, "+ %s %s\n", code.SwiftCode, code.BankName); err != nil {
+			return err
+		}
+	}
+	for _, code := range r.Removed {
+		if _, err := fmt.Fprintf(w, "- %s %s\n", code.SwiftCode, code.BankName); err != nil {
+			return err
+		}
+	}
+	for _, m := range r.Modified {
+		if _, err := fmt.Fprintf(w, "~ %s\n", m.SwiftCode); err != nil {
+			return err
+		}
+		for _, change := range m.Changes {
+			if _, err := fmt.Fprintf(w, "    %s: %q -> %q\n", change.Field, change.Old, change.New); err != nil {
+				return err
+			}
+		}
+	}
+	_, err := fmt.Fprintf(w, "Added: %d; Removed: %d; Modified: %d\n", len(r.Added), len(r.Removed), len(r.Modified))
+	return err
+}
@@ -0,0 +1,80 @@
+package differ
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rtsncs/remitly-swift-api/database"
+	"github.com/rtsncs/remitly-swift-api/models"
+	"github.com/stretchr/testify/assert"
+)
+
+const header = "COUNTRY ISO2 CODE,SWIFT CODE,CODE TYPE,NAME,ADDRESS,TOWN NAME,COUNTRY NAME,TIME ZONE\n"
+
+func writeFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func TestDiffFiles(t *testing.T) {
+	oldPath := writeFile(t, "old.csv", header+
+		"PL,BANKPLPWXXX,BIC11,Bank,Old Street 1,,Poland,\n"+
+		"PL,BANKPLPWKRK,BIC11,Bank Krakow,Main Square,,Poland,\n"+
+		"US,BANKUS33XXX,BIC11,Bank,Wall St,,United States,\n")
+	newPath := writeFile(t, "new.csv", header+
+		"US,BANKUS33XXX,BIC11,Bank,Wall St,,UNITED STATES,\n"+
+		"pl,BANKPLPWXXX,BIC11,Bank S.A.,New Street 2,,Poland,\n"+
+		"PL,BANKPLPWWAW,BIC11,Bank Warsaw,Marszalkowska,,Poland,\n"+
+		"PL,INVALID,BIC11,Bank,,,Poland,\n")
+
+	result, err := DiffFiles(oldPath, newPath)
+	assert.NoError(t, err)
+
+	assert.Len(t, result.Added, 1)
+	assert.Equal(t, "BANKPLPWWAW", result.Added[0].SwiftCode)
+	assert.Len(t, result.Removed, 1)
+	assert.Equal(t, "BANKPLPWKRK", result.Removed[0].SwiftCode)
+	assert.Equal(t, []Modification{{
+		SwiftCode: "BANKPLPWXXX",
+		Changes: []FieldChange{
+			{"bankName", "Bank", "Bank S.A."},
+			{"address", "Old Street 1", "New Street 2"},
+		},
+	}}, result.Modified)
+
+	var text bytes.Buffer
+	assert.NoError(t, result.Write(&text, Text))
+	assert.Equal(t, "+ BANKPLPWWAW Bank Warsaw\n"+
+		"- BANKPLPWKRK Bank Krakow\n"+
+		"~ BANKPLPWXXX\n"+
+		"    bankName: \"Bank\" -> \"Bank S.A.\"\n"+
+		"    address: \"Old Street 1\" -> \"New Street 2\"\n"+
+		"Added: 1; Removed: 1; Modified: 1\n", text.String())
+
+	var out bytes.Buffer
+	assert.NoError(t, result.Write(&out, JSON))
+	var decoded Result
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &decoded))
+	assert.Equal(t, result, decoded)
+}
+
+func TestDiffFileWithDatabase(t *testing.T) {
+	c := context.Background()
+	db := database.NewMemory()
+	assert.NoError(t, db.InsertCode(c, models.SwiftCode{SwiftCode: "BANKUS33XXX", BankName: "Bank", Address: "Wall St", CountryISO2: "US", CountryName: "UNITED STATES", IsHeadquarter: true, ValidFrom: "2024-01-01"}))
+	assert.NoError(t, db.InsertCode(c, models.SwiftCode{SwiftCode: "GONEUS33XXX", BankName: "Gone", CountryISO2: "US", CountryName: "UNITED STATES", IsHeadquarter: true, ValidTo: "2024-01-01"}))
+
+	path := writeFile(t, "new.csv", header+"US,BANKUS33XXX,BIC11,Bank,Wall St,,United States,\n")
+	result, err := DiffFileWithDatabase(path, db)
+	assert.NoError(t, err)
+	assert.Equal(t, Result{Added: []models.SwiftCode{}, Removed: []models.SwiftCode{}, Modified: []Modification{}}, result)
+
+	var text bytes.Buffer
+	assert.NoError(t, result.Write(&text, Text))
+	assert.Equal(t, "Added: 0; Removed: 0; Modified: 0\n", text.String())
+}
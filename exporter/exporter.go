@@ -0,0 +1,244 @@
+package exporter
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/rtsncs/remitly-swift-api/database"
+	"github.com/rtsncs/remitly-swift-api/loader"
+	"github.com/rtsncs/remitly-swift-api/models"
+	"github.com/xuri/excelize/v2"
+)
+
+type Format string
+
+const (
+	XLSX   Format = "xlsx"
+	CSV    Format = "csv"
+	JSON   Format = "json"
+	NDJSON Format = "ndjson"
+)
+
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(s); f {
+	case XLSX, CSV, JSON, NDJSON:
+		return f, nil
+	}
+	return "", fmt.Errorf("unsupported format %q", s)
+}
+
+func (f Format) ContentType() string {
+	switch f {
+	case XLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	case CSV:
+		return "text/csv; charset=UTF-8"
+	case JSON:
+		return "application/json; charset=UTF-8"
+	default:
+		return "application/x-ndjson; charset=UTF-8"
+	}
+}
+
+// Row converts a code to a row in the SWIFT data spreadsheet layout, so that
+// exported files can be loaded back with the loader. TOWN NAME and TIME ZONE
+// aren't stored and are left empty.
+func Row(code models.SwiftCode) []string {
+	return []string{code.CountryISO2, code.SwiftCode, codeType(code.SwiftCode), code.BankName, code.Address, "", code.CountryName, ""}
+}
+
+// codeType is the CODE TYPE of a code, named after its length.
+func codeType(swiftCode string) string {
+	if len(swiftCode) == 8 {
+		return "BIC8"
+	}
+	return "BIC11"
+}
+
+type writer interface {
+	Write(code models.SwiftCode) error
+	Close() error
+}
+
+func ExportToFile(path string, format Format, countryCode string) error {
+	c := context.Background()
+	db, err := database.Connect(c)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	return ExportToFileWithDatabase(path, format, countryCode, db)
+}
+
+func ExportToFileWithDatabase(path string, format Format, countryCode string, db database.Store) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("Failed to create file: %w", err)
+	}
+	defer f.Close()
+
+	count, err := Export(context.Background(), db, f, format, countryCode)
+	if err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("Failed to write file: %w", err)
+	}
+
+	log.Printf("Exported %d codes to %s\n", count, path)
+	return nil
+}
+
+// Export writes the stored codes to w one at a time and returns how many
+// were written.
+func Export(c context.Context, db database.Store, w io.Writer, format Format, countryCode string) (int, error) {
+	ew, err := newWriter(w, format)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	err = db.ForEachCode(c, countryCode, func(code models.SwiftCode) error {
+		count++
+		return ew.Write(code)
+	})
+	if err != nil {
+		return count, err
+	}
+
+	return count, ew.Close()
+}
+
+func newWriter(w io.Writer, format Format) (writer, error) {
+	switch format {
+	case XLSX:
+		return newXLSXWriter(w)
+	case CSV:
+		return newCSVWriter(w)
+	case JSON:
+		return newJSONWriter(w)
+	case NDJSON:
+		return &ndjsonWriter{bufio.NewWriter(w)}, nil
+	}
+	return nil, fmt.Errorf("unsupported format %q", format)
+}
+
+type xlsxWriter struct {
+	w   io.Writer
+	f   *excelize.File
+	sw  *excelize.StreamWriter
+	row int
+}
+
+func newXLSXWriter(w io.Writer) (*xlsxWriter, error) {
+	f := excelize.NewFile()
+	sw, err := f.NewStreamWriter(f.GetSheetName(0))
+	if err != nil {
+		return nil, err
+	}
+	xw := &xlsxWriter{w: w, f: f, sw: sw, row: 1}
+	if err := xw.setRow(loader.Columns); err != nil {
+		return nil, err
+	}
+	return xw, nil
+}
+
+func (xw *xlsxWriter) setRow(row []string) error {
+	values := make([]any, len(row))
+	for i, v := range row {
+		values[i] = v
+	}
+	cell, err := excelize.CoordinatesToCellName(1, xw.row)
+	if err != nil {
+		return err
+	}
+	xw.row++
+	return xw.sw.SetRow(cell, values)
+}
+
+func (xw *xlsxWriter) Write(code models.SwiftCode) error {
+	return xw.setRow(Row(code))
+}
+
+func (xw *xlsxWriter) Close() error {
+	defer xw.f.Close()
+	if err := xw.sw.Flush(); err != nil {
+		return err
+	}
+	return xw.f.Write(xw.w)
+}
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func newCSVWriter(w io.Writer) (*csvWriter, error) {
+	cw := &csvWriter{csv.NewWriter(w)}
+	return cw, cw.w.Write(loader.Columns)
+}
+
+func (cw *csvWriter) Write(code models.SwiftCode) error {
+	return cw.w.Write(Row(code))
+}
+
+func (cw *csvWriter) Close() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+type jsonWriter struct {
+	w     *bufio.Writer
+	first bool
+}
+
+func newJSONWriter(w io.Writer) (*jsonWriter, error) {
+	jw := &jsonWriter{bufio.NewWriter(w), true}
+	_, err := jw.w.WriteString("[")
+	return jw, err
+}
+
+func (jw *jsonWriter) Write(code models.SwiftCode) error {
+	if !jw.first {
+		if err := jw.w.WriteByte(','); err != nil {
+			return err
+		}
+	}
+	jw.first = false
+	b, err := json.Marshal(code)
+	if err != nil {
+		return err
+	}
+	_, err = jw.w.Write(b)
+	return err
+}
+
+func (jw *jsonWriter) Close() error {
+	if _, err := jw.w.WriteString("]\n"); err != nil {
+		return err
+	}
+	return jw.w.Flush()
+}
+
+type ndjsonWriter struct {
+	w *bufio.Writer
+}
+
+func (nw *ndjsonWriter) Write(code models.SwiftCode) error {
+	b, err := json.Marshal(code)
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+	_, err = nw.w.Write(b)
+	return err
+}
+
+func (nw *ndjsonWriter) Close() error {
+	return nw.w.Flush()
+}
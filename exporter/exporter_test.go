@@ -0,0 +1,93 @@
+package exporter
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rtsncs/remitly-swift-api/database"
+	"github.com/rtsncs/remitly-swift-api/loader"
+	"github.com/rtsncs/remitly-swift-api/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/xuri/excelize/v2"
+)
+
+func collect(t *testing.T, db database.Store) []models.SwiftCode {
+	var codes []models.SwiftCode
+	err := db.ForEachCode(context.Background(), "", func(code models.SwiftCode) error {
+		codes = append(codes, code)
+		return nil
+	})
+	assert.NoError(t, err)
+	return codes
+}
+
+func TestExportRoundTrip(t *testing.T) {
+	c := context.Background()
+	source := database.NewMemory()
+	codes := []models.SwiftCode{
+		{SwiftCode: "AAISALTRXXX", BankName: "UNITED BANK OF ALBANIA SH.A", Address: "HYRJA 3 RR. DRITAN HOXHA ND. 11 TIRANA, TIRANA, 1023", CountryISO2: "AL", CountryName: "ALBANIA", IsHeadquarter: true},
+		{SwiftCode: "BANKUS33XXX", BankName: "Bank \"Quoted\", Inc.", Address: "", CountryISO2: "US", CountryName: "UNITED STATES", IsHeadquarter: true},
+		{SwiftCode: "BANKUS33NYC", BankName: "Bank NYC", Address: "1 Line\nSecond Line", CountryISO2: "US", CountryName: "UNITED STATES", IsHeadquarter: false},
+	}
+	for _, code := range codes {
+		assert.NoError(t, source.InsertCode(c, code))
+	}
+	// Loaded codes are valid from the day they're loaded on.
+	loaded := make([]models.SwiftCode, len(codes))
+	for i, code := range codes {
+		code.ValidFrom = models.Today()
+		loaded[i] = code
+	}
+
+	for _, format := range []Format{XLSX, CSV, JSON, NDJSON} {
+		t.Run(string(format), func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "export."+string(format))
+			assert.NoError(t, ExportToFileWithDatabase(path, format, "", source))
+
+			target := database.NewMemory()
+			assert.NoError(t, loader.LoadFromFileWithDatabase(path, target))
+			assert.Equal(t, loaded, collect(t, target))
+		})
+	}
+}
+
+func TestExportLayout(t *testing.T) {
+	c := context.Background()
+	source := database.NewMemory()
+	code := models.SwiftCode{SwiftCode: "BANKDEFFXXX", BankName: "Bank DE", Address: "1 Main St", CountryISO2: "DE", CountryName: "GERMANY", IsHeadquarter: true}
+	assert.NoError(t, source.InsertCode(c, code))
+
+	path := filepath.Join(t.TempDir(), "export.xlsx")
+	assert.NoError(t, ExportToFileWithDatabase(path, XLSX, "", source))
+
+	f, err := excelize.OpenFile(path)
+	assert.NoError(t, err)
+	defer f.Close()
+	rows, err := f.GetRows(f.GetSheetName(0))
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{
+		loader.Columns,
+		{"DE", "BANKDEFFXXX", "BIC11", "Bank DE", "1 Main St", "", "GERMANY"},
+	}, rows)
+
+	target := database.NewMemory()
+	assert.NoError(t, loader.LoadFromFileWithDatabase(path, target))
+	code.ValidFrom = models.Today()
+	assert.Equal(t, []models.SwiftCode{code}, collect(t, target))
+}
+
+func TestExportCountry(t *testing.T) {
+	c := context.Background()
+	db := database.NewMemory()
+	assert.NoError(t, db.InsertCode(c, models.SwiftCode{SwiftCode: "BANKUS33XXX", BankName: "Bank", CountryISO2: "US", CountryName: "UNITED STATES", IsHeadquarter: true}))
+	assert.NoError(t, db.InsertCode(c, models.SwiftCode{SwiftCode: "BANKPLPWXXX", BankName: "Bank", CountryISO2: "PL", CountryName: "POLAND", IsHeadquarter: true}))
+
+	path := filepath.Join(t.TempDir(), "export.csv")
+	assert.NoError(t, ExportToFileWithDatabase(path, CSV, "PL", db))
+
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "COUNTRY ISO2 CODE,SWIFT CODE,CODE TYPE,NAME,ADDRESS,TOWN NAME,COUNTRY NAME,TIME ZONE\nPL,BANKPLPWXXX,BIC11,Bank,,,POLAND,\n", string(content))
+}
@@ -0,0 +1,132 @@
+// Package gql serves the SWIFT code directory over GraphQL, so clients can
+// fetch a code, its branches, its bank and its country in one request.
+package gql
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"log"
+	"strings"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/rtsncs/remitly-swift-api/database"
+)
+
+//go:embed schema.graphql
+var schemaSDL string
+
+// maxDepth bounds the nesting of queries, which could otherwise go back and
+// forth between headquarters and branches forever.
+const maxDepth = 10
+
+// Schema executes GraphQL queries over a store.
+type Schema struct {
+	db     database.Store
+	schema *graphql.Schema
+}
+
+func New(db database.Store) *Schema {
+	return &Schema{
+		db:     db,
+		schema: graphql.MustParseSchema(schemaSDL, &query{db: db}, graphql.MaxDepth(maxDepth)),
+	}
+}
+
+// Exec runs a query with loaders of its own, which batch the lookups of
+// nested fields and cache them for the duration of the query.
+func (s *Schema) Exec(c context.Context, queryString, operationName string, variables map[string]any) *graphql.Response {
+	response := s.schema.Exec(withLoaders(c, newLoaders(s.db)), queryString, operationName, variables)
+	for _, err := range response.Errors {
+		if err.ResolverError != nil {
+			err.Message, err.Extensions = resolverError(err.ResolverError)
+		}
+	}
+	return response
+}
+
+// inputError is returned by resolvers for invalid arguments.
+type inputError struct{ message string }
+
+func (e inputError) Error() string { return e.message }
+
+// resolverError maps the errors of resolvers to the message and code
+// reported to clients. Unexpected errors are logged and hidden from them.
+func resolverError(err error) (string, map[string]any) {
+	var input inputError
+	switch {
+	case errors.As(err, &input):
+		return input.message, map[string]any{"code": "BAD_USER_INPUT"}
+	case errors.Is(err, database.ErrTimeout):
+		return "database timeout", map[string]any{"code": "DATABASE_TIMEOUT"}
+	case errors.Is(err, database.ErrUnavailable):
+		return "database unavailable", map[string]any{"code": "DATABASE_UNAVAILABLE"}
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return err.Error(), map[string]any{"code": "CANCELED"}
+	}
+	log.Printf("GraphQL resolver failed: %v\n", err)
+	return "internal error", map[string]any{"code": "INTERNAL"}
+}
+
+// notFound turns ErrNotFound into a nil error, for fields that are null
+// when there's nothing to resolve.
+func notFound(err error) error {
+	if errors.Is(err, database.ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
+type query struct {
+	db database.Store
+}
+
+func (q *query) SwiftCode(c context.Context, args struct{ Code string }) (*swiftCode, error) {
+	code, found, err := loadersFrom(c).codes.load(c, strings.ToUpper(args.Code))
+	if err != nil || !found {
+		return nil, err
+	}
+	return &swiftCode{db: q.db, code: code}, nil
+}
+
+func (q *query) SwiftCodes(c context.Context, args struct{ Codes []string }) ([]*swiftCode, error) {
+	if len(args.Codes) > maxBatchSize {
+		return nil, inputError{"at most 1000 codes can be looked up at once"}
+	}
+	keys := make([]string, len(args.Codes))
+	for i, code := range args.Codes {
+		keys[i] = strings.ToUpper(code)
+	}
+	codes, err := q.db.GetByCodes(c, keys)
+	if err != nil {
+		return nil, err
+	}
+	return swiftCodes(c, q.db, codes), nil
+}
+
+func (q *query) Country(c context.Context, args struct{ ISO2 string }) (*country, error) {
+	iso2 := strings.ToUpper(args.ISO2)
+	name, found, err := loadersFrom(c).countryNames.load(c, iso2)
+	if err != nil || !found {
+		return nil, err
+	}
+	return loadersFrom(c).country(q.db, iso2, name), nil
+}
+
+func (q *query) Bank(c context.Context, args struct {
+	Code        string
+	CountryISO2 string
+}) (*bank, error) {
+	prefix := strings.ToUpper(args.Code + args.CountryISO2)
+	if len(prefix) != 6 {
+		return nil, inputError{"code must have 4 letters and countryISO2 2"}
+	}
+	codes, err := q.db.GetByCodePrefix(c, prefix, maxBatchSize, database.Filter{})
+	if err != nil {
+		return nil, err
+	}
+	if len(codes) == 0 {
+		return nil, nil
+	}
+	return newBank(c, q.db, prefix, codes), nil
+}
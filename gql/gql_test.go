@@ -0,0 +1,250 @@
+package gql
+
+import (
+	"context"
+	"encoding/json"
+	"sync/atomic"
+	"testing"
+
+	"github.com/rtsncs/remitly-swift-api/database"
+	"github.com/rtsncs/remitly-swift-api/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingStore counts the lookups of nested fields.
+type countingStore struct {
+	database.Store
+	byCodes, branchesOf, branches, countryNames atomic.Int32
+	byCountry, byPrefix                         atomic.Int32
+}
+
+func (s *countingStore) GetByCodes(c context.Context, codes []string) ([]models.SwiftCode, error) {
+	s.byCodes.Add(1)
+	return s.Store.GetByCodes(c, codes)
+}
+
+func (s *countingStore) GetBranchesOf(c context.Context, headquarterCodes []string) ([]models.SwiftCode, error) {
+	s.branchesOf.Add(1)
+	return s.Store.GetBranchesOf(c, headquarterCodes)
+}
+
+func (s *countingStore) GetBranches(c context.Context, headquarterCode string) ([]models.SwiftCode, error) {
+	s.branches.Add(1)
+	return s.Store.GetBranches(c, headquarterCode)
+}
+
+func (s *countingStore) GetCountryNames(c context.Context, countryCodes []string) (map[string]string, error) {
+	s.countryNames.Add(1)
+	return s.Store.GetCountryNames(c, countryCodes)
+}
+
+func (s *countingStore) GetByCountryCode(c context.Context, countryCode string, filter database.Filter) ([]models.SwiftCode, error) {
+	s.byCountry.Add(1)
+	return s.Store.GetByCountryCode(c, countryCode, filter)
+}
+
+func (s *countingStore) GetByCodePrefix(c context.Context, prefix string, limit int, filter database.Filter) ([]models.SwiftCode, error) {
+	s.byPrefix.Add(1)
+	return s.Store.GetByCodePrefix(c, prefix, limit, filter)
+}
+
+func newStore(t *testing.T) *countingStore {
+	db := database.NewMemory()
+	for _, code := range []string{
+		"AAAAPLPWXXX", "AAAAPLPW001", "AAAAPLPW002",
+		"BBBBPLPWXXX", "BBBBPLPW001",
+		"CCCCPLPWXXX",
+		"DDDDPLPW001",
+		"EEEEDEFFXXX",
+	} {
+		require.NoError(t, db.InsertCode(context.Background(), models.SwiftCode{
+			SwiftCode:     code,
+			BankName:      code[:4] + " " + code[8:],
+			CountryISO2:   code[4:6],
+			CountryName:   map[string]string{"PL": "POLAND", "DE": "GERMANY"}[code[4:6]],
+			IsHeadquarter: code[8:] == "XXX",
+		}))
+	}
+	return &countingStore{Store: db}
+}
+
+func exec(t *testing.T, s *Schema, query string, variables map[string]any) map[string]any {
+	t.Helper()
+	response := s.Exec(context.Background(), query, "", variables)
+	require.Empty(t, response.Errors)
+	var data map[string]any
+	require.NoError(t, json.Unmarshal(response.Data, &data))
+	return data
+}
+
+func TestSwiftCode(t *testing.T) {
+	s := New(newStore(t))
+
+	data := exec(t, s, `query($code: String!) {
+		swiftCode(code: $code) {
+			swiftCode bankName countryName isHeadquarter
+			country { iso2 name }
+			bank { code name }
+			headquarter { swiftCode }
+			branches { swiftCode countryName headquarter { swiftCode } }
+		}
+		missing: swiftCode(code: "NONEPLPWXXX") { swiftCode }
+	}`, map[string]any{"code": "aaaaplpwxxx"})
+	assert.Equal(t, map[string]any{
+		"swiftCode":     "AAAAPLPWXXX",
+		"bankName":      "AAAA XXX",
+		"countryName":   "POLAND",
+		"isHeadquarter": true,
+		"country":       map[string]any{"iso2": "PL", "name": "POLAND"},
+		"bank":          map[string]any{"code": "AAAA", "name": "AAAA XXX"},
+		"headquarter":   nil,
+		"branches": []any{
+			map[string]any{"swiftCode": "AAAAPLPW001", "countryName": "POLAND", "headquarter": map[string]any{"swiftCode": "AAAAPLPWXXX"}},
+			map[string]any{"swiftCode": "AAAAPLPW002", "countryName": "POLAND", "headquarter": map[string]any{"swiftCode": "AAAAPLPWXXX"}},
+		},
+	}, data["swiftCode"])
+	assert.Nil(t, data["missing"])
+
+	// Branches name their bank after the headquarter, or themselves when it
+	// isn't stored.
+	data = exec(t, s, `{
+		swiftCodes(codes: ["BBBBPLPW001", "DDDDPLPW001", "NONEPLPWXXX"]) {
+			swiftCode branches { swiftCode } bank { name country { name } }
+		}
+	}`, nil)
+	assert.Equal(t, []any{
+		map[string]any{"swiftCode": "BBBBPLPW001", "branches": []any{}, "bank": map[string]any{"name": "BBBB XXX", "country": map[string]any{"name": "POLAND"}}},
+		map[string]any{"swiftCode": "DDDDPLPW001", "branches": []any{}, "bank": map[string]any{"name": "DDDD 001", "country": map[string]any{"name": "POLAND"}}},
+	}, data["swiftCodes"])
+}
+
+func TestCountry(t *testing.T) {
+	db := newStore(t)
+	s := New(db)
+
+	data := exec(t, s, `{
+		country(iso2: "pl") {
+			iso2 name codeCount headquarterCount
+			swiftCodes(isHeadquarter: true) {
+				swiftCode
+				branches { swiftCode headquarter { swiftCode bankName } }
+			}
+			banks { code name headquarters { swiftCode } }
+		}
+		missing: country(iso2: "XX") { name }
+	}`, nil)
+	country := data["country"].(map[string]any)
+	assert.Equal(t, "PL", country["iso2"])
+	assert.Equal(t, "POLAND", country["name"])
+	assert.Equal(t, float64(7), country["codeCount"])
+	assert.Equal(t, float64(3), country["headquarterCount"])
+	assert.Len(t, country["swiftCodes"], 3)
+	assert.Len(t, country["banks"], 4)
+	assert.Equal(t, map[string]any{"code": "DDDD", "name": "DDDD 001", "headquarters": []any{}}, country["banks"].([]any)[3])
+	assert.Nil(t, data["missing"])
+
+	// The branches of all the headquarters are looked up at once, and so
+	// are the headquarters of all those branches, which were already
+	// loaded.
+	assert.Equal(t, int32(1), db.branchesOf.Load())
+	assert.Equal(t, int32(0), db.branches.Load())
+	assert.Equal(t, int32(0), db.byCodes.Load())
+}
+
+func TestBatching(t *testing.T) {
+	db := newStore(t)
+	s := New(db)
+
+	exec(t, s, `{
+		swiftCodes(codes: ["AAAAPLPW001", "AAAAPLPW002", "BBBBPLPW001", "DDDDPLPW001"]) {
+			headquarter { swiftCode branches { swiftCode country { name } } }
+			bank { name }
+		}
+	}`, nil)
+	// One lookup for the listed codes and one for their headquarters,
+	// whose branches are then looked up together.
+	assert.Equal(t, int32(2), db.byCodes.Load())
+	assert.Equal(t, int32(1), db.branchesOf.Load())
+	assert.Equal(t, int32(0), db.countryNames.Load())
+}
+
+// namelessStore leaves the country names out of the records it looks up.
+type namelessStore struct {
+	*countingStore
+}
+
+func (s namelessStore) GetByCodes(c context.Context, codes []string) ([]models.SwiftCode, error) {
+	found, err := s.countingStore.GetByCodes(c, codes)
+	for i := range found {
+		found[i].CountryName = ""
+	}
+	return found, err
+}
+
+func TestBatchingCountryNames(t *testing.T) {
+	db := newStore(t)
+	s := New(namelessStore{db})
+
+	data := exec(t, s, `{
+		swiftCodes(codes: ["AAAAPLPWXXX", "BBBBPLPW001", "EEEEDEFFXXX"]) { countryName }
+	}`, nil)
+	assert.Equal(t, []any{
+		map[string]any{"countryName": "POLAND"},
+		map[string]any{"countryName": "POLAND"},
+		map[string]any{"countryName": "GERMANY"},
+	}, data["swiftCodes"])
+	// The names of all the listed countries are looked up at once.
+	assert.Equal(t, int32(1), db.countryNames.Load())
+}
+
+func TestSharedResolvers(t *testing.T) {
+	db := newStore(t)
+	s := New(db)
+
+	data := exec(t, s, `{
+		swiftCodes(codes: ["AAAAPLPWXXX", "AAAAPLPW001", "AAAAPLPW002", "BBBBPLPW001"]) {
+			country { codeCount }
+			bank { swiftCodes { swiftCode } }
+		}
+	}`, nil)
+	codes := data["swiftCodes"].([]any)
+	require.Len(t, codes, 4)
+	for _, code := range codes {
+		assert.Equal(t, float64(7), code.(map[string]any)["country"].(map[string]any)["codeCount"])
+	}
+	// Codes of the same country and bank share their resolvers, which look
+	// up their codes once.
+	assert.Equal(t, int32(1), db.byCountry.Load())
+	assert.Equal(t, int32(2), db.byPrefix.Load())
+}
+
+func TestErrors(t *testing.T) {
+	s := New(newStore(t))
+
+	response := s.Exec(context.Background(), `{ swiftCodes(codes: $codes) { swiftCode } }`, "", nil)
+	assert.NotEmpty(t, response.Errors)
+
+	codes := make([]any, maxBatchSize+1)
+	for i := range codes {
+		codes[i] = "AAAAPLPWXXX"
+	}
+	response = s.Exec(context.Background(), `query($codes: [String!]!) { swiftCodes(codes: $codes) { swiftCode } }`, "", map[string]any{"codes": codes})
+	require.Len(t, response.Errors, 1)
+	assert.Equal(t, "at most 1000 codes can be looked up at once", response.Errors[0].Message)
+	assert.Equal(t, "BAD_USER_INPUT", response.Errors[0].Extensions["code"])
+
+	response = s.Exec(context.Background(), `{ bank(code: "A", countryISO2: "PL") { name } }`, "", nil)
+	require.Len(t, response.Errors, 1)
+	assert.Equal(t, "BAD_USER_INPUT", response.Errors[0].Extensions["code"])
+
+	message, extensions := resolverError(database.ErrTimeout)
+	assert.Equal(t, "database timeout", message)
+	assert.Equal(t, "DATABASE_TIMEOUT", extensions["code"])
+	message, _ = resolverError(assert.AnError)
+	assert.Equal(t, "internal error", message)
+
+	depth := `{ swiftCode(code: "AAAAPLPWXXX") { branches { headquarter { branches { headquarter { branches { headquarter { branches { headquarter { branches { headquarter { swiftCode } } } } } } } } } } } }`
+	response = s.Exec(context.Background(), depth, "", nil)
+	assert.NotEmpty(t, response.Errors)
+}
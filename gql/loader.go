@@ -0,0 +1,206 @@
+package gql
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/rtsncs/remitly-swift-api/database"
+	"github.com/rtsncs/remitly-swift-api/models"
+)
+
+// maxBatchSize bounds the keys fetched by a single call of a loader.
+const maxBatchSize = 1000
+
+// loader batches lookups by key. Resolvers returning a list queue the keys
+// their elements will look up, and the first load fetches every queued key
+// in one call, so a field resolved on each element of a list doesn't issue
+// a query per element. Results are kept for the rest of the request.
+type loader[V any] struct {
+	fetch   func(c context.Context, keys []string) (map[string]V, error)
+	mu      sync.Mutex
+	queued  []string
+	results map[string]*result[V]
+}
+
+type result[V any] struct {
+	done  chan struct{}
+	value V
+	found bool
+	err   error
+}
+
+func newLoader[V any](fetch func(c context.Context, keys []string) (map[string]V, error)) *loader[V] {
+	return &loader[V]{fetch: fetch, results: make(map[string]*result[V])}
+}
+
+// queue queues keys to be fetched along with the next load.
+func (l *loader[V]) queue(keys ...string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.queued = append(l.queued, keys...)
+}
+
+// prime stores the value of a key that was looked up some other way, unless
+// it's already loaded.
+func (l *loader[V]) prime(key string, value V) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.results[key]; ok {
+		return
+	}
+	r := &result[V]{done: make(chan struct{}), value: value, found: true}
+	close(r.done)
+	l.results[key] = r
+}
+
+// load returns the value of key, and whether it was found.
+func (l *loader[V]) load(c context.Context, key string) (V, bool, error) {
+	l.mu.Lock()
+	r, ok := l.results[key]
+	if !ok {
+		batch := l.batch(key)
+		l.mu.Unlock()
+		l.run(c, batch)
+		r = batch[key]
+	} else {
+		l.mu.Unlock()
+	}
+
+	select {
+	case <-r.done:
+		return r.value, r.found, r.err
+	case <-c.Done():
+		var zero V
+		return zero, false, c.Err()
+	}
+}
+
+// batch takes key and up to maxBatchSize queued keys that weren't loaded
+// yet off the queue. It must be called with l.mu held.
+func (l *loader[V]) batch(key string) map[string]*result[V] {
+	batch := make(map[string]*result[V])
+	take := func(key string) {
+		r := &result[V]{done: make(chan struct{})}
+		l.results[key] = r
+		batch[key] = r
+	}
+	take(key)
+
+	var rest []string
+	for _, queued := range l.queued {
+		if _, ok := l.results[queued]; ok {
+			continue
+		}
+		if len(batch) == maxBatchSize {
+			rest = append(rest, queued)
+			continue
+		}
+		take(queued)
+	}
+	l.queued = rest
+	return batch
+}
+
+func (l *loader[V]) run(c context.Context, batch map[string]*result[V]) {
+	keys := slices.Sorted(maps.Keys(batch))
+	values, err := l.fetch(c, keys)
+	for key, r := range batch {
+		r.value, r.found = values[key]
+		r.err = err
+		close(r.done)
+	}
+}
+
+// loaders are the loaders of a single request.
+type loaders struct {
+	// codes loads the current records of codes.
+	codes *loader[models.SwiftCode]
+	// branches loads the current branches of headquarters.
+	branches *loader[[]models.SwiftCode]
+	// countryNames loads the names of countries by their ISO2 code.
+	countryNames *loader[string]
+
+	// countries and banks hold a resolver per ISO2 code and bank prefix,
+	// shared by every field resolving the same country or bank, so that
+	// their codes are looked up once rather than once per list element.
+	mu        sync.Mutex
+	countries map[string]*country
+	banks     map[string]*bank
+}
+
+func newLoaders(db database.Store) *loaders {
+	l := &loaders{countries: map[string]*country{}, banks: map[string]*bank{}}
+	l.codes = newLoader(func(c context.Context, keys []string) (map[string]models.SwiftCode, error) {
+		codes, err := db.GetByCodes(c, keys)
+		if err != nil {
+			return nil, err
+		}
+		found := make(map[string]models.SwiftCode, len(codes))
+		for _, code := range codes {
+			found[code.SwiftCode] = code
+			// Headquarters looked up together, like those of a list of
+			// branches, tend to have their branches looked up together.
+			if code.IsHeadquarter {
+				l.branches.queue(headquarterCode(code.SwiftCode))
+			}
+		}
+		return found, nil
+	})
+	l.branches = newLoader(func(c context.Context, keys []string) (map[string][]models.SwiftCode, error) {
+		branches, err := db.GetBranchesOf(c, keys)
+		if err != nil {
+			return nil, err
+		}
+		found := make(map[string][]models.SwiftCode, len(keys))
+		for _, branch := range branches {
+			hq := headquarterCode(branch.SwiftCode)
+			found[hq] = append(found[hq], branch)
+		}
+		return found, nil
+	})
+	l.countryNames = newLoader(db.GetCountryNames)
+	return l
+}
+
+// country returns the resolver of the country, creating it with name when
+// the request has none yet.
+func (l *loaders) country(db database.Store, iso2, name string) *country {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if r, ok := l.countries[iso2]; ok {
+		return r
+	}
+	r := &country{db: db, iso2: iso2, name: name}
+	l.countries[iso2] = r
+	return r
+}
+
+// bank returns the resolver of the bank with the prefix, creating it with
+// newBank when the request has none yet.
+func (l *loaders) bank(prefix string, newBank func() *bank) *bank {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if r, ok := l.banks[prefix]; ok {
+		return r
+	}
+	r := newBank()
+	l.banks[prefix] = r
+	return r
+}
+
+type loadersKey struct{}
+
+func withLoaders(c context.Context, l *loaders) context.Context {
+	return context.WithValue(c, loadersKey{}, l)
+}
+
+func loadersFrom(c context.Context) *loaders {
+	return c.Value(loadersKey{}).(*loaders)
+}
+
+// headquarterCode returns the code of the headquarter of a branch.
+func headquarterCode(code string) string {
+	return code[:min(len(code), 8)] + "XXX"
+}
@@ -0,0 +1,293 @@
+package gql
+
+import (
+	"cmp"
+	"context"
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/rtsncs/remitly-swift-api/database"
+	"github.com/rtsncs/remitly-swift-api/models"
+)
+
+type swiftCode struct {
+	db   database.Store
+	code models.SwiftCode
+}
+
+// swiftCodes wraps a list of current codes in resolvers. The codes are kept
+// for later lookups, and the branches and headquarters they may look up are
+// queued, so that the lookups of the whole list are batched.
+func swiftCodes(c context.Context, db database.Store, codes []models.SwiftCode) []*swiftCode {
+	l := loadersFrom(c)
+	resolvers := make([]*swiftCode, len(codes))
+	var headquarters, branches, countries []string
+	for i, code := range codes {
+		resolvers[i] = &swiftCode{db: db, code: code}
+		l.codes.prime(code.SwiftCode, code)
+		if code.IsHeadquarter {
+			headquarters = append(headquarters, headquarterCode(code.SwiftCode))
+		} else {
+			branches = append(branches, headquarterCode(code.SwiftCode))
+		}
+		if code.CountryName == "" {
+			countries = append(countries, code.CountryISO2)
+		}
+	}
+	l.branches.queue(headquarters...)
+	l.codes.queue(branches...)
+	l.countryNames.queue(countries...)
+	return resolvers
+}
+
+func (r *swiftCode) SwiftCode() string      { return r.code.SwiftCode }
+func (r *swiftCode) BankName() string       { return r.code.BankName }
+func (r *swiftCode) Address() string        { return r.code.Address }
+func (r *swiftCode) CountryISO2() string    { return r.code.CountryISO2 }
+func (r *swiftCode) IsHeadquarter() bool    { return r.code.IsHeadquarter }
+func (r *swiftCode) IsTest() bool           { return r.code.IsTest }
+func (r *swiftCode) IsPassive() bool        { return r.code.IsPassive }
+func (r *swiftCode) IsReverseBilling() bool { return r.code.IsReverseBilling }
+
+func (r *swiftCode) ValidFrom() *string {
+	if r.code.ValidFrom == "" {
+		return nil
+	}
+	return &r.code.ValidFrom
+}
+
+func (r *swiftCode) CountryName(c context.Context) (string, error) {
+	return countryName(c, r.code.CountryISO2, r.code.CountryName)
+}
+
+func (r *swiftCode) Country(c context.Context) (*country, error) {
+	name, err := countryName(c, r.code.CountryISO2, r.code.CountryName)
+	if err != nil {
+		return nil, err
+	}
+	return loadersFrom(c).country(r.db, r.code.CountryISO2, name), nil
+}
+
+func (r *swiftCode) Bank(c context.Context) *bank {
+	prefix := bankPrefix(r.code.SwiftCode)
+	return loadersFrom(c).bank(prefix, func() *bank {
+		b := &bank{db: r.db, prefix: prefix, countryName: r.code.CountryName}
+		if r.code.IsHeadquarter {
+			b.name = r.code.BankName
+		} else {
+			b.headquarter = headquarterCode(r.code.SwiftCode)
+			b.branchName = r.code.BankName
+		}
+		return b
+	})
+}
+
+func (r *swiftCode) Branches(c context.Context) ([]*swiftCode, error) {
+	if !r.code.IsHeadquarter {
+		return []*swiftCode{}, nil
+	}
+	branches, _, err := loadersFrom(c).branches.load(c, headquarterCode(r.code.SwiftCode))
+	if err != nil {
+		return nil, err
+	}
+	// Branches share the country of their headquarter, whose name isn't
+	// stored with them.
+	branches = slices.Clone(branches)
+	for i := range branches {
+		branches[i].CountryName = r.code.CountryName
+	}
+	return swiftCodes(c, r.db, branches), nil
+}
+
+func (r *swiftCode) Headquarter(c context.Context) (*swiftCode, error) {
+	if r.code.IsHeadquarter {
+		return nil, nil
+	}
+	hq, found, err := loadersFrom(c).codes.load(c, headquarterCode(r.code.SwiftCode))
+	if err != nil || !found {
+		return nil, err
+	}
+	return &swiftCode{db: r.db, code: hq}, nil
+}
+
+// countryName returns name, or looks up the name of the country when the
+// store left it out.
+func countryName(c context.Context, iso2, name string) (string, error) {
+	if name != "" {
+		return name, nil
+	}
+	name, _, err := loadersFrom(c).countryNames.load(c, iso2)
+	return name, err
+}
+
+type country struct {
+	db   database.Store
+	iso2 string
+	name string
+
+	once  sync.Once
+	codes []models.SwiftCode
+	err   error
+}
+
+// load returns the current codes of the country, which its fields share.
+func (r *country) load(c context.Context) ([]models.SwiftCode, error) {
+	r.once.Do(func() {
+		r.codes, r.err = r.db.GetByCountryCode(c, r.iso2, database.Filter{})
+		r.err = notFound(r.err)
+		for i := range r.codes {
+			r.codes[i].CountryName = r.name
+		}
+	})
+	return r.codes, r.err
+}
+
+func (r *country) ISO2() string { return r.iso2 }
+func (r *country) Name() string { return r.name }
+
+func (r *country) SwiftCodes(c context.Context, args struct {
+	IsHeadquarter    *bool
+	IsTest           *bool
+	IsPassive        *bool
+	IsReverseBilling *bool
+}) ([]*swiftCode, error) {
+	codes, err := r.load(c)
+	if err != nil {
+		return nil, err
+	}
+	matches := func(filter *bool, value bool) bool { return filter == nil || *filter == value }
+	filtered := []models.SwiftCode{}
+	for _, code := range codes {
+		if matches(args.IsHeadquarter, code.IsHeadquarter) && matches(args.IsTest, code.IsTest) &&
+			matches(args.IsPassive, code.IsPassive) && matches(args.IsReverseBilling, code.IsReverseBilling) {
+			filtered = append(filtered, code)
+		}
+	}
+	return swiftCodes(c, r.db, filtered), nil
+}
+
+func (r *country) CodeCount(c context.Context) (int32, error) {
+	codes, err := r.load(c)
+	return int32(len(codes)), err
+}
+
+func (r *country) HeadquarterCount(c context.Context) (int32, error) {
+	codes, err := r.load(c)
+	count := 0
+	for _, code := range codes {
+		if code.IsHeadquarter {
+			count++
+		}
+	}
+	return int32(count), err
+}
+
+func (r *country) Banks(c context.Context) ([]*bank, error) {
+	codes, err := r.load(c)
+	if err != nil {
+		return nil, err
+	}
+	byPrefix := make(map[string][]models.SwiftCode)
+	for _, code := range codes {
+		prefix := bankPrefix(code.SwiftCode)
+		byPrefix[prefix] = append(byPrefix[prefix], code)
+	}
+	banks := []*bank{}
+	for _, prefix := range slices.Sorted(maps.Keys(byPrefix)) {
+		banks = append(banks, newBank(c, r.db, prefix, byPrefix[prefix]))
+	}
+	return banks, nil
+}
+
+type bank struct {
+	db database.Store
+	// prefix is the first 6 characters of the codes of the bank.
+	prefix string
+	// name is the name of the headquarter. When it isn't known, headquarter
+	// is looked up, and branchName is used if it isn't stored.
+	name        string
+	headquarter string
+	branchName  string
+	countryName string
+
+	once  sync.Once
+	codes []models.SwiftCode
+	err   error
+}
+
+// newBank returns the bank of the request with the prefix, which has codes
+// when it wasn't resolved before.
+func newBank(c context.Context, db database.Store, prefix string, codes []models.SwiftCode) *bank {
+	return loadersFrom(c).bank(prefix, func() *bank {
+		b := &bank{db: db, prefix: prefix, countryName: codes[0].CountryName, branchName: codes[0].BankName}
+		for _, code := range codes {
+			if code.IsHeadquarter {
+				b.name = code.BankName
+				break
+			}
+		}
+		b.once.Do(func() { b.codes = codes })
+		return b
+	})
+}
+
+// load returns the current codes of the bank.
+func (r *bank) load(c context.Context) ([]models.SwiftCode, error) {
+	r.once.Do(func() {
+		r.codes, r.err = r.db.GetByCodePrefix(c, r.prefix, maxBatchSize, database.Filter{})
+	})
+	return r.codes, r.err
+}
+
+func (r *bank) Code() string { return r.prefix[:min(len(r.prefix), 4)] }
+
+func (r *bank) Name(c context.Context) (string, error) {
+	if r.name != "" || r.headquarter == "" {
+		return cmp.Or(r.name, r.branchName), nil
+	}
+	hq, found, err := loadersFrom(c).codes.load(c, r.headquarter)
+	if err != nil {
+		return "", err
+	}
+	if found {
+		return hq.BankName, nil
+	}
+	return r.branchName, nil
+}
+
+func (r *bank) Country(c context.Context) (*country, error) {
+	iso2 := r.prefix[min(len(r.prefix), 4):]
+	name, err := countryName(c, iso2, r.countryName)
+	if err != nil {
+		return nil, err
+	}
+	return loadersFrom(c).country(r.db, iso2, name), nil
+}
+
+func (r *bank) SwiftCodes(c context.Context) ([]*swiftCode, error) {
+	codes, err := r.load(c)
+	if err != nil {
+		return nil, err
+	}
+	return swiftCodes(c, r.db, codes), nil
+}
+
+func (r *bank) Headquarters(c context.Context) ([]*swiftCode, error) {
+	codes, err := r.load(c)
+	if err != nil {
+		return nil, err
+	}
+	headquarters := []models.SwiftCode{}
+	for _, code := range codes {
+		if code.IsHeadquarter {
+			headquarters = append(headquarters, code)
+		}
+	}
+	return swiftCodes(c, r.db, headquarters), nil
+}
+
+// bankPrefix returns the institution and country codes a code starts with.
+func bankPrefix(code string) string {
+	return code[:min(len(code), 6)]
+}
@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+const headerAPIKey = "X-API-Key"
+
+// requireAdmin rejects requests that don't carry the admin API key in the
+// X-API-Key header.
+func (h *Handler) requireAdmin(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if !h.AdminEnabled() {
+			return echo.NewHTTPError(http.StatusForbidden, "Admin API is disabled")
+		}
+		if !h.IsAdminKey(c.Request().Header.Get(headerAPIKey)) {
+			return echo.NewHTTPError(http.StatusUnauthorized, "Invalid API key")
+		}
+		return next(c)
+	}
+}
+
+// AdminEnabled reports whether there is an admin API key.
+func (h *Handler) AdminEnabled() bool {
+	return h.adminKey != ""
+}
+
+// IsAdminKey reports whether key is the admin API key.
+func (h *Handler) IsAdminKey(key string) bool {
+	return h.AdminEnabled() && subtle.ConstantTimeCompare([]byte(key), []byte(h.adminKey)) == 1
+}
@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rtsncs/remitly-swift-api/models"
+)
+
+const (
+	defaultAutocompleteLimit = 10
+	maxAutocompleteLimit     = 50
+	// autocompleteBudget bounds the time spent looking up suggestions.
+	// Lookups that don't finish in time are dropped and the response is
+	// marked as partial.
+	autocompleteBudget = 150 * time.Millisecond
+)
+
+type autocompleteSuggestion struct {
+	SwiftCode     string `json:"swiftCode" xml:"swiftCode"`
+	BankName      string `json:"bankName" xml:"bankName"`
+	CountryISO2   string `json:"countryISO2" xml:"countryISO2"`
+	IsHeadquarter bool   `json:"isHeadquarter" xml:"isHeadquarter"`
+}
+
+type autocompleteResponse struct {
+	XMLName     xml.Name                 `json:"-" xml:"autocomplete"`
+	Suggestions []autocompleteSuggestion `json:"suggestions" xml:"suggestion"`
+	Partial     bool                     `json:"partial,omitempty" xml:"partial,attr,omitempty"`
+}
+
+// Autocomplete suggests codes whose SWIFT code or bank name starts with the
+// given prefix and that match the filter parameters. Code matches come first.
+func (h *Handler) Autocomplete(c echo.Context) error {
+	format, err := negotiateFormat(c)
+	if err != nil {
+		return err
+	}
+	prefix := strings.TrimSpace(c.QueryParam("prefix"))
+	if prefix == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "prefix is required")
+	}
+	limit := defaultAutocompleteLimit
+	if s := c.QueryParam("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 || n > maxAutocompleteLimit {
+			return echo.NewHTTPError(http.StatusBadRequest, "limit must be between 1 and "+strconv.Itoa(maxAutocompleteLimit))
+		}
+		limit = n
+	}
+	filter, err := parseFilter(c)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), autocompleteBudget)
+	defer cancel()
+
+	var (
+		wg               sync.WaitGroup
+		byCode, byName   []models.SwiftCode
+		codeErr, nameErr error
+	)
+	if code := strings.ToUpper(prefix); len(code) <= 11 && isAlphanumericString(code) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			byCode, codeErr = h.db.GetByCodePrefix(ctx, code, limit, filter)
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		byName, nameErr = h.db.GetByBankNamePrefix(ctx, prefix, limit, filter)
+	}()
+	wg.Wait()
+
+	response := autocompleteResponse{Suggestions: []autocompleteSuggestion{}}
+	for _, err := range []error{codeErr, nameErr} {
+		// Drivers report interrupted queries in their own way, so the
+		// budget is checked instead of the error.
+		if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			response.Partial = true
+		} else if err != nil {
+			return err
+		}
+	}
+
+	var rows []models.SwiftCode
+	seen := map[string]bool{}
+	for _, code := range append(byCode, byName...) {
+		if len(rows) == limit {
+			break
+		}
+		if seen[code.SwiftCode] {
+			continue
+		}
+		seen[code.SwiftCode] = true
+		rows = append(rows, code)
+		response.Suggestions = append(response.Suggestions, autocompleteSuggestion{
+			SwiftCode:     code.SwiftCode,
+			BankName:      code.BankName,
+			CountryISO2:   code.CountryISO2,
+			IsHeadquarter: code.IsHeadquarter,
+		})
+	}
+
+	return respond(c, format, http.StatusOK, response, rows)
+}
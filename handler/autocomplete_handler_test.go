@@ -0,0 +1,142 @@
+package handler_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rtsncs/remitly-swift-api/database"
+	"github.com/rtsncs/remitly-swift-api/handler"
+	"github.com/rtsncs/remitly-swift-api/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAutocomplete(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  string
+		status int
+		output string
+	}{
+		{
+			name:   "code prefix",
+			query:  "?prefix=bankus",
+			status: http.StatusOK,
+			output: `{"suggestions":[{"swiftCode":"BANKUS33ABC","bankName":"Bank Branch","countryISO2":"US","isHeadquarter":false},{"swiftCode":"BANKUS33XXX","bankName":"Bank HQ","countryISO2":"US","isHeadquarter":true}]}`,
+		},
+		{
+			name:   "csv",
+			query:  "?prefix=bankus&format=csv",
+			status: http.StatusOK,
+			output: "swiftCode,bankName,address,countryISO2,countryName,isHeadquarter,isTest,isPassive,isReverseBilling\nBANKUS33ABC,Bank Branch,1 Branch Street,US,UNITED STATES,false,false,false,false\nBANKUS33XXX,Bank HQ,1 HQ Street,US,UNITED STATES,true,false,false,false",
+		},
+		{
+			name:   "xml",
+			query:  "?prefix=bank%20h&format=xml",
+			status: http.StatusOK,
+			output: `<?xml version="1.0" encoding="UTF-8"?>` + "\n" + `<autocomplete><suggestion><swiftCode>BANKUS33XXX</swiftCode><bankName>Bank HQ</bankName><countryISO2>US</countryISO2><isHeadquarter>true</isHeadquarter></suggestion></autocomplete>`,
+		},
+		{
+			name:   "unsupported format",
+			query:  "?prefix=bank&format=yaml",
+			status: http.StatusNotAcceptable,
+			output: `{"message":"Not Acceptable"}`,
+		},
+		{
+			name:   "test codes",
+			query:  "?prefix=bankgb&isTest=true",
+			status: http.StatusOK,
+			output: `{"suggestions":[{"swiftCode":"BANKGB20XXX","bankName":"Bank GB","countryISO2":"GB","isHeadquarter":true}]}`,
+		},
+		{
+			name:   "reverse billing codes by name",
+			query:  "?prefix=bank%20g&isReverseBilling=true",
+			status: http.StatusOK,
+			output: `{"suggestions":[{"swiftCode":"BANKGB22XXX","bankName":"Bank GB","countryISO2":"GB","isHeadquarter":true}]}`,
+		},
+		{
+			name:   "invalid filter",
+			query:  "?prefix=bank&isPassive=maybe",
+			status: http.StatusBadRequest,
+			output: `{"message":"isPassive must be true or false"}`,
+		},
+		{
+			name:   "bank name prefix",
+			query:  "?prefix=bank%20h",
+			status: http.StatusOK,
+			output: `{"suggestions":[{"swiftCode":"BANKUS33XXX","bankName":"Bank HQ","countryISO2":"US","isHeadquarter":true}]}`,
+		},
+		{
+			name:   "code and name matches are merged",
+			query:  "?prefix=Bank&limit=7",
+			status: http.StatusOK,
+			output: `{"suggestions":[{"swiftCode":"BANKGB20XXX","bankName":"Bank GB","countryISO2":"GB","isHeadquarter":true},{"swiftCode":"BANKGB21XXX","bankName":"Bank GB","countryISO2":"GB","isHeadquarter":true},{"swiftCode":"BANKGB22XXX","bankName":"Bank GB","countryISO2":"GB","isHeadquarter":true},{"swiftCode":"BANKPLPWXXX","bankName":"Bank PL","countryISO2":"PL","isHeadquarter":true},{"swiftCode":"BANKUS33ABC","bankName":"Bank Branch","countryISO2":"US","isHeadquarter":false},{"swiftCode":"BANKUS33XXX","bankName":"Bank HQ","countryISO2":"US","isHeadquarter":true}]}`,
+		},
+		{
+			name:   "limit",
+			query:  "?prefix=bank&limit=2",
+			status: http.StatusOK,
+			output: `{"suggestions":[{"swiftCode":"BANKGB20XXX","bankName":"Bank GB","countryISO2":"GB","isHeadquarter":true},{"swiftCode":"BANKGB21XXX","bankName":"Bank GB","countryISO2":"GB","isHeadquarter":true}]}`,
+		},
+		{
+			name:   "no matches",
+			query:  "?prefix=zzz",
+			status: http.StatusOK,
+			output: `{"suggestions":[]}`,
+		},
+		{
+			name:   "missing prefix",
+			query:  "",
+			status: http.StatusBadRequest,
+			output: `{"message":"prefix is required"}`,
+		},
+		{
+			name:   "limit too large",
+			query:  "?prefix=bank&limit=51",
+			status: http.StatusBadRequest,
+			output: `{"message":"limit must be between 1 and 50"}`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			status, body := request(t, http.MethodGet, apiPrefix+"/autocomplete"+tc.query, "")
+			assert.Equal(t, tc.status, status)
+			assert.Equal(t, tc.output, body)
+		})
+	}
+}
+
+// interruptedStore answers bank name lookups only once they time out, with
+// an error that doesn't wrap the context's, as SQLite does.
+type interruptedStore struct {
+	*database.Memory
+}
+
+func (s interruptedStore) GetByBankNamePrefix(c context.Context, prefix string, limit int, filter database.Filter) ([]models.SwiftCode, error) {
+	<-c.Done()
+	return nil, errors.New("interrupted (9)")
+}
+
+func TestAutocompletePartial(t *testing.T) {
+	db := database.NewMemory()
+	err := db.InsertCode(context.Background(), models.SwiftCode{SwiftCode: "BANKUS33XXX", BankName: "Bank HQ", CountryISO2: "US", CountryName: "UNITED STATES", IsHeadquarter: true})
+	assert.NoError(t, err)
+
+	e := echo.New()
+	h := handler.New(interruptedStore{db}, handler.Config{})
+	h.Register(e)
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, apiPrefix+"/autocomplete?prefix=bankus", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"suggestions":[{"swiftCode":"BANKUS33XXX","bankName":"Bank HQ","countryISO2":"US","isHeadquarter":true}],"partial":true}`, rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, apiPrefix+"/autocomplete?prefix=bankus&format=xml", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `<autocomplete partial="true">`)
+}
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rtsncs/remitly-swift-api/models"
+)
+
+const (
+	defaultChangesLimit = 100
+	maxChangesLimit     = 1000
+)
+
+type changesResponse struct {
+	Changes []models.Change `json:"changes"`
+	// Cursor is the since parameter that resumes the feed after the last
+	// change returned, or the one the request was sent with when there were
+	// none.
+	Cursor  string `json:"cursor"`
+	HasMore bool   `json:"hasMore"`
+}
+
+// GetChanges returns the changes made to codes after the change the since
+// cursor points at, oldest first.
+func (h *Handler) GetChanges(c echo.Context) error {
+	var since int64
+	if s := c.QueryParam("since"); s != "" {
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil || n < 0 {
+			return echo.NewHTTPError(http.StatusBadRequest, "since must be a cursor returned by the feed")
+		}
+		since = n
+	}
+	limit := defaultChangesLimit
+	if s := c.QueryParam("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 || n > maxChangesLimit {
+			return echo.NewHTTPError(http.StatusBadRequest, "limit must be between 1 and "+strconv.Itoa(maxChangesLimit))
+		}
+		limit = n
+	}
+
+	// One more change than asked for tells whether the feed goes on.
+	changes, err := h.db.GetChanges(c.Request().Context(), since, limit+1)
+	if err != nil {
+		return err
+	}
+	response := changesResponse{Changes: changes, Cursor: strconv.FormatInt(since, 10)}
+	if len(changes) > limit {
+		response.Changes, response.HasMore = changes[:limit], true
+	}
+	if n := len(response.Changes); n > 0 {
+		response.Cursor = strconv.FormatInt(response.Changes[n-1].ID, 10)
+	}
+	if response.Changes == nil {
+		response.Changes = []models.Change{}
+	}
+	return c.JSON(http.StatusOK, response)
+}
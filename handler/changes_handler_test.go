@@ -0,0 +1,113 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type changeFeed struct {
+	Changes []struct {
+		ID        int64  `json:"id"`
+		Type      string `json:"type"`
+		SwiftCode string `json:"swiftCode"`
+		Version   int    `json:"version"`
+		Record    *struct {
+			BankName    string `json:"bankName"`
+			CountryName string `json:"countryName"`
+		} `json:"record"`
+	} `json:"changes"`
+	Cursor  string `json:"cursor"`
+	HasMore bool   `json:"hasMore"`
+}
+
+func getChanges(t *testing.T, query string) changeFeed {
+	t.Helper()
+	status, body := request(t, http.MethodGet, "/v1/changes"+query, "")
+	require.Equal(t, http.StatusOK, status, body)
+	var feed changeFeed
+	require.NoError(t, json.Unmarshal([]byte(body), &feed))
+	return feed
+}
+
+func TestGetChanges(t *testing.T) {
+	// The seed codes were the first writes to the store.
+	feed := getChanges(t, "?limit=2")
+	require.Len(t, feed.Changes, 2)
+	assert.Equal(t, int64(1), feed.Changes[0].ID)
+	assert.Equal(t, "created", feed.Changes[0].Type)
+	assert.Equal(t, "BANKUS33XXX", feed.Changes[0].SwiftCode)
+	assert.Equal(t, 1, feed.Changes[0].Version)
+	require.NotNil(t, feed.Changes[0].Record)
+	assert.Equal(t, "Bank HQ", feed.Changes[0].Record.BankName)
+	assert.Equal(t, "UNITED STATES", feed.Changes[0].Record.CountryName)
+	assert.Equal(t, "BANKUS33ABC", feed.Changes[1].SwiftCode)
+	assert.Equal(t, "2", feed.Cursor)
+	assert.True(t, feed.HasMore)
+
+	// The cursor resumes the feed after the last change returned.
+	feed = getChanges(t, "?since="+feed.Cursor+"&limit=1")
+	require.Len(t, feed.Changes, 1)
+	assert.Equal(t, int64(3), feed.Changes[0].ID)
+	assert.Equal(t, "BANKPLPWXXX", feed.Changes[0].SwiftCode)
+	assert.Equal(t, "3", feed.Cursor)
+
+	// Past the end of the feed, the cursor stays where it was.
+	feed = getChanges(t, "?since=1000000")
+	assert.Empty(t, feed.Changes)
+	assert.NotNil(t, feed.Changes)
+	assert.Equal(t, "1000000", feed.Cursor)
+	assert.False(t, feed.HasMore)
+}
+
+func TestGetChangesAfterWrites(t *testing.T) {
+	feed := getChanges(t, "?since=0&limit=1000")
+	for feed.HasMore {
+		feed = getChanges(t, "?since="+feed.Cursor+"&limit=1000")
+	}
+	cursor := feed.Cursor
+
+	code := `{"bankName":"Feed Bank","address":"","countryISO2":"JP","countryName":"Japan","isHeadquarter":true,"swiftCode":"FEEDJPJTXXX"}`
+	status, body := request(t, http.MethodPost, apiPrefix, code)
+	require.Equal(t, http.StatusCreated, status, body)
+	update := `{"bankName":"Feed Bank KK","address":"","countryISO2":"JP","countryName":"Japan","isHeadquarter":true}`
+	status, body = requestWithHeaders(t, http.MethodPut, apiPrefix+"/FEEDJPJTXXX", update, map[string]string{"If-Match": "*"})
+	require.Equal(t, http.StatusOK, status, body)
+	status, body = requestWithHeaders(t, http.MethodDelete, apiPrefix+"/FEEDJPJTXXX", "", map[string]string{"If-Match": "*"})
+	require.Equal(t, http.StatusOK, status, body)
+
+	feed = getChanges(t, "?since="+cursor)
+	require.Len(t, feed.Changes, 3)
+	for i, changeType := range []string{"created", "updated", "deleted"} {
+		assert.Equal(t, changeType, feed.Changes[i].Type)
+		assert.Equal(t, "FEEDJPJTXXX", feed.Changes[i].SwiftCode)
+	}
+	require.NotNil(t, feed.Changes[1].Record)
+	assert.Equal(t, "Feed Bank KK", feed.Changes[1].Record.BankName)
+	assert.Nil(t, feed.Changes[2].Record)
+	assert.False(t, feed.HasMore)
+}
+
+func TestGetChangesInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  string
+		output string
+	}{
+		{"cursor not a number", "?since=abc", `{"message":"since must be a cursor returned by the feed"}`},
+		{"negative cursor", "?since=-1", `{"message":"since must be a cursor returned by the feed"}`},
+		{"limit too large", "?limit=1001", `{"message":"limit must be between 1 and 1000"}`},
+		{"limit too small", "?limit=0", `{"message":"limit must be between 1 and 1000"}`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			status, body := request(t, http.MethodGet, "/v1/changes"+tc.query, "")
+			assert.Equal(t, http.StatusBadRequest, status)
+			assert.Equal(t, tc.output, body)
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/labstack/echo/v4"
+)
+
+// clientID identifies the client making the request.
+func (h *Handler) clientID(c echo.Context) string {
+	return h.ClientID(c.Request().Header.Get(headerAPIKey), c.RealIP())
+}
+
+// ClientID identifies the client sending the API key from the IP address.
+// Clients with a known API key are told apart by the key and others by
+// their IP address, so that made up keys don't get around rate limits or
+// replay the responses of others.
+func (h *Handler) ClientID(key, ip string) string {
+	if key != "" {
+		if _, ok := h.limits.Keys[key]; ok {
+			return hashKey(key)
+		}
+		if h.IsAdminKey(key) {
+			return hashKey(key)
+		}
+	}
+	return "ip:" + ip
+}
+
+// hashKey keeps API keys out of the stores that identify clients.
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return "key:" + hex.EncodeToString(sum[:])
+}
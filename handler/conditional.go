@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rtsncs/remitly-swift-api/database"
+	"github.com/rtsncs/remitly-swift-api/models"
+)
+
+// etag identifies the representation of the records in a format by their
+// codes and versions. Each format has a tag of its own, as the tag is
+// strong.
+func etag(format string, codes []models.SwiftCode) string {
+	codes = slices.Clone(codes)
+	slices.SortFunc(codes, func(a, b models.SwiftCode) int {
+		return cmp.Or(strings.Compare(a.SwiftCode, b.SwiftCode), cmp.Compare(a.Version, b.Version))
+	})
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\n", format)
+	for _, code := range codes {
+		fmt.Fprintf(h, "%s:%d\n", code.SwiftCode, code.Version)
+	}
+	return `"` + hex.EncodeToString(h.Sum(nil)[:16]) + `"`
+}
+
+// notModified sets the ETag and Last-Modified headers of a lookup and
+// reports whether the client's copy is still fresh. If-None-Match takes
+// precedence over If-Modified-Since.
+func notModified(c echo.Context, tag string, modified time.Time) bool {
+	header := c.Response().Header()
+	header.Set(echo.HeaderLastModified, modified.UTC().Format(http.TimeFormat))
+	header.Set("ETag", tag)
+
+	req := c.Request()
+	if ifNoneMatch := req.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		return matchETag(ifNoneMatch, tag, true)
+	}
+	since, err := http.ParseTime(req.Header.Get(echo.HeaderIfModifiedSince))
+	return err == nil && !modified.Truncate(time.Second).After(since)
+}
+
+// respondNotModified answers with 304, keeping the headers that a 200
+// response would have.
+func respondNotModified(c echo.Context) error {
+	c.Response().Header().Add(echo.HeaderVary, echo.HeaderAccept)
+	return c.NoContent(http.StatusNotModified)
+}
+
+// checkIfMatch makes writes conditional on the client having seen the
+// current representation of the code, in any format, so that it doesn't
+// overwrite changes it doesn't know about.
+func checkIfMatch(c echo.Context, tags []string) error {
+	ifMatch := c.Request().Header.Get("If-Match")
+	if ifMatch == "" {
+		return echo.NewHTTPError(http.StatusPreconditionRequired, "If-Match header is required")
+	}
+	for _, tag := range tags {
+		if matchETag(ifMatch, tag, false) {
+			return nil
+		}
+	}
+	return echo.NewHTTPError(http.StatusPreconditionFailed, "Swift code was changed")
+}
+
+// matchETag reports whether a list of entity tags from a conditional header
+// matches tag. Weak tags only match with weak comparison.
+func matchETag(header, tag string, weak bool) bool {
+	for candidate := range strings.SplitSeq(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		if strings.HasPrefix(candidate, "W/") {
+			if !weak {
+				continue
+			}
+			candidate = candidate[2:]
+		}
+		if candidate == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// current returns the current record of the code and the ETags of its
+// representations in every format, which cover the branches of
+// headquarters.
+func (h *Handler) current(c context.Context, code string) (models.SwiftCode, []string, error) {
+	stored, err := h.db.GetByCode(c, code)
+	if err != nil {
+		return models.SwiftCode{}, nil, err
+	}
+	codes := []models.SwiftCode{stored}
+	if stored.IsHeadquarter {
+		branches, err := h.db.GetBranches(c, code)
+		if err != nil && !errors.Is(err, database.ErrNotFound) {
+			return models.SwiftCode{}, nil, err
+		}
+		codes = append(codes, branches...)
+	}
+	tags := []string{etag(formatJSON, codes), etag(formatCSV, codes), etag(formatXML, codes)}
+	return stored, tags, nil
+}
+
+// codePrefix is the prefix of the codes in the representation of code,
+// which for headquarters includes their branches.
+func codePrefix(code models.SwiftCode) string {
+	if code.IsHeadquarter {
+		return code.SwiftCode[:8]
+	}
+	return code.SwiftCode
+}
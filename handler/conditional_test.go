@@ -0,0 +1,94 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rtsncs/remitly-swift-api/database"
+	"github.com/rtsncs/remitly-swift-api/handler"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConditionalRequests(t *testing.T) {
+	e := echo.New()
+	h := handler.New(database.NewMemory(), handler.Config{})
+	h.Register(e)
+
+	send := func(method, path, body string, headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+	hq := `{"bankName":"Bank","address":"","countryISO2":"NL","countryName":"Netherlands","isHeadquarter":true,"swiftCode":"CONDNL2AXXX"}`
+	require.Equal(t, http.StatusCreated, send(http.MethodPost, apiPrefix, hq, nil).Code)
+
+	rec := send(http.MethodGet, apiPrefix+"/CONDNL2AXXX", "", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	tag := rec.Header().Get("ETag")
+	assert.NotEmpty(t, tag)
+	modified, err := http.ParseTime(rec.Header().Get("Last-Modified"))
+	require.NoError(t, err)
+
+	rec = send(http.MethodGet, apiPrefix+"/CONDNL2AXXX", "", map[string]string{"If-None-Match": `"other", W/` + tag})
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+	assert.Empty(t, rec.Body.String())
+	assert.Equal(t, tag, rec.Header().Get("ETag"))
+	rec = send(http.MethodGet, apiPrefix+"/CONDNL2AXXX", "", map[string]string{"If-Modified-Since": modified.Format(http.TimeFormat)})
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+	rec = send(http.MethodGet, apiPrefix+"/CONDNL2AXXX", "", map[string]string{"If-Modified-Since": modified.Add(-time.Second).Format(http.TimeFormat)})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	rec = send(http.MethodGet, apiPrefix+"/country/NL", "", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	countryTag := rec.Header().Get("ETag")
+
+	// Each format has a tag of its own.
+	rec = send(http.MethodGet, apiPrefix+"/CONDNL2AXXX?format=csv", "", map[string]string{"If-None-Match": tag})
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.NotEqual(t, tag, rec.Header().Get("ETag"))
+
+	// New branches are part of the headquarter and the country.
+	branch := `{"bankName":"Bank","address":"","countryISO2":"NL","countryName":"Netherlands","isHeadquarter":false,"swiftCode":"CONDNL2A001"}`
+	require.Equal(t, http.StatusCreated, send(http.MethodPost, apiPrefix, branch, nil).Code)
+	rec = send(http.MethodGet, apiPrefix+"/CONDNL2AXXX", "", map[string]string{"If-None-Match": tag})
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.NotEqual(t, tag, rec.Header().Get("ETag"))
+	tag = rec.Header().Get("ETag")
+	rec = send(http.MethodGet, apiPrefix+"/country/NL", "", map[string]string{"If-None-Match": countryTag})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	rec = send(http.MethodGet, apiPrefix+"/CONDNL2AXXX?format=xml", "", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	xmlTag := rec.Header().Get("ETag")
+
+	updated := `{"bankName":"New Bank","address":"","countryISO2":"NL","countryName":"Netherlands","isHeadquarter":true}`
+	rec = send(http.MethodPut, apiPrefix+"/CONDNL2AXXX", updated, nil)
+	assert.Equal(t, http.StatusPreconditionRequired, rec.Code)
+	rec = send(http.MethodPut, apiPrefix+"/CONDNL2AXXX", updated, map[string]string{"If-Match": `"stale"`})
+	assert.Equal(t, http.StatusPreconditionFailed, rec.Code)
+	rec = send(http.MethodPut, apiPrefix+"/CONDNL2AXXX", updated, map[string]string{"If-Match": "W/" + tag})
+	assert.Equal(t, http.StatusPreconditionFailed, rec.Code)
+	// The tag of any format will do.
+	rec = send(http.MethodPut, apiPrefix+"/CONDNL2AXXX", updated, map[string]string{"If-Match": xmlTag})
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	rec = send(http.MethodGet, apiPrefix+"/CONDNL2AXXX", "", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"bankName":"New Bank"`)
+	assert.NotEqual(t, tag, rec.Header().Get("ETag"))
+
+	// The update made the old ETag stale.
+	rec = send(http.MethodDelete, apiPrefix+"/CONDNL2AXXX", "", map[string]string{"If-Match": tag})
+	assert.Equal(t, http.StatusPreconditionFailed, rec.Code)
+
+	rec = send(http.MethodPut, apiPrefix+"/CONDNL2AXXX", strings.Replace(updated, "{", `{"swiftCode":"CONDNL2BXXX",`, 1), map[string]string{"If-Match": "*"})
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
@@ -0,0 +1,27 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rtsncs/remitly-swift-api/database"
+)
+
+// databaseErrors answers requests whose database operations timed out with
+// 504 and those that couldn't reach the database with 503, rather than 500.
+func databaseErrors(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		return databaseError(next(c))
+	}
+}
+
+func databaseError(err error) error {
+	switch {
+	case errors.Is(err, database.ErrTimeout):
+		return echo.NewHTTPError(http.StatusGatewayTimeout, "Database timeout").SetInternal(err)
+	case errors.Is(err, database.ErrUnavailable):
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "Database unavailable").SetInternal(err)
+	}
+	return err
+}
@@ -0,0 +1,72 @@
+package handler_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rtsncs/remitly-swift-api/database"
+	"github.com/rtsncs/remitly-swift-api/handler"
+	"github.com/rtsncs/remitly-swift-api/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// failingStore fails lookups of a code with err.
+type failingStore struct {
+	database.Store
+	err error
+}
+
+func (s failingStore) GetByCodeAsOf(c context.Context, code string, asOf time.Time) (models.SwiftCode, error) {
+	return models.SwiftCode{}, s.err
+}
+
+func TestDatabaseErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		body   string
+	}{
+		{"timeout", fmt.Errorf("%w: %w", database.ErrTimeout, context.DeadlineExceeded), http.StatusGatewayTimeout, `{"message":"Database timeout"}`},
+		{"unavailable", fmt.Errorf("%w: connection refused", database.ErrUnavailable), http.StatusServiceUnavailable, `{"message":"Database unavailable"}`},
+		{"other", fmt.Errorf("syntax error"), http.StatusInternalServerError, `{"message":"Internal Server Error"}`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			e := echo.New()
+			h := handler.New(failingStore{database.NewMemory(), tc.err}, handler.Config{})
+			h.Register(e)
+
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, apiPrefix+"/BANKUS33XXX", nil))
+			assert.Equal(t, tc.status, rec.Code)
+			assert.JSONEq(t, tc.body, rec.Body.String())
+		})
+	}
+}
+
+// prefixFailingStore fails the prefix lookups used to suggest codes.
+type prefixFailingStore struct {
+	database.Store
+}
+
+func (s prefixFailingStore) GetByCodePrefix(c context.Context, prefix string, limit int, filter database.Filter) ([]models.SwiftCode, error) {
+	return nil, fmt.Errorf("%w: connection refused", database.ErrUnavailable)
+}
+
+func TestSuggestionsFailing(t *testing.T) {
+	e := echo.New()
+	h := handler.New(prefixFailingStore{database.NewMemory()}, handler.Config{})
+	h.Register(e)
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, apiPrefix+"/BANKUS33XXX", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.JSONEq(t, `{"message":"Not Found"}`, rec.Body.String())
+}
@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rtsncs/remitly-swift-api/exporter"
+)
+
+func (h *Handler) Export(c echo.Context) error {
+	formatParam := c.QueryParam("format")
+	if formatParam == "" {
+		formatParam = string(exporter.CSV)
+	}
+	format, err := exporter.ParseFormat(strings.ToLower(formatParam))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	countryCode := strings.ToUpper(c.QueryParam("country"))
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, format.ContentType())
+	res.Header().Set(echo.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="swift-codes.%s"`, format))
+	res.WriteHeader(http.StatusOK)
+
+	if _, err := exporter.Export(c.Request().Context(), h.db, res, format, countryCode); err != nil {
+		c.Logger().Errorf("Export failed: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package handler_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExport(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		output string
+		status int
+	}{
+		{
+			name:   "csv by country",
+			input:  "?format=csv&country=pl",
+			status: http.StatusOK,
+			output: "COUNTRY ISO2 CODE,SWIFT CODE,CODE TYPE,NAME,ADDRESS,TOWN NAME,COUNTRY NAME,TIME ZONE\nPL,BANKPLPWXXX,BIC11,Bank PL,2 HQ Street,,POLAND,",
+		},
+		{
+			name:   "ndjson by country",
+			input:  "?format=ndjson&country=PL",
+			status: http.StatusOK,
+			output: `{"address":"2 HQ Street","bankName":"Bank PL","countryISO2":"PL","countryName":"POLAND","isHeadquarter":true,"isPassive":false,"isReverseBilling":false,"isTest":false,"swiftCode":"BANKPLPWXXX"}`,
+		},
+		{
+			name:   "unsupported format",
+			input:  "?format=pdf",
+			status: http.StatusBadRequest,
+			output: `{"message":"unsupported format \"pdf\""}`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			status, body := request(t, http.MethodGet, apiPrefix+"/export"+tc.input, "")
+			assert.Equal(t, tc.status, status)
+			assert.Equal(t, tc.output, body)
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rtsncs/remitly-swift-api/database"
+	"github.com/rtsncs/remitly-swift-api/models"
+)
+
+// parseFilter reads the isTest, isPassive, isReverseBilling and asOf query
+// parameters. Parameters that are missing don't filter.
+func parseFilter(c echo.Context) (database.Filter, error) {
+	var filter database.Filter
+	params := []struct {
+		name string
+		dest **bool
+	}{
+		{"isTest", &filter.IsTest},
+		{"isPassive", &filter.IsPassive},
+		{"isReverseBilling", &filter.IsReverseBilling},
+	}
+
+	for _, param := range params {
+		value := c.QueryParam(param.name)
+		if value == "" {
+			continue
+		}
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return filter, echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("%s must be true or false", param.name))
+		}
+		*param.dest = &b
+	}
+
+	asOf, err := parseAsOf(c)
+	if err != nil {
+		return filter, err
+	}
+	filter.AsOf = asOf
+
+	return filter, nil
+}
+
+// parseAsOf reads the asOf query parameter, the date to look codes up on.
+// It is zero when missing, which selects the current codes.
+func parseAsOf(c echo.Context) (time.Time, error) {
+	value := c.QueryParam("asOf")
+	if value == "" {
+		return time.Time{}, nil
+	}
+	asOf, err := time.Parse(models.DateLayout, value)
+	if err != nil {
+		return time.Time{}, echo.NewHTTPError(http.StatusBadRequest, "asOf must be a YYYY-MM-DD date")
+	}
+	return asOf, nil
+}
@@ -0,0 +1,29 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type graphQLRequest struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+// GraphQL runs a GraphQL query. Like other GraphQL servers, it answers with
+// 200 whether or not the query failed, and reports failures in the errors
+// of the response.
+func (h *Handler) GraphQL(c echo.Context) error {
+	req := new(graphQLRequest)
+	if err := c.Bind(req); err != nil {
+		return err
+	}
+	if req.Query == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "query is required")
+	}
+
+	response := h.graphql.Exec(c.Request().Context(), req.Query, req.OperationName, req.Variables)
+	return c.JSON(http.StatusOK, response)
+}
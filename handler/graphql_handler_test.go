@@ -0,0 +1,50 @@
+package handler_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGraphQL(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		status int
+		output string
+	}{
+		{
+			name:   "headquarter with branches",
+			input:  `{"query":"query($code: String!) { swiftCode(code: $code) { bankName country { name } branches { swiftCode headquarter { swiftCode } } } }","variables":{"code":"BANKUS33XXX"}}`,
+			status: http.StatusOK,
+			output: `{"data":{"swiftCode":{"bankName":"Bank HQ","country":{"name":"UNITED STATES"},"branches":[{"swiftCode":"BANKUS33ABC","headquarter":{"swiftCode":"BANKUS33XXX"}}]}}}`,
+		},
+		{
+			name:   "unknown code",
+			input:  `{"query":"{ swiftCode(code: \"NONEUS33XXX\") { bankName } }"}`,
+			status: http.StatusOK,
+			output: `{"data":{"swiftCode":null}}`,
+		},
+		{
+			name:   "invalid query",
+			input:  `{"query":"{ swiftCode { bankName } }"}`,
+			status: http.StatusOK,
+			output: `{"errors":[{"message":"Field \"swiftCode\" argument \"code\" of type \"String!\" is required but not provided.","locations":[{"line":1,"column":3}]}]}`,
+		},
+		{
+			name:   "missing query",
+			input:  `{}`,
+			status: http.StatusBadRequest,
+			output: `{"message":"query is required"}`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			status, body := request(t, http.MethodPost, "/graphql", tc.input)
+			assert.Equal(t, tc.status, status)
+			assert.Equal(t, tc.output, body)
+		})
+	}
+}
@@ -1,15 +1,81 @@
 package handler
 
-import "github.com/rtsncs/remitly-swift-api/database"
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/rtsncs/remitly-swift-api/database"
+	"github.com/rtsncs/remitly-swift-api/gql"
+)
 
 type Handler struct {
-	db *database.Database
+	db       database.Store
+	adminKey string
+	imports  *imports
+	// draining is set once the server starts shutting down.
+	draining *atomic.Bool
+	// primaryPin is how long clients read from the primary database after
+	// a write.
+	primaryPin time.Duration
+	limits     RateLimits
+	graphql    *gql.Schema
+}
+
+// Config holds the settings of a Handler.
+type Config struct {
+	// AdminAPIKey authorizes requests to the admin routes, which are
+	// disabled when it's empty.
+	AdminAPIKey string
+	// ReadYourWrites is how long clients read from the primary database
+	// after a write, rather than from a replica that may not have it yet.
+	ReadYourWrites time.Duration
+	RateLimits     RateLimits
 }
 
 type genericResponse struct {
 	Message string `json:"message"`
 }
 
-func New(db *database.Database) Handler {
-	return Handler{db}
+func New(db database.Store, config Config) Handler {
+	return Handler{
+		db:         db,
+		adminKey:   config.AdminAPIKey,
+		imports:    newImports(),
+		draining:   &atomic.Bool{},
+		primaryPin: config.ReadYourWrites,
+		limits:     config.RateLimits,
+		graphql:    gql.New(db),
+	}
+}
+
+func (h *Handler) Register(e *echo.Echo) {
+	e.Use(databaseErrors)
+	e.Use(h.readYourWrites)
+
+	e.GET("/openapi.json", h.OpenAPI)
+	e.GET("/livez", h.Live)
+	e.GET("/readyz", h.Ready)
+
+	lookup, write := h.rateLimit(LimitLookup), h.rateLimit(LimitWrite)
+
+	e.GET("/v1/iban/:iban", h.GetIBAN, lookup)
+	e.POST("/graphql", h.GraphQL, lookup)
+	e.GET("/v1/changes", h.GetChanges, lookup)
+
+	g := e.Group("/v1/swift-codes")
+	g.GET("/export", h.Export, lookup)
+	g.GET("/autocomplete", h.Autocomplete, lookup)
+	g.GET("/:code", h.GetCode, lookup)
+	g.GET("/country/:countryCode", h.GetByCountryCode, lookup)
+	g.GET("/by-national-id/:scheme/:id", h.GetByNationalID, lookup)
+	g.POST("", h.AddCode, write, h.idempotent)
+	g.PUT("/:code", h.UpdateCode, write, h.idempotent)
+	g.DELETE("/:code", h.DeleteCode, write, h.idempotent)
+
+	// Limiting before the key check slows down guessing it.
+	admin := e.Group("/v1/imports", h.rateLimit(LimitAdmin), h.requireAdmin, middleware.BodyLimit(maxImportSize))
+	admin.POST("", h.CreateImport)
+	admin.GET("/:id", h.GetImport)
 }
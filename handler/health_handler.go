@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// readyTimeout bounds the database check of the readiness probe.
+const readyTimeout = 2 * time.Second
+
+// Live reports that the process is up.
+func (h *Handler) Live(c echo.Context) error {
+	return c.JSON(http.StatusOK, genericResponse{"OK"})
+}
+
+// Ready reports whether the server should receive traffic: it isn't shutting
+// down and the database can be reached.
+func (h *Handler) Ready(c echo.Context) error {
+	if h.draining.Load() {
+		return c.JSON(http.StatusServiceUnavailable, genericResponse{"Shutting down"})
+	}
+	ctx, cancel := context.WithTimeout(c.Request().Context(), readyTimeout)
+	defer cancel()
+	if err := h.db.Ping(ctx); err != nil {
+		c.Logger().Errorf("Database ping failed: %v", err)
+		return c.JSON(http.StatusServiceUnavailable, genericResponse{"Database unavailable"})
+	}
+	return c.JSON(http.StatusOK, genericResponse{"OK"})
+}
+
+// Drain marks the server as not ready, so that load balancers stop sending
+// it requests before it shuts down.
+func (h *Handler) Drain() {
+	h.draining.Store(true)
+}
+
+// Shutdown drains the server, stops accepting imports and waits for the
+// running ones to finish or ctx to be done.
+func (h *Handler) Shutdown(ctx context.Context) error {
+	h.Drain()
+	return h.imports.close(ctx)
+}
@@ -0,0 +1,44 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rtsncs/remitly-swift-api/database"
+	"github.com/rtsncs/remitly-swift-api/handler"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealth(t *testing.T) {
+	status, body := request(t, http.MethodGet, "/livez", "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, `{"message":"OK"}`, body)
+
+	status, body = request(t, http.MethodGet, "/readyz", "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, `{"message":"OK"}`, body)
+}
+
+func TestShutdown(t *testing.T) {
+	e := echo.New()
+	h := handler.New(database.NewMemory(), handler.Config{AdminAPIKey: adminKey})
+	h.Register(e)
+
+	assert.NoError(t, h.Shutdown(context.Background()))
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.JSONEq(t, `{"message":"Shutting down"}`, rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/livez", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = upload(t, e, adminKey, "directory.csv", "COUNTRY ISO2 CODE,SWIFT CODE\n", nil)
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.JSONEq(t, `{"message":"Server is shutting down"}`, rec.Body.String())
+}
@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"encoding/xml"
+	"maps"
+	"net/http"
+	"slices"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rtsncs/remitly-swift-api/models"
+)
+
+type responseIBAN struct {
+	XMLName xml.Name `json:"-" xml:"iban"`
+	models.IBAN
+	SwiftCodes []decodedCode `json:"swiftCodes" xml:"swiftCodes>swiftCode"`
+}
+
+// GetIBAN validates an IBAN and resolves its national bank identifiers to the
+// SWIFT codes mapped to them.
+func (h *Handler) GetIBAN(c echo.Context) error {
+	format, err := negotiateFormat(c)
+	if err != nil {
+		return err
+	}
+
+	iban, err := models.ParseIBAN(c.Param("iban"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "IBAN "+err.Error())
+	}
+
+	ids := iban.NationalIDs()
+	var codes []models.SwiftCode
+	seen := map[string]bool{}
+	for _, scheme := range slices.Sorted(maps.Keys(ids)) {
+		found, err := h.db.GetByNationalID(c.Request().Context(), scheme, ids[scheme]...)
+		if err != nil {
+			return err
+		}
+		for _, code := range found {
+			if !seen[code.SwiftCode] {
+				seen[code.SwiftCode] = true
+				codes = append(codes, code)
+			}
+		}
+	}
+	if len(codes) == 0 {
+		return echo.NewHTTPError(http.StatusNotFound)
+	}
+
+	return respond(c, format, http.StatusOK, responseIBAN{IBAN: iban, SwiftCodes: decodeAll(codes)}, codes)
+}
@@ -0,0 +1,62 @@
+package handler_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetIBAN(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		status int
+		output string
+	}{
+		{
+			name:   "resolved",
+			input:  "PL61109010140000071219812874",
+			status: http.StatusOK,
+			output: `{"iban":"PL61109010140000071219812874","countryISO2":"PL","checkDigits":"61","bban":"109010140000071219812874","bankCode":"109","branchCode":"01014","swiftCodes":[{"address":"2 HQ Street","bankName":"Bank PL","countryISO2":"PL","countryName":"POLAND","isHeadquarter":true,"isPassive":false,"isReverseBilling":false,"isTest":false,"swiftCode":"BANKPLPWXXX","bic":{"institution":"BANK","country":"PL","location":"PW","branch":"XXX"}}]}`,
+		},
+		{
+			name:   "print format",
+			input:  "GB29%20NWBK%206016%201331%209268%2019",
+			status: http.StatusOK,
+			output: `{"iban":"GB29NWBK60161331926819","countryISO2":"GB","checkDigits":"29","bban":"NWBK60161331926819","bankCode":"NWBK","branchCode":"601613","swiftCodes":[{"address":"","bankName":"Bank GB","countryISO2":"GB","countryName":"UNITED KINGDOM","isHeadquarter":true,"isPassive":false,"isReverseBilling":false,"isTest":true,"swiftCode":"BANKGB20XXX","bic":{"institution":"BANK","country":"GB","location":"20","branch":"XXX"}}]}`,
+		},
+		{
+			name:   "unmapped",
+			input:  "DE89370400440532013000",
+			status: http.StatusNotFound,
+			output: `{"message":"Not Found"}`,
+		},
+		{
+			name:   "bad checksum",
+			input:  "GB28NWBK60161331926819",
+			status: http.StatusBadRequest,
+			output: `{"message":"IBAN has an invalid checksum"}`,
+		},
+		{
+			name:   "bad length",
+			input:  "DE8937040044053201300",
+			status: http.StatusBadRequest,
+			output: `{"message":"IBAN must be 22 characters long for DE"}`,
+		},
+		{
+			name:   "unknown country",
+			input:  "US12345678",
+			status: http.StatusBadRequest,
+			output: `{"message":"IBAN has an unknown country code \"US\""}`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			status, body := request(t, http.MethodGet, "/v1/iban/"+tc.input, "")
+			assert.Equal(t, tc.status, status)
+			assert.Equal(t, tc.output, body)
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rtsncs/remitly-swift-api/database"
+	"github.com/rtsncs/remitly-swift-api/models"
+)
+
+const (
+	headerIdempotencyKey     = "Idempotency-Key"
+	headerIdempotentReplayed = "Idempotent-Replayed"
+)
+
+// replayedHeaders are the response headers stored for retries. The others
+// are either set again by middleware or don't describe the result.
+var replayedHeaders = []string{echo.HeaderLocation, "ETag", echo.HeaderLastModified}
+
+const (
+	// IdempotencyTTL is how long responses are kept for retries.
+	IdempotencyTTL = 24 * time.Hour
+	// MaxIdempotencyKeySize is the length of the longest key accepted.
+	MaxIdempotencyKeySize = 255
+)
+
+// idempotent stores the response to a request sent with an Idempotency-Key
+// and replays it when the client retries the request with the same key.
+// Reusing a key for a different request is rejected with 422, and retrying
+// while the first request is still being handled with 409. Server errors
+// aren't stored, so that the request can be retried.
+func (h *Handler) idempotent(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		key := c.Request().Header.Get(headerIdempotencyKey)
+		if key == "" {
+			return next(c)
+		}
+		if len(key) > MaxIdempotencyKeySize {
+			return echo.NewHTTPError(http.StatusBadRequest, "Idempotency-Key is too long")
+		}
+
+		req := c.Request()
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			return err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+
+		now := time.Now().UTC()
+		record := models.IdempotencyRecord{
+			Key:         h.clientID(c) + ":" + key,
+			RequestHash: requestHash(req.Method, req.URL.Path, body),
+			CreatedAt:   now,
+		}
+		ctx := req.Context()
+		err = h.db.InsertIdempotencyRecord(ctx, record, now.Add(-IdempotencyTTL))
+		if errors.Is(err, database.ErrDuplicate) {
+			return h.replay(c, record)
+		}
+		if err != nil {
+			return err
+		}
+
+		// The request is over, but its record must still be settled. A
+		// record that isn't completed, because the request failed or
+		// panicked, is deleted for the request to be retried.
+		ctx = context.WithoutCancel(ctx)
+		completed := false
+		defer func() {
+			if completed {
+				return
+			}
+			if err := h.db.DeleteIdempotencyRecord(ctx, record.Key); err != nil {
+				c.Logger().Errorf("Failed to delete idempotency record: %v", err)
+			}
+		}()
+
+		recorder := &bodyRecorder{ResponseWriter: c.Response().Writer}
+		c.Response().Writer = recorder
+		if err := next(c); err != nil {
+			c.Error(databaseError(err))
+		}
+
+		res := c.Response()
+		if res.Status >= http.StatusInternalServerError {
+			return nil
+		}
+		completed = true
+		record.Status = res.Status
+		record.ContentType = res.Header().Get(echo.HeaderContentType)
+		record.Header = http.Header{}
+		for _, name := range replayedHeaders {
+			if values := res.Header().Values(name); len(values) > 0 {
+				record.Header[http.CanonicalHeaderKey(name)] = values
+			}
+		}
+		record.Body = recorder.body.Bytes()
+		if err := h.db.CompleteIdempotencyRecord(ctx, record); err != nil {
+			c.Logger().Errorf("Failed to store idempotent response: %v", err)
+		}
+		return nil
+	}
+}
+
+// replay answers a retried request with the stored response of the first
+// one.
+func (h *Handler) replay(c echo.Context, record models.IdempotencyRecord) error {
+	stored, err := h.db.GetIdempotencyRecord(c.Request().Context(), record.Key)
+	if err != nil && !errors.Is(err, database.ErrNotFound) {
+		return err
+	}
+	if err == nil && stored.RequestHash != record.RequestHash {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, "Idempotency-Key was already used for a different request")
+	}
+	// A record that's gone was of a request that failed just now.
+	if err != nil || stored.Status == 0 {
+		return echo.NewHTTPError(http.StatusConflict, "A request with this Idempotency-Key is still being processed")
+	}
+	header := c.Response().Header()
+	for name, values := range stored.Header {
+		header[http.CanonicalHeaderKey(name)] = values
+	}
+	header.Set(headerIdempotentReplayed, "true")
+	return c.Blob(stored.Status, stored.ContentType, stored.Body)
+}
+
+func requestHash(method, path string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method + " " + path + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// bodyRecorder keeps a copy of the response body.
+type bodyRecorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *bodyRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
@@ -0,0 +1,151 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/rtsncs/remitly-swift-api/database"
+	"github.com/rtsncs/remitly-swift-api/handler"
+	"github.com/rtsncs/remitly-swift-api/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdempotency(t *testing.T) {
+	db := database.NewMemory()
+	e := echo.New()
+	h := handler.New(db, handler.Config{})
+	h.Register(e)
+
+	send := func(method, path, ip, key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set(echo.HeaderXRealIP, ip)
+		req.Header.Set("Idempotency-Key", key)
+		if method == http.MethodDelete {
+			req.Header.Set("If-Match", "*")
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+	body := `{"bankName":"Bank","address":"","countryISO2":"FR","countryName":"France","isHeadquarter":true,"swiftCode":"IDEMFRPPXXX"}`
+
+	rec := send(http.MethodPost, apiPrefix, "192.0.2.1", "add", body)
+	require.Equal(t, http.StatusCreated, rec.Code)
+	first := rec.Body.String()
+	firstHeader := rec.Header().Clone()
+	assert.Empty(t, rec.Header().Get("Idempotent-Replayed"))
+	assert.Equal(t, apiPrefix+"/IDEMFRPPXXX", firstHeader.Get(echo.HeaderLocation))
+
+	// The ETag is that of the code's representation.
+	lookup := httptest.NewRecorder()
+	e.ServeHTTP(lookup, httptest.NewRequest(http.MethodGet, apiPrefix+"/IDEMFRPPXXX", nil))
+	assert.Equal(t, lookup.Header().Get("ETag"), firstHeader.Get("ETag"))
+
+	// Retries get the first response rather than a conflict.
+	rec = send(http.MethodPost, apiPrefix, "192.0.2.1", "add", body)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, first, rec.Body.String())
+	assert.Equal(t, "true", rec.Header().Get("Idempotent-Replayed"))
+	for _, name := range []string{"ETag", echo.HeaderLocation, echo.HeaderContentType} {
+		assert.Equal(t, firstHeader.Get(name), rec.Header().Get(name), name)
+	}
+
+	rec = send(http.MethodPost, apiPrefix, "192.0.2.1", "add", strings.Replace(body, "Bank", "Other Bank", 1))
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+
+	// Keys belong to the client that sent them.
+	rec = send(http.MethodPost, apiPrefix, "192.0.2.2", "add", body)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	assert.Empty(t, rec.Header().Get("Idempotent-Replayed"))
+
+	rec = send(http.MethodDelete, apiPrefix+"/IDEMFRPPXXX", "192.0.2.1", "delete", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	rec = send(http.MethodDelete, apiPrefix+"/IDEMFRPPXXX", "192.0.2.1", "delete", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "true", rec.Header().Get("Idempotent-Replayed"))
+
+	rec = send(http.MethodPost, apiPrefix, "192.0.2.1", strings.Repeat("k", 256), body)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+// blockingStore holds inserts of codes until release is closed.
+type blockingStore struct {
+	database.Store
+	inserting chan struct{}
+	release   chan struct{}
+}
+
+func (s blockingStore) InsertCode(c context.Context, code models.SwiftCode) error {
+	close(s.inserting)
+	<-s.release
+	return s.Store.InsertCode(c, code)
+}
+
+func TestIdempotencyInFlight(t *testing.T) {
+	db := blockingStore{database.NewMemory(), make(chan struct{}), make(chan struct{})}
+	e := echo.New()
+	h := handler.New(db, handler.Config{})
+	h.Register(e)
+
+	send := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, apiPrefix, strings.NewReader(`{"bankName":"Bank","address":"","countryISO2":"FR","countryName":"France","isHeadquarter":true,"swiftCode":"SLOWFRPPXXX"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set("Idempotency-Key", "slow")
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- send() }()
+	select {
+	case <-db.inserting:
+	case <-time.After(5 * time.Second):
+		t.Fatal("The first request didn't reach the store")
+	}
+	assert.Equal(t, http.StatusConflict, send().Code)
+
+	close(db.release)
+	assert.Equal(t, http.StatusCreated, (<-done).Code)
+}
+
+// panickingStore panics on inserts of codes.
+type panickingStore struct {
+	database.Store
+}
+
+func (s panickingStore) InsertCode(c context.Context, code models.SwiftCode) error {
+	panic("insert failed")
+}
+
+func TestIdempotencyPanic(t *testing.T) {
+	db := panickingStore{database.NewMemory()}
+	e := echo.New()
+	e.Use(middleware.RecoverWithConfig(middleware.RecoverConfig{DisablePrintStack: true}))
+	h := handler.New(db, handler.Config{})
+	h.Register(e)
+
+	send := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, apiPrefix, strings.NewReader(`{"bankName":"Bank","address":"","countryISO2":"FR","countryName":"France","isHeadquarter":true,"swiftCode":"PANCFRPPXXX"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set("Idempotency-Key", "panic")
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	assert.Equal(t, http.StatusInternalServerError, send().Code)
+	// The record of the request was deleted, so the retry isn't taken for
+	// one still in flight.
+	assert.Equal(t, http.StatusInternalServerError, send().Code)
+	_, err := db.GetIdempotencyRecord(context.Background(), "ip:192.0.2.1:panic")
+	assert.ErrorIs(t, err, database.ErrNotFound)
+}
@@ -0,0 +1,251 @@
+package handler
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rtsncs/remitly-swift-api/loader"
+	"github.com/rtsncs/remitly-swift-api/models"
+)
+
+const (
+	// maxImportSize bounds the size of uploaded files.
+	maxImportSize = "100M"
+	// Finished imports are kept for importTTL, and at most
+	// maxFinishedImports of them.
+	importTTL          = 24 * time.Hour
+	maxFinishedImports = 100
+)
+
+// Statuses of an import.
+const (
+	importPending   = "pending"
+	importRunning   = "running"
+	importSucceeded = "succeeded"
+	importFailed    = "failed"
+)
+
+type importJob struct {
+	ID         string     `json:"id"`
+	Status     string     `json:"status"`
+	FileName   string     `json:"fileName"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+	// Error is set when the file couldn't be loaded at all. Records that
+	// failed to load are listed in Result.Errors.
+	Error string `json:"error,omitempty"`
+	// Progress is reported while a spreadsheet is parsed.
+	Progress *loader.Progress `json:"progress,omitempty"`
+	loader.Result
+}
+
+// imports keeps the running imports and the recently finished ones.
+type imports struct {
+	mu   sync.Mutex
+	jobs map[string]*importJob
+	// running counts the imports being loaded. No imports are started
+	// once closed is set.
+	running sync.WaitGroup
+	closed  bool
+}
+
+func newImports() *imports {
+	return &imports{jobs: make(map[string]*importJob)}
+}
+
+func (i *imports) get(id string) (importJob, bool) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	job, ok := i.jobs[id]
+	if !ok {
+		return importJob{}, false
+	}
+	return *job, true
+}
+
+func (i *imports) update(id string, fn func(job *importJob)) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	fn(i.jobs[id])
+}
+
+// start registers a new import, unless imports are closed.
+func (i *imports) start(job *importJob) bool {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if i.closed {
+		return false
+	}
+	i.prune(job.CreatedAt)
+	i.jobs[job.ID] = job
+	i.running.Add(1)
+	return true
+}
+
+// finish records the outcome of an import.
+func (i *imports) finish(id string, result loader.Result, err error) {
+	i.update(id, func(job *importJob) {
+		now := time.Now().UTC()
+		job.FinishedAt = &now
+		job.Status = importSucceeded
+		if err != nil {
+			job.Status = importFailed
+			job.Error = err.Error()
+		}
+		job.Result = result
+	})
+}
+
+// prune forgets the imports that finished more than importTTL before now,
+// and the oldest ones beyond maxFinishedImports. The caller holds mu.
+func (i *imports) prune(now time.Time) {
+	var finished []*importJob
+	for id, job := range i.jobs {
+		if job.FinishedAt == nil {
+			continue
+		}
+		if now.Sub(*job.FinishedAt) > importTTL {
+			delete(i.jobs, id)
+			continue
+		}
+		finished = append(finished, job)
+	}
+	if len(finished) <= maxFinishedImports {
+		return
+	}
+	slices.SortFunc(finished, func(a, b *importJob) int { return a.FinishedAt.Compare(*b.FinishedAt) })
+	for _, job := range finished[:len(finished)-maxFinishedImports] {
+		delete(i.jobs, job.ID)
+	}
+}
+
+// close stops new imports from starting and waits for the running ones.
+func (i *imports) close(ctx context.Context) error {
+	i.mu.Lock()
+	i.closed = true
+	i.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		i.running.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// CreateImport accepts a SWIFT data spreadsheet or CSV file uploaded in the
+// file field of a multipart form and loads it in the background.
+func (h *Handler) CreateImport(c echo.Context) error {
+	file, err := c.FormFile("file")
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "file is required")
+	}
+	ext := strings.ToLower(filepath.Ext(file.Filename))
+	if ext != ".xlsx" && ext != ".csv" {
+		return echo.NewHTTPError(http.StatusBadRequest, "file must be an XLSX or CSV file")
+	}
+
+	var opts loader.Options
+	if validFrom := c.FormValue("validFrom"); validFrom != "" {
+		if _, err := time.Parse(models.DateLayout, validFrom); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "validFrom must be a YYYY-MM-DD date")
+		}
+		opts.ValidFrom = validFrom
+	}
+	if partial := c.FormValue("partial"); partial != "" {
+		opts.Partial, err = strconv.ParseBool(partial)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "partial must be true or false")
+		}
+	}
+
+	path, err := saveUpload(file, ext)
+	if err != nil {
+		return err
+	}
+
+	id := rand.Text()
+	job := &importJob{
+		ID:        id,
+		Status:    importPending,
+		FileName:  file.Filename,
+		CreatedAt: time.Now().UTC(),
+		Result:    loader.Result{Errors: []loader.RowError{}},
+	}
+	response := *job
+	if !h.imports.start(job) {
+		os.Remove(path)
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "Server is shutting down")
+	}
+	go h.runImport(c.Logger(), id, path, opts)
+
+	c.Response().Header().Set(echo.HeaderLocation, "/v1/imports/"+id)
+	return c.JSON(http.StatusAccepted, response)
+}
+
+func (h *Handler) GetImport(c echo.Context) error {
+	job, ok := h.imports.get(c.Param("id"))
+	if !ok {
+		return echo.NewHTTPError(http.StatusNotFound)
+	}
+	return c.JSON(http.StatusOK, job)
+}
+
+// saveUpload copies the uploaded file to a temporary file with the given
+// extension, which tells the loader its format.
+func saveUpload(file *multipart.FileHeader, ext string) (string, error) {
+	src, err := file.Open()
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+
+	dst, err := os.CreateTemp("", "import-*"+ext)
+	if err != nil {
+		return "", err
+	}
+	defer dst.Close()
+	if _, err := io.Copy(dst, src); err != nil {
+		os.Remove(dst.Name())
+		return "", err
+	}
+	return dst.Name(), nil
+}
+
+func (h *Handler) runImport(logger echo.Logger, id, path string, opts loader.Options) {
+	defer h.imports.running.Done()
+	defer os.Remove(path)
+	// A panicking import fails on its own rather than taking the server
+	// down with it.
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Errorf("Import %s panicked: %v\n%s", id, r, debug.Stack())
+			h.imports.finish(id, loader.Result{Errors: []loader.RowError{}}, errors.New("internal error"))
+		}
+	}()
+	h.imports.update(id, func(job *importJob) { job.Status = importRunning })
+	opts.Progress = func(p loader.Progress) {
+		h.imports.update(id, func(job *importJob) { job.Progress = &p })
+	}
+
+	result, err := loader.LoadFromFileWithOptions(path, h.db, opts)
+	h.imports.finish(id, result, err)
+}
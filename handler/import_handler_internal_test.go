@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rtsncs/remitly-swift-api/database"
+	"github.com/rtsncs/remitly-swift-api/loader"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportsPrune(t *testing.T) {
+	now := time.Now().UTC()
+	i := newImports()
+	finishedAt := func(age time.Duration) *time.Time {
+		at := now.Add(-age)
+		return &at
+	}
+	i.jobs["running"] = &importJob{ID: "running"}
+	i.jobs["expired"] = &importJob{ID: "expired", FinishedAt: finishedAt(importTTL + time.Minute)}
+	for n := range maxFinishedImports + 1 {
+		id := fmt.Sprint(n)
+		i.jobs[id] = &importJob{ID: id, FinishedAt: finishedAt(time.Duration(n) * time.Minute)}
+	}
+
+	i.prune(now)
+	assert.Len(t, i.jobs, maxFinishedImports+1)
+	assert.Contains(t, i.jobs, "running")
+	assert.NotContains(t, i.jobs, "expired")
+	// The oldest of the finished imports is forgotten.
+	assert.NotContains(t, i.jobs, fmt.Sprint(maxFinishedImports))
+	assert.Contains(t, i.jobs, "0")
+}
+
+// panickingStore panics on any call, as its embedded Store is nil.
+type panickingStore struct {
+	database.Store
+}
+
+func TestImportPanic(t *testing.T) {
+	h := New(panickingStore{}, Config{})
+	f, err := os.CreateTemp(t.TempDir(), "import-*.csv")
+	require.NoError(t, err)
+	_, err = f.WriteString("COUNTRY ISO2 CODE,SWIFT CODE,CODE TYPE,NAME,ADDRESS,TOWN NAME,COUNTRY NAME,TIME ZONE\n" +
+		"NZ,IMPTNZ22XXX,BIC11,Imported Bank,,,New Zealand,\n")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	require.True(t, h.imports.start(&importJob{ID: "panic", CreatedAt: time.Now().UTC()}))
+	h.runImport(echo.New().Logger, "panic", f.Name(), loader.Options{})
+
+	job, ok := h.imports.get("panic")
+	require.True(t, ok)
+	assert.Equal(t, importFailed, job.Status)
+	assert.Equal(t, "internal error", job.Error)
+	assert.NotNil(t, job.FinishedAt)
+	assert.NoError(t, h.Shutdown(context.Background()))
+}
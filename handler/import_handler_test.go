@@ -0,0 +1,144 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rtsncs/remitly-swift-api/database"
+	"github.com/rtsncs/remitly-swift-api/handler"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type importResponse struct {
+	ID        string `json:"id"`
+	Status    string `json:"status"`
+	FileName  string `json:"fileName"`
+	Error     string `json:"error"`
+	Total     int    `json:"total"`
+	Inserted  int    `json:"inserted"`
+	Unchanged int    `json:"unchanged"`
+	Failed    int    `json:"failed"`
+	Errors    []struct {
+		Record string `json:"record"`
+		Error  string `json:"error"`
+	} `json:"errors"`
+}
+
+func uploadRequest(t *testing.T, filename, content string, fields map[string]string) *http.Request {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	for name, value := range fields {
+		require.NoError(t, w.WriteField(name, value))
+	}
+	if filename != "" {
+		part, err := w.CreateFormFile("file", filename)
+		require.NoError(t, err)
+		_, err = part.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/imports", &body)
+	req.Header.Set(echo.HeaderContentType, w.FormDataContentType())
+	return req
+}
+
+func upload(t *testing.T, e *echo.Echo, key, filename, content string, fields map[string]string) *httptest.ResponseRecorder {
+	req := uploadRequest(t, filename, content, fields)
+	if key != "" {
+		req.Header.Set("X-API-Key", key)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestImport(t *testing.T) {
+	content := "COUNTRY ISO2 CODE,SWIFT CODE,CODE TYPE,NAME,ADDRESS,TOWN NAME,COUNTRY NAME,TIME ZONE\n" +
+		"NZ,IMPTNZ22XXX,BIC11,Imported Bank,1 Queen St,,New Zealand,\n" +
+		"NZ,INVALID,BIC11,Imported Bank,,,New Zealand,\n"
+
+	// The other tests' codes are missing from the file, so it is loaded as
+	// partial to keep them.
+	rec := upload(t, e, adminKey, "directory.csv", content, map[string]string{"validFrom": "2026-01-01", "partial": "true"})
+	require.Equal(t, http.StatusAccepted, rec.Code)
+	var created importResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &created))
+	assert.Equal(t, "directory.csv", created.FileName)
+	assert.Equal(t, "/v1/imports/"+created.ID, rec.Header().Get(echo.HeaderLocation))
+
+	var job importResponse
+	assert.Eventually(t, func() bool {
+		status, body := requestWithHeaders(t, http.MethodGet, "/v1/imports/"+created.ID, "", map[string]string{"X-API-Key": adminKey})
+		require.Equal(t, http.StatusOK, status)
+		require.NoError(t, json.Unmarshal([]byte(body), &job))
+		return job.Status == "succeeded" || job.Status == "failed"
+	}, 5*time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, "succeeded", job.Status)
+	assert.Equal(t, 2, job.Total)
+	assert.Equal(t, 1, job.Inserted)
+	assert.Equal(t, 1, job.Failed)
+	require.Len(t, job.Errors, 1)
+	assert.Contains(t, job.Errors[0].Record, "INVALID")
+
+	status, _ := request(t, http.MethodGet, apiPrefix+"/IMPTNZ22XXX", "")
+	assert.Equal(t, http.StatusOK, status)
+}
+
+func TestImportErrors(t *testing.T) {
+	csv := "COUNTRY ISO2 CODE,SWIFT CODE,CODE TYPE,NAME,ADDRESS,TOWN NAME,COUNTRY NAME,TIME ZONE\n"
+	tests := []struct {
+		name     string
+		key      string
+		filename string
+		fields   map[string]string
+		status   int
+		output   string
+	}{
+		{"missing key", "", "directory.csv", nil, http.StatusUnauthorized, `{"message":"Invalid API key"}`},
+		{"wrong key", "wrong", "directory.csv", nil, http.StatusUnauthorized, `{"message":"Invalid API key"}`},
+		{"missing file", adminKey, "", nil, http.StatusBadRequest, `{"message":"file is required"}`},
+		{"unsupported file", adminKey, "directory.pdf", nil, http.StatusBadRequest, `{"message":"file must be an XLSX or CSV file"}`},
+		{"invalid validFrom", adminKey, "directory.csv", map[string]string{"validFrom": "yesterday"}, http.StatusBadRequest, `{"message":"validFrom must be a YYYY-MM-DD date"}`},
+		{"invalid partial", adminKey, "directory.csv", map[string]string{"partial": "maybe"}, http.StatusBadRequest, `{"message":"partial must be true or false"}`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := upload(t, e, tc.key, tc.filename, csv, tc.fields)
+			assert.Equal(t, tc.status, rec.Code)
+			assert.JSONEq(t, tc.output, rec.Body.String())
+		})
+	}
+
+	status, body := requestWithHeaders(t, http.MethodGet, "/v1/imports/UNKNOWN", "", map[string]string{"X-API-Key": adminKey})
+	assert.Equal(t, http.StatusNotFound, status)
+	assert.Equal(t, `{"message":"Not Found"}`, body)
+}
+
+func TestImportTooLarge(t *testing.T) {
+	req := uploadRequest(t, "directory.csv", "", nil)
+	req.Header.Set("X-API-Key", adminKey)
+	req.ContentLength = 101 << 20
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+}
+
+func TestImportDisabled(t *testing.T) {
+	disabled := echo.New()
+	h := handler.New(database.NewMemory(), handler.Config{})
+	h.Register(disabled)
+
+	rec := upload(t, disabled, "", "directory.csv", "", nil)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.JSONEq(t, `{"message":"Admin API is disabled"}`, rec.Body.String())
+}
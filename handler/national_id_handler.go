@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rtsncs/remitly-swift-api/models"
+)
+
+type responseNationalID struct {
+	XMLName    xml.Name      `json:"-" xml:"nationalId"`
+	Scheme     string        `json:"scheme" xml:"scheme"`
+	Identifier string        `json:"identifier" xml:"identifier"`
+	SwiftCodes []decodedCode `json:"swiftCodes" xml:"swiftCodes>swiftCode"`
+}
+
+// GetByNationalID resolves a UK sort code, US ABA routing number or German
+// BLZ to the SWIFT codes mapped to it.
+func (h *Handler) GetByNationalID(c echo.Context) error {
+	scheme := strings.ToLower(c.Param("scheme"))
+	format, err := negotiateFormat(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := models.ParseNationalID(scheme, c.Param("id"))
+	if err != nil {
+		if errors.Is(err, models.ErrUnknownScheme) {
+			return echo.NewHTTPError(http.StatusBadRequest, "Scheme must be one of sort-code, aba or blz")
+		}
+		return echo.NewHTTPError(http.StatusBadRequest, "Identifier "+err.Error())
+	}
+
+	codes, err := h.db.GetByNationalID(c.Request().Context(), scheme, id)
+	if err != nil {
+		return err
+	}
+	if len(codes) == 0 {
+		return echo.NewHTTPError(http.StatusNotFound)
+	}
+
+	response := responseNationalID{Scheme: scheme, Identifier: id, SwiftCodes: decodeAll(codes)}
+	return respond(c, format, http.StatusOK, response, codes)
+}
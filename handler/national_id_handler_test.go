@@ -0,0 +1,62 @@
+package handler_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetByNationalID(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		status int
+		output string
+	}{
+		{
+			name:   "routing number",
+			input:  "/aba/021000021",
+			status: http.StatusOK,
+			output: `{"scheme":"aba","identifier":"021000021","swiftCodes":[{"address":"1 Branch Street","bankName":"Bank Branch","countryISO2":"US","countryName":"UNITED STATES","isHeadquarter":false,"isPassive":false,"isReverseBilling":false,"isTest":false,"swiftCode":"BANKUS33ABC","bic":{"institution":"BANK","country":"US","location":"33","branch":"ABC"}},{"address":"1 HQ Street","bankName":"Bank HQ","countryISO2":"US","countryName":"UNITED STATES","isHeadquarter":true,"isPassive":false,"isReverseBilling":false,"isTest":false,"swiftCode":"BANKUS33XXX","bic":{"institution":"BANK","country":"US","location":"33","branch":"XXX"}}]}`,
+		},
+		{
+			name:   "bad check digit",
+			input:  "/aba/021000022",
+			status: http.StatusBadRequest,
+			output: `{"message":"Identifier has an invalid check digit"}`,
+		},
+		{
+			name:   "sort code with dashes",
+			input:  "/sort-code/60-16-13",
+			status: http.StatusOK,
+			output: `{"scheme":"sort-code","identifier":"601613","swiftCodes":[{"address":"","bankName":"Bank GB","countryISO2":"GB","countryName":"UNITED KINGDOM","isHeadquarter":true,"isPassive":false,"isReverseBilling":false,"isTest":true,"swiftCode":"BANKGB20XXX","bic":{"institution":"BANK","country":"GB","location":"20","branch":"XXX"}}]}`,
+		},
+		{
+			name:   "unknown sort code",
+			input:  "/sort-code/000000",
+			status: http.StatusNotFound,
+			output: `{"message":"Not Found"}`,
+		},
+		{
+			name:   "bad length",
+			input:  "/blz/3704004",
+			status: http.StatusBadRequest,
+			output: `{"message":"Identifier must be 8 digits long"}`,
+		},
+		{
+			name:   "unknown scheme",
+			input:  "/iban/021000021",
+			status: http.StatusBadRequest,
+			output: `{"message":"Scheme must be one of sort-code, aba or blz"}`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			status, body := request(t, http.MethodGet, apiPrefix+"/by-national-id"+tc.input, "")
+			assert.Equal(t, tc.status, status)
+			assert.Equal(t, tc.output, body)
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"encoding/csv"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rtsncs/remitly-swift-api/models"
+)
+
+const (
+	formatJSON = "json"
+	formatCSV  = "csv"
+	formatXML  = "xml"
+)
+
+var mediaTypes = map[string]string{
+	echo.MIMEApplicationJSON: formatJSON,
+	"text/csv":               formatCSV,
+	echo.MIMEApplicationXML:  formatXML,
+	echo.MIMETextXML:         formatXML,
+}
+
+var csvHeader = []string{"swiftCode", "bankName", "address", "countryISO2", "countryName", "isHeadquarter", "isTest", "isPassive", "isReverseBilling"}
+
+// negotiateFormat picks the response format from the format query parameter,
+// falling back to the Accept header and finally to JSON.
+func negotiateFormat(c echo.Context) (string, error) {
+	if format := strings.ToLower(c.QueryParam("format")); format != "" {
+		switch format {
+		case formatJSON, formatCSV, formatXML:
+			return format, nil
+		}
+		return "", echo.NewHTTPError(http.StatusNotAcceptable)
+	}
+
+	accept := c.Request().Header.Get(echo.HeaderAccept)
+	if accept == "" {
+		return formatJSON, nil
+	}
+
+	type mediaRange struct {
+		mediaType string
+		q         float64
+	}
+	var ranges []mediaRange
+	for _, part := range strings.Split(accept, ",") {
+		params := strings.Split(part, ";")
+		r := mediaRange{strings.ToLower(strings.TrimSpace(params[0])), 1}
+		for _, param := range params[1:] {
+			if v, ok := strings.CutPrefix(strings.TrimSpace(param), "q="); ok {
+				if q, err := strconv.ParseFloat(v, 64); err == nil {
+					r.q = q
+				}
+			}
+		}
+		if r.q > 0 {
+			ranges = append(ranges, r)
+		}
+	}
+	sort.SliceStable(ranges, func(i, j int) bool { return ranges[i].q > ranges[j].q })
+
+	for _, r := range ranges {
+		switch r.mediaType {
+		case "*/*", "application/*":
+			return formatJSON, nil
+		case "text/*":
+			return formatCSV, nil
+		}
+		if format, ok := mediaTypes[r.mediaType]; ok {
+			return format, nil
+		}
+	}
+
+	return "", echo.NewHTTPError(http.StatusNotAcceptable)
+}
+
+// respond writes v in the given format. CSV has no nesting, so it is
+// written from the flat list of codes instead.
+func respond(c echo.Context, format string, status int, v any, codes []models.SwiftCode) error {
+	c.Response().Header().Add(echo.HeaderVary, echo.HeaderAccept)
+
+	switch format {
+	case formatXML:
+		return c.XML(status, v)
+	case formatCSV:
+		res := c.Response()
+		res.Header().Set(echo.HeaderContentType, "text/csv; charset=UTF-8")
+		res.WriteHeader(status)
+
+		w := csv.NewWriter(res)
+		w.Write(csvHeader)
+		for _, code := range codes {
+			w.Write([]string{
+				code.SwiftCode,
+				code.BankName,
+				code.Address,
+				code.CountryISO2,
+				code.CountryName,
+				strconv.FormatBool(code.IsHeadquarter),
+				strconv.FormatBool(code.IsTest),
+				strconv.FormatBool(code.IsPassive),
+				strconv.FormatBool(code.IsReverseBilling),
+			})
+		}
+		w.Flush()
+		return w.Error()
+	default:
+		return c.JSON(status, v)
+	}
+}
@@ -0,0 +1,261 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func loadSpec(t *testing.T) (*openapi3.T, routers.Router) {
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	loader := openapi3.NewLoader()
+	doc, err := loader.LoadFromData(rec.Body.Bytes())
+	require.NoError(t, err)
+	require.NoError(t, doc.Validate(loader.Context))
+
+	router, err := gorillamux.NewRouter(doc)
+	require.NoError(t, err)
+	return doc, router
+}
+
+func TestOpenAPIDocumentsAllRoutes(t *testing.T) {
+	_, router := loadSpec(t)
+
+	for _, r := range e.Routes() {
+		if strings.HasPrefix(r.Path, "/*") || r.Method == echo.RouteNotFound {
+			continue
+		}
+		path := r.Path
+		for _, segment := range strings.Split(r.Path, "/") {
+			if strings.HasPrefix(segment, ":") {
+				path = strings.Replace(path, segment, "TESTPARAM", 1)
+			}
+		}
+		req := httptest.NewRequest(r.Method, path, nil)
+		_, _, err := router.FindRoute(req)
+		assert.NoError(t, err, "route %s %s is not documented", r.Method, r.Path)
+	}
+}
+
+// newerFields are the code fields added since the first version of the API.
+// Every operation whose responses document one of them has to return it in
+// some contract case, so that its schema is checked against real output.
+var newerFields = []string{"bic", "isTest", "isPassive", "isReverseBilling", "validFrom", "validTo", "suggestions"}
+
+// schemaFields adds the names of the properties schema declares, at any depth,
+// to fields.
+func schemaFields(schema *openapi3.SchemaRef, fields map[string]bool, visited map[*openapi3.Schema]bool) {
+	if schema == nil || schema.Value == nil || visited[schema.Value] {
+		return
+	}
+	visited[schema.Value] = true
+	for name, property := range schema.Value.Properties {
+		fields[name] = true
+		schemaFields(property, fields, visited)
+	}
+	schemaFields(schema.Value.Items, fields, visited)
+	schemaFields(schema.Value.AdditionalProperties.Schema, fields, visited)
+	for _, group := range []openapi3.SchemaRefs{schema.Value.OneOf, schema.Value.AnyOf, schema.Value.AllOf} {
+		for _, s := range group {
+			schemaFields(s, fields, visited)
+		}
+	}
+}
+
+// jsonFields adds the keys of the objects in a decoded JSON value, at any
+// depth, to fields.
+func jsonFields(value any, fields map[string]bool) {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, field := range v {
+			fields[key] = true
+			jsonFields(field, fields)
+		}
+	case []any:
+		for _, item := range v {
+			jsonFields(item, fields)
+		}
+	}
+}
+
+// takesParameter reports whether op, or the path it belongs to, takes the
+// named parameter.
+func takesParameter(item *openapi3.PathItem, op *openapi3.Operation, name string) bool {
+	for _, params := range []openapi3.Parameters{item.Parameters, op.Parameters} {
+		if params.GetByInAndName(openapi3.ParameterInQuery, name) != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func TestOpenAPIContract(t *testing.T) {
+	doc, router := loadSpec(t)
+
+	uploadReq := uploadRequest(t, "directory.csv", "COUNTRY ISO2 CODE,SWIFT CODE,CODE TYPE,NAME,ADDRESS,TOWN NAME,COUNTRY NAME,TIME ZONE\n", nil)
+	uploadBody, err := io.ReadAll(uploadReq.Body)
+	require.NoError(t, err)
+	upload := string(uploadBody)
+
+	tests := []struct {
+		method string
+		path   string
+		body   string
+		accept string
+		status int
+	}{
+		{http.MethodGet, "/openapi.json", "", "", http.StatusOK},
+		{http.MethodGet, "/livez", "", "", http.StatusOK},
+		{http.MethodGet, "/readyz", "", "", http.StatusOK},
+		{http.MethodGet, apiPrefix + "/BANKUS33XXX", "", "", http.StatusOK},
+		{http.MethodGet, apiPrefix + "/BANKUS33ABC", "", "", http.StatusOK},
+		{http.MethodGet, apiPrefix + "/BANKUS33ABC", "", "text/csv", http.StatusOK},
+		{http.MethodGet, apiPrefix + "/BANKUS33ABC", "", "text/html", http.StatusNotAcceptable},
+		{http.MethodGet, apiPrefix + "/NONEXISTENT", "", "", http.StatusNotFound},
+		{http.MethodGet, apiPrefix + "/BANKUS33DEF", "", "", http.StatusNotFound},
+		{http.MethodGet, apiPrefix + "/BANKUS33DEF", "", "text/csv", http.StatusNotFound},
+		{http.MethodGet, apiPrefix + "/GONEUS33XXX?asOf=2023-12-31", "", "", http.StatusOK},
+		{http.MethodGet, apiPrefix + "/GONEUS33XXX?asOf=2023-13-01", "", "", http.StatusBadRequest},
+		{http.MethodGet, apiPrefix + "/country/US", "", "", http.StatusOK},
+		{http.MethodGet, apiPrefix + "/country/US?format=csv", "", "", http.StatusOK},
+		{http.MethodGet, apiPrefix + "/country/XX", "", "", http.StatusNotFound},
+		{http.MethodGet, apiPrefix + "/country/GB?isTest=false&isPassive=true", "", "", http.StatusOK},
+		{http.MethodGet, apiPrefix + "/country/GB?isTest=maybe", "", "", http.StatusBadRequest},
+		{http.MethodGet, apiPrefix + "/country/US?asOf=2023-12-31", "", "", http.StatusOK},
+		{http.MethodGet, apiPrefix + "/by-national-id/aba/021000021", "", "", http.StatusOK},
+		{http.MethodGet, apiPrefix + "/by-national-id/aba/021000021", "", "text/csv", http.StatusOK},
+		{http.MethodGet, apiPrefix + "/by-national-id/aba/021000022", "", "", http.StatusBadRequest},
+		{http.MethodGet, apiPrefix + "/by-national-id/blz/37040044", "", "", http.StatusNotFound},
+		{http.MethodGet, apiPrefix + "/by-national-id/blz/50070010", "", "", http.StatusOK},
+		{http.MethodGet, apiPrefix + "/autocomplete?prefix=bank&limit=3", "", "", http.StatusOK},
+		{http.MethodGet, apiPrefix + "/autocomplete?prefix=bank", "", "text/csv", http.StatusOK},
+		{http.MethodGet, apiPrefix + "/autocomplete?prefix=bank&isTest=false&asOf=2024-01-01", "", "", http.StatusOK},
+		{http.MethodGet, apiPrefix + "/autocomplete?prefix=bank", "", "text/html", http.StatusNotAcceptable},
+		{http.MethodGet, apiPrefix + "/autocomplete", "", "", http.StatusBadRequest},
+		{http.MethodGet, apiPrefix + "/export?format=json&country=US", "", "", http.StatusOK},
+		{http.MethodGet, apiPrefix + "/export?format=csv", "", "", http.StatusOK},
+		{http.MethodGet, apiPrefix + "/export?format=json&country=DE", "", "", http.StatusOK},
+		{http.MethodGet, "/v1/iban/PL61109010140000071219812874", "", "", http.StatusOK},
+		{http.MethodGet, "/v1/iban/PL61109010140000071219812874", "", "text/csv", http.StatusOK},
+		{http.MethodGet, "/v1/iban/PL61109010140000071219812875", "", "", http.StatusBadRequest},
+		{http.MethodGet, "/v1/iban/DE89370400440532013000", "", "", http.StatusNotFound},
+		{http.MethodGet, "/v1/iban/DE94500700100123456789", "", "", http.StatusOK},
+		{http.MethodPost, "/graphql", `{"query":"{ swiftCode(code: \"BANKUS33XXX\") { bankName branches { swiftCode } } }"}`, "", http.StatusOK},
+		{http.MethodPost, "/graphql", `{"query":"{ unknown }"}`, "", http.StatusOK},
+		{http.MethodPost, "/graphql", `{}`, "", http.StatusBadRequest},
+		{http.MethodPost, apiPrefix, `{"bankName":"Contract Bank","address":"","countryISO2":"FR","countryName":"France","isHeadquarter":true,"swiftCode":"CONTFRPPXXX"}`, "", http.StatusCreated},
+		{http.MethodPost, apiPrefix, `{"bankName":"Contract Bank","address":"","countryISO2":"FR","countryName":"France","isHeadquarter":true,"swiftCode":"CONTFRPPXXX"}`, "", http.StatusConflict},
+		{http.MethodPost, apiPrefix, `{"bankName":"Contract Bank","countryISO2":"FR","countryName":"France","isHeadquarter":true,"swiftCode":"INVALID"}`, "", http.StatusBadRequest},
+		{http.MethodPut, apiPrefix + "/CONTFRPPXXX", `{"bankName":"Contract Bank SA","address":"","countryISO2":"FR","countryName":"France","isHeadquarter":true}`, "", http.StatusOK},
+		{http.MethodPut, apiPrefix + "/NONEFRPPXXX", `{"bankName":"Contract Bank","address":"","countryISO2":"FR","countryName":"France","isHeadquarter":true}`, "", http.StatusNotFound},
+		{http.MethodDelete, apiPrefix + "/CONTFRPPXXX", "", "", http.StatusOK},
+		{http.MethodDelete, apiPrefix + "/CONTFRPPXXX", "", "", http.StatusNotFound},
+		{http.MethodGet, "/v1/changes", "", "", http.StatusOK},
+		{http.MethodGet, "/v1/changes?since=1&limit=2", "", "", http.StatusOK},
+		{http.MethodGet, "/v1/changes?since=last", "", "", http.StatusBadRequest},
+		{http.MethodPost, "/v1/imports", upload, "", http.StatusAccepted},
+		{http.MethodGet, "/v1/imports/UNKNOWN", "", "", http.StatusNotFound},
+	}
+
+	exercised := map[*openapi3.Operation]bool{}
+	returned := map[*openapi3.Operation]map[string]bool{}
+	for _, tc := range tests {
+		t.Run(tc.method+" "+tc.path, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+			if tc.body == upload {
+				req.Header.Set(echo.HeaderContentType, uploadReq.Header.Get(echo.HeaderContentType))
+			} else if tc.body != "" {
+				req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			}
+			req.Header.Set("X-API-Key", adminKey)
+			if tc.method == http.MethodPut || tc.method == http.MethodDelete {
+				req.Header.Set("If-Match", "*")
+			}
+			if tc.accept != "" {
+				req.Header.Set(echo.HeaderAccept, tc.accept)
+			}
+
+			route, pathParams, err := router.FindRoute(req)
+			require.NoError(t, err)
+			exercised[route.Operation] = true
+
+			input := &openapi3filter.RequestValidationInput{
+				Request:    req,
+				PathParams: pathParams,
+				Route:      route,
+				Options: &openapi3filter.Options{
+					IncludeResponseStatus: true,
+					AuthenticationFunc:    openapi3filter.NoopAuthenticationFunc,
+				},
+			}
+			if tc.status < http.StatusBadRequest {
+				assert.NoError(t, openapi3filter.ValidateRequest(context.Background(), input))
+			}
+
+			rec := httptest.NewRecorder()
+			req.Body = io.NopCloser(strings.NewReader(tc.body))
+			e.ServeHTTP(rec, req)
+			assert.Equal(t, tc.status, rec.Code)
+
+			err = openapi3filter.ValidateResponse(context.Background(), &openapi3filter.ResponseValidationInput{
+				RequestValidationInput: input,
+				Status:                 rec.Code,
+				Header:                 rec.Header(),
+				Body:                   io.NopCloser(bytes.NewReader(rec.Body.Bytes())),
+				Options:                input.Options,
+			})
+			assert.NoError(t, err)
+
+			if strings.HasPrefix(rec.Header().Get(echo.HeaderContentType), echo.MIMEApplicationJSON) {
+				var body any
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+				if returned[route.Operation] == nil {
+					returned[route.Operation] = map[string]bool{}
+				}
+				jsonFields(body, returned[route.Operation])
+			}
+		})
+	}
+
+	for path, item := range doc.Paths.Map() {
+		for method, op := range item.Operations() {
+			assert.True(t, exercised[op], "operation %s %s is not covered", method, path)
+
+			documented := map[string]bool{}
+			visited := map[*openapi3.Schema]bool{}
+			for _, response := range op.Responses.Map() {
+				if response.Value == nil {
+					continue
+				}
+				if content := response.Value.Content.Get(echo.MIMEApplicationJSON); content != nil {
+					schemaFields(content.Schema, documented, visited)
+				}
+			}
+			for _, field := range newerFields {
+				// Only past records are retired, and only asOf looks them up.
+				if field == "validTo" && !takesParameter(item, op, "asOf") {
+					continue
+				}
+				if documented[field] {
+					assert.True(t, returned[op][field], "operation %s %s never returned %s", method, path, field)
+				}
+			}
+		}
+	}
+}
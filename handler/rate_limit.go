@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rtsncs/remitly-swift-api/ratelimit"
+)
+
+// Route groups limited separately.
+const (
+	LimitLookup = "lookup"
+	LimitWrite  = "write"
+	LimitAdmin  = "admin"
+)
+
+// RateLimits are the limits of the requests of each client, which is told
+// apart by its API key or IP address.
+type RateLimits struct {
+	// Store keeps the buckets of the clients. Nothing is limited when it's
+	// nil.
+	Store  ratelimit.Store
+	Lookup ratelimit.Limit
+	Write  ratelimit.Limit
+	Admin  ratelimit.Limit
+	// Keys override the limits of clients sending one of the API keys in
+	// the X-API-Key header, for every route group.
+	Keys map[string]ratelimit.Limit
+}
+
+func (l RateLimits) group(group string) ratelimit.Limit {
+	switch group {
+	case LimitLookup:
+		return l.Lookup
+	case LimitWrite:
+		return l.Write
+	case LimitAdmin:
+		return l.Admin
+	}
+	return ratelimit.Limit{}
+}
+
+// rateLimit rejects requests of clients that used up their limit of the
+// route group with 429. Responses tell clients about their limit in the
+// RateLimit headers.
+func (h *Handler) rateLimit(group string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			limit, result, err := h.Take(c.Request().Context(), group, c.Request().Header.Get(headerAPIKey), c.RealIP())
+			if err != nil {
+				// A failing store shouldn't take the API down with it.
+				c.Logger().Errorf("Rate limit store failed: %v", err)
+				return next(c)
+			}
+			if limit.IsZero() {
+				return next(c)
+			}
+
+			header := c.Response().Header()
+			header.Set("RateLimit-Limit", strconv.Itoa(limit.Requests))
+			header.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+			header.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+			header.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d", limit.Requests, ceilSeconds(limit.Per)))
+			if !result.Allowed {
+				header.Set(echo.HeaderRetryAfter, strconv.Itoa(ceilSeconds(result.RetryAfter)))
+				return echo.NewHTTPError(http.StatusTooManyRequests, "Too many requests")
+			}
+			return next(c)
+		}
+	}
+}
+
+// Take takes a token from the bucket of the client sending the API key from
+// the IP address in the route group. Known API keys may have limits of their
+// own. The limit is zero when the client isn't limited.
+func (h *Handler) Take(c context.Context, group, key, ip string) (ratelimit.Limit, ratelimit.Result, error) {
+	limit, ok := h.limits.Keys[key]
+	if !ok {
+		limit = h.limits.group(group)
+	}
+	if h.limits.Store == nil || limit.IsZero() {
+		return ratelimit.Limit{}, ratelimit.Result{}, nil
+	}
+	result, err := h.limits.Store.Take(c, group+":"+h.ClientID(key, ip), limit, time.Now())
+	if err != nil {
+		return ratelimit.Limit{}, ratelimit.Result{}, err
+	}
+	return limit, result, nil
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,89 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rtsncs/remitly-swift-api/database"
+	"github.com/rtsncs/remitly-swift-api/handler"
+	"github.com/rtsncs/remitly-swift-api/ratelimit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimit(t *testing.T) {
+	e := echo.New()
+	h := handler.New(database.NewMemory(), handler.Config{
+		AdminAPIKey: adminKey,
+		RateLimits: handler.RateLimits{
+			Store:  ratelimit.NewMemory(),
+			Lookup: ratelimit.Limit{Requests: 2, Per: time.Minute},
+			Write:  ratelimit.Limit{Requests: 1, Per: time.Minute},
+			Admin:  ratelimit.Limit{Requests: 1, Per: time.Minute},
+			Keys:   map[string]ratelimit.Limit{"batch-key": {Requests: 5, Per: time.Minute}},
+		},
+	})
+	h.Register(e)
+
+	send := func(method, path, ip, key string) *httptest.ResponseRecorder {
+		var body string
+		if method == http.MethodPost {
+			body = `{"bankName":"Bank","address":"","countryISO2":"FR","countryName":"France","isHeadquarter":true,"swiftCode":"RATEFRPPXXX"}`
+		}
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set(echo.HeaderXRealIP, ip)
+		if key != "" {
+			req.Header.Set("X-API-Key", key)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	for remaining := 1; remaining >= 0; remaining-- {
+		rec := send(http.MethodGet, apiPrefix+"/NONEFRPPXXX", "192.0.2.1", "")
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Equal(t, "2", rec.Header().Get("RateLimit-Limit"))
+		assert.Equal(t, strconv.Itoa(remaining), rec.Header().Get("RateLimit-Remaining"))
+		assert.Equal(t, "2;w=60", rec.Header().Get("RateLimit-Policy"))
+	}
+	rec := send(http.MethodGet, apiPrefix+"/NONEFRPPXXX", "192.0.2.1", "")
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.JSONEq(t, `{"message":"Too many requests"}`, rec.Body.String())
+	assert.Equal(t, "30", rec.Header().Get(echo.HeaderRetryAfter))
+	assert.Equal(t, "0", rec.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "60", rec.Header().Get("RateLimit-Reset"))
+
+	// Made up API keys don't get around the limit of the IP address.
+	rec = send(http.MethodGet, apiPrefix+"/NONEFRPPXXX", "192.0.2.1", "made-up-key")
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+
+	// Other clients, route groups and known keys have limits of their own.
+	rec = send(http.MethodGet, apiPrefix+"/NONEFRPPXXX", "192.0.2.2", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	rec = send(http.MethodPost, apiPrefix, "192.0.2.1", "")
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, "1", rec.Header().Get("RateLimit-Limit"))
+	rec = send(http.MethodGet, apiPrefix+"/NONEFRPPXXX", "192.0.2.1", "batch-key")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, "5", rec.Header().Get("RateLimit-Limit"))
+	rec = send(http.MethodGet, "/v1/imports/UNKNOWN", "192.0.2.1", adminKey)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, "1", rec.Header().Get("RateLimit-Limit"))
+
+	// Wrong admin keys use up the limit of the IP address.
+	rec = send(http.MethodGet, "/v1/imports/UNKNOWN", "192.0.2.1", "wrong-key")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	rec = send(http.MethodGet, "/v1/imports/UNKNOWN", "192.0.2.1", "wrong-key")
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+
+	// Health checks aren't limited.
+	rec = send(http.MethodGet, "/livez", "192.0.2.1", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Header().Get("RateLimit-Limit"))
+}
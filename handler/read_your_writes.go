@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rtsncs/remitly-swift-api/database"
+)
+
+const (
+	cookieReadPrimary = "read_primary"
+	headerReadPrimary = "X-Read-Primary"
+)
+
+// readYourWrites sends writes, and lookups of clients that have just made
+// one, to the primary database, which read replicas may lag behind. Writes
+// set a cookie pinning the client to the primary for the read-your-writes
+// window; clients can also ask for the primary with the X-Read-Primary
+// header.
+func (h *Handler) readYourWrites(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		req := c.Request()
+		write := req.Method != http.MethodGet && req.Method != http.MethodHead
+		_, err := c.Cookie(cookieReadPrimary)
+		pinned := err == nil
+		if write || pinned || req.Header.Get(headerReadPrimary) == "true" {
+			c.SetRequest(req.WithContext(database.WithPrimary(req.Context())))
+		}
+		if write && h.primaryPin > 0 {
+			c.SetCookie(&http.Cookie{
+				Name:     cookieReadPrimary,
+				Value:    "1",
+				Path:     "/",
+				MaxAge:   int((h.primaryPin + time.Second - 1) / time.Second),
+				HttpOnly: true,
+				SameSite: http.SameSiteLaxMode,
+			})
+		}
+		return next(c)
+	}
+}
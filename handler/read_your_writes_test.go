@@ -0,0 +1,66 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rtsncs/remitly-swift-api/database"
+	"github.com/rtsncs/remitly-swift-api/handler"
+	"github.com/rtsncs/remitly-swift-api/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// routingStore records whether lookups of a code were sent to the primary.
+type routingStore struct {
+	database.Store
+	primary *bool
+}
+
+func (s routingStore) GetByCodeAsOf(c context.Context, code string, asOf time.Time) (models.SwiftCode, error) {
+	*s.primary = database.ReadsPrimary(c)
+	return s.Store.GetByCodeAsOf(c, code, asOf)
+}
+
+func TestReadYourWrites(t *testing.T) {
+	var primary bool
+	e := echo.New()
+	h := handler.New(routingStore{database.NewMemory(), &primary}, handler.Config{ReadYourWrites: 1500 * time.Millisecond})
+	h.Register(e)
+
+	get := func(header, cookie string) {
+		req := httptest.NewRequest(http.MethodGet, apiPrefix+"/RYOWFRPPXXX", nil)
+		if header != "" {
+			req.Header.Set("X-Read-Primary", header)
+		}
+		if cookie != "" {
+			req.Header.Set("Cookie", cookie)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, rec.Header().Values("Set-Cookie"))
+	}
+
+	req := httptest.NewRequest(http.MethodPost, apiPrefix, strings.NewReader(`{"bankName":"Bank","address":"","countryISO2":"FR","countryName":"France","isHeadquarter":true,"swiftCode":"RYOWFRPPXXX"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusCreated, rec.Code)
+	cookies := rec.Result().Cookies()
+	require.Len(t, cookies, 1)
+	assert.Equal(t, "read_primary", cookies[0].Name)
+	assert.Equal(t, 2, cookies[0].MaxAge)
+
+	get("", "")
+	assert.False(t, primary)
+	get("", cookies[0].Name+"="+cookies[0].Value)
+	assert.True(t, primary)
+	get("true", "")
+	assert.True(t, primary)
+}
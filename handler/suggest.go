@@ -0,0 +1,174 @@
+package handler
+
+import (
+	"context"
+	"encoding/xml"
+	"slices"
+	"strings"
+
+	"github.com/rtsncs/remitly-swift-api/database"
+	"github.com/rtsncs/remitly-swift-api/models"
+)
+
+const (
+	maxSuggestions = 10
+	// maxSuggestionDistance is the largest edit distance between the
+	// requested code and a similar code.
+	maxSuggestionDistance = 2
+	// suggestionCandidates caps the codes fetched per prefix lookup.
+	suggestionCandidates = 100
+)
+
+// Reasons for suggesting a code.
+const (
+	reasonHeadquarter = "headquarter"
+	reasonSameBIC8    = "same-bic8"
+	reasonSimilar     = "similar"
+)
+
+type suggestion struct {
+	SwiftCode string `json:"swiftCode" xml:"swiftCode"`
+	BankName  string `json:"bankName" xml:"bankName"`
+	Reason    string `json:"reason" xml:"reason"`
+	// code is the suggested record, written out in full as a CSV row.
+	code models.SwiftCode
+}
+
+type notFoundResponse struct {
+	XMLName     xml.Name     `json:"-" xml:"notFound"`
+	Message     string       `json:"message" xml:"message"`
+	Suggestions []suggestion `json:"suggestions,omitempty" xml:"suggestion,omitempty"`
+}
+
+// confusables are characters commonly typed in place of each other.
+var confusables = map[byte]byte{'O': '0', '0': 'O', 'I': '1', '1': 'I'}
+
+// suggest lists known codes close to a code that doesn't exist: the
+// headquarter and other branches sharing its BIC8, then codes within a small
+// edit distance. Similar codes are only looked for among codes sharing the
+// institution prefix, or a variant of it with common typos fixed, so that
+// the lookup can use the prefix index.
+func (h *Handler) suggest(c context.Context, code string) ([]suggestion, error) {
+	code = strings.ToUpper(code)
+	if len(code) < 8 || len(code) > 11 {
+		return nil, nil
+	}
+	if !isAlphanumericString(code) {
+		return nil, nil
+	}
+
+	var suggestions []suggestion
+	seen := map[string]bool{code: true}
+
+	sameBIC8, err := h.db.GetByCodePrefix(c, code[:8], suggestionCandidates, database.Filter{})
+	if err != nil {
+		return nil, err
+	}
+	for _, candidate := range sameBIC8 {
+		if candidate.IsHeadquarter {
+			suggestions = append(suggestions, suggestion{candidate.SwiftCode, candidate.BankName, reasonHeadquarter, candidate})
+			seen[candidate.SwiftCode] = true
+		}
+	}
+	for _, candidate := range sameBIC8 {
+		if !seen[candidate.SwiftCode] {
+			suggestions = append(suggestions, suggestion{candidate.SwiftCode, candidate.BankName, reasonSameBIC8, candidate})
+			seen[candidate.SwiftCode] = true
+		}
+	}
+
+	target := code
+	if len(target) == 8 {
+		target += "XXX"
+	}
+	type similar struct {
+		code     models.SwiftCode
+		distance int
+	}
+	var similars []similar
+	for _, prefix := range typoVariants(code, 4) {
+		candidates, err := h.db.GetByCodePrefix(c, prefix, suggestionCandidates, database.Filter{})
+		if err != nil {
+			return nil, err
+		}
+		for _, candidate := range candidates {
+			if seen[candidate.SwiftCode] {
+				continue
+			}
+			seen[candidate.SwiftCode] = true
+			if d := editDistance(target, candidate.SwiftCode); d <= maxSuggestionDistance {
+				similars = append(similars, similar{candidate, d})
+			}
+		}
+	}
+	slices.SortStableFunc(similars, func(a, b similar) int { return a.distance - b.distance })
+	for _, s := range similars {
+		suggestions = append(suggestions, suggestion{s.code.SwiftCode, s.code.BankName, reasonSimilar, s.code})
+	}
+
+	if len(suggestions) > maxSuggestions {
+		suggestions = suggestions[:maxSuggestions]
+	}
+	return suggestions, nil
+}
+
+// typoVariants returns the first n characters of code followed by what they
+// could have been mistyped from: adjacent characters swapped, including the
+// n-th with the one after it, or O/0 and I/1 confused.
+func typoVariants(code string, n int) []string {
+	variants := []string{code[:n]}
+	add := func(b []byte) {
+		if v := string(b[:n]); !slices.Contains(variants, v) {
+			variants = append(variants, v)
+		}
+	}
+	for i := range min(n, len(code)-1) {
+		b := []byte(code)
+		b[i], b[i+1] = b[i+1], b[i]
+		add(b)
+	}
+	for i := range n {
+		if r, ok := confusables[code[i]]; ok {
+			b := []byte(code)
+			b[i] = r
+			add(b)
+		}
+	}
+	return variants
+}
+
+// editDistance is the optimal string alignment distance between a and b:
+// the number of insertions, deletions, substitutions and transpositions of
+// adjacent characters needed to turn one into the other.
+func editDistance(a, b string) int {
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(a)][len(b)]
+}
+
+func isAlphanumericString(s string) bool {
+	for i := range len(s) {
+		if !models.IsAlphanumeric(s[i]) {
+			return false
+		}
+	}
+	return true
+}
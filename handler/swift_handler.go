@@ -1,71 +1,161 @@
 package handler
 
 import (
+	"encoding/xml"
 	"errors"
 	"net/http"
+	"strings"
+	"time"
 
-	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/labstack/echo/v4"
+	"github.com/rtsncs/remitly-swift-api/database"
 	"github.com/rtsncs/remitly-swift-api/models"
 )
 
-type responseWithBranches struct {
+type decodedCode struct {
 	models.SwiftCode
-	Branches []models.SwiftCode `json:"branches"`
+	BIC *models.BIC `json:"bic,omitempty" xml:"bic,omitempty"`
+}
+
+type responseCode struct {
+	XMLName xml.Name `json:"-" xml:"swiftCode"`
+	decodedCode
+}
+
+type responseWithBranches struct {
+	XMLName xml.Name `json:"-" xml:"swiftCode"`
+	decodedCode
+	Branches []decodedCode `json:"branches" xml:"branches>swiftCode"`
 }
 
 type responseByCountry struct {
-	CountryISO2 string             `json:"countryISO2"`
-	CountryName string             `json:"countryName"`
-	SwiftCodes  []models.SwiftCode `json:"swiftCodes"`
+	XMLName     xml.Name      `json:"-" xml:"country"`
+	CountryISO2 string        `json:"countryISO2" xml:"countryISO2"`
+	CountryName string        `json:"countryName" xml:"countryName"`
+	SwiftCodes  []decodedCode `json:"swiftCodes" xml:"swiftCodes>swiftCode"`
+}
+
+func decode(code models.SwiftCode) decodedCode {
+	decoded := decodedCode{SwiftCode: code}
+	if bic, err := models.ParseBIC(code.SwiftCode); err == nil {
+		decoded.BIC = &bic
+	}
+	return decoded
+}
+
+func decodeAll(codes []models.SwiftCode) []decodedCode {
+	decoded := make([]decodedCode, len(codes))
+	for i, code := range codes {
+		decoded[i] = decode(code)
+	}
+	return decoded
 }
 
 func (h *Handler) GetCode(c echo.Context) error {
 	code := c.Param("code")
+	format, err := negotiateFormat(c)
+	if err != nil {
+		return err
+	}
+	asOf, err := parseAsOf(c)
+	if err != nil {
+		return err
+	}
 
-	codeDetails, err := h.db.GetByCode(c.Request().Context(), code)
+	codeDetails, err := h.db.GetByCodeAsOf(c.Request().Context(), code, asOf)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
+		if errors.Is(err, database.ErrNotFound) && !asOf.IsZero() {
 			return echo.NewHTTPError(http.StatusNotFound)
 		}
+		if errors.Is(err, database.ErrNotFound) {
+			suggestions, err := h.suggest(c.Request().Context(), code)
+			if err != nil {
+				c.Logger().Errorf("Suggesting codes failed: %v", err)
+				suggestions = nil
+			}
+			rows := make([]models.SwiftCode, len(suggestions))
+			for i, s := range suggestions {
+				rows[i] = s.code
+			}
+			response := notFoundResponse{Message: http.StatusText(http.StatusNotFound), Suggestions: suggestions}
+			return respond(c, format, http.StatusNotFound, response, rows)
+		}
 		return err
 	}
+	var branches []models.SwiftCode
 	if codeDetails.IsHeadquarter {
-		branches, err := h.db.GetBranches(c.Request().Context(), code)
-		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		branches, err = h.db.GetBranchesAsOf(c.Request().Context(), code, asOf)
+		if err != nil && !errors.Is(err, database.ErrNotFound) {
 			return err
 		}
+	}
+	codes := append([]models.SwiftCode{codeDetails}, branches...)
 
-		return c.JSON(http.StatusOK, responseWithBranches{SwiftCode: codeDetails, Branches: branches})
+	modified, err := h.db.LastModified(c.Request().Context(), codeDetails.CountryISO2, codePrefix(codeDetails))
+	if err != nil {
+		return err
+	}
+	if notModified(c, etag(format, codes), modified) {
+		return respondNotModified(c)
 	}
 
-	return c.JSON(http.StatusOK, codeDetails)
+	if codeDetails.IsHeadquarter {
+		response := responseWithBranches{decodedCode: decode(codeDetails), Branches: decodeAll(branches)}
+
+		rows := make([]models.SwiftCode, len(codes))
+		for i, code := range codes {
+			code.CountryName = codeDetails.CountryName
+			rows[i] = code
+		}
+		return respond(c, format, http.StatusOK, response, rows)
+	}
+	return respond(c, format, http.StatusOK, responseCode{decodedCode: decode(codeDetails)}, codes)
 }
 
 func (h *Handler) GetByCountryCode(c echo.Context) error {
 	countryCode := c.Param("countryCode")
+	format, err := negotiateFormat(c)
+	if err != nil {
+		return err
+	}
+	filter, err := parseFilter(c)
+	if err != nil {
+		return err
+	}
 
 	name, err := h.db.GetCountryName(c.Request().Context(), countryCode)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
+		if errors.Is(err, database.ErrNotFound) {
 			return echo.NewHTTPError(http.StatusNotFound)
 		}
 		return err
 	}
 
-	codes, err := h.db.GetByCountryCode(c.Request().Context(), countryCode)
+	codes, err := h.db.GetByCountryCode(c.Request().Context(), countryCode, filter)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
+		if errors.Is(err, database.ErrNotFound) {
 			codes = nil
 		} else {
 			return err
 		}
 	}
 
-	response := responseByCountry{countryCode, name, codes}
+	modified, err := h.db.LastModified(c.Request().Context(), countryCode, "")
+	if err != nil {
+		return err
+	}
+	if notModified(c, etag(format, codes), modified) {
+		return respondNotModified(c)
+	}
+
+	response := responseByCountry{CountryISO2: countryCode, CountryName: name, SwiftCodes: decodeAll(codes)}
 
-	return c.JSON(http.StatusOK, response)
+	rows := make([]models.SwiftCode, len(codes))
+	for i, code := range codes {
+		code.CountryName = name
+		rows[i] = code
+	}
+	return respond(c, format, http.StatusOK, response, rows)
 }
 
 func (h *Handler) AddCode(c echo.Context) error {
@@ -73,30 +163,110 @@ func (h *Handler) AddCode(c echo.Context) error {
 	if err := c.Bind(code); err != nil {
 		return err
 	}
+	if code.ValidFrom == "" {
+		code.ValidFrom = models.Today()
+	}
 	if err := code.Validate(); err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, err)
 	}
 	if err := h.db.InsertCode(c.Request().Context(), *code); err != nil {
-		var pgErr *pgconn.PgError
-		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		if errors.Is(err, database.ErrDuplicate) {
 			return echo.NewHTTPError(http.StatusConflict, "Swift code already exists")
 		}
 		return err
 	}
 
+	c.Response().Header().Set(echo.HeaderLocation, "/v1/swift-codes/"+code.SwiftCode)
+	h.setETag(c, code.SwiftCode)
 	return c.JSON(http.StatusCreated, genericResponse{http.StatusText(http.StatusCreated)})
 }
 
-func (h *Handler) DeleteCode(c echo.Context) error {
-	code := c.Param("code")
+// UpdateCode replaces the current record of a code with a new one, valid
+// from validFrom or today.
+func (h *Handler) UpdateCode(c echo.Context) error {
+	code := new(models.SwiftCode)
+	if err := c.Bind(code); err != nil {
+		return err
+	}
+	if code.SwiftCode == "" {
+		code.SwiftCode = c.Param("code")
+	}
+	if code.ValidFrom == "" {
+		code.ValidFrom = models.Today()
+	}
+	if err := code.Validate(); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+	if code.SwiftCode != strings.ToUpper(c.Param("code")) {
+		return echo.NewHTTPError(http.StatusBadRequest, "swiftCode doesn't match the path")
+	}
+	if code.ValidTo != "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "validTo can't be set by updates, delete the code instead")
+	}
 
-	count, err := h.db.DeleteByCode(c.Request().Context(), code)
+	stored, tags, err := h.current(c.Request().Context(), code.SwiftCode)
 	if err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound)
+		}
 		return err
 	}
-	if count == 0 {
-		return echo.NewHTTPError(http.StatusNotFound)
+	if err := checkIfMatch(c, tags); err != nil {
+		return err
+	}
+
+	err = h.db.ReplaceCode(c.Request().Context(), *code, stored.Version)
+	if errors.Is(err, database.ErrBackdated) {
+		return echo.NewHTTPError(http.StatusBadRequest, "validFrom can't be before that of the current record")
+	}
+	if err := writeError(err); err != nil {
+		return err
+	}
+
+	h.setETag(c, code.SwiftCode)
+	return c.JSON(http.StatusOK, genericResponse{http.StatusText(http.StatusOK)})
+}
+
+// setETag sets the ETag of the JSON representation of a code that was just
+// written, for the client's next write to be conditional on it. The write
+// has succeeded, so a failed lookup only leaves the header out.
+func (h *Handler) setETag(c echo.Context, code string) {
+	_, tags, err := h.current(c.Request().Context(), code)
+	if err != nil {
+		c.Logger().Errorf("Failed to look up written code: %v", err)
+		return
+	}
+	c.Response().Header().Set("ETag", tags[0])
+}
+
+func (h *Handler) DeleteCode(c echo.Context) error {
+	stored, tags, err := h.current(c.Request().Context(), c.Param("code"))
+	if err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound)
+		}
+		return err
+	}
+	if err := checkIfMatch(c, tags); err != nil {
+		return err
+	}
+
+	today, _ := time.Parse(models.DateLayout, models.Today())
+	if err := writeError(h.db.CloseByCodeAtVersion(c.Request().Context(), stored.SwiftCode, stored.Version, today)); err != nil {
+		return err
 	}
 
 	return c.JSON(http.StatusOK, genericResponse{http.StatusText(http.StatusOK)})
 }
+
+// writeError maps the errors of writes conditional on the version of the
+// stored record, which fail when it changed since it was read.
+func writeError(err error) error {
+	switch {
+	case errors.Is(err, database.ErrNotFound):
+		return echo.NewHTTPError(http.StatusNotFound)
+	case errors.Is(err, database.ErrVersionMismatch), errors.Is(err, database.ErrDuplicate):
+		return echo.NewHTTPError(http.StatusPreconditionFailed, "Swift code was changed")
+	}
+	return err
+}
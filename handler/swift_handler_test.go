@@ -0,0 +1,489 @@
+package handler_test
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rtsncs/remitly-swift-api/database"
+	"github.com/rtsncs/remitly-swift-api/handler"
+	"github.com/rtsncs/remitly-swift-api/models"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	e         *echo.Echo
+	apiPrefix = "/v1/swift-codes"
+	adminKey  = "test-admin-key"
+)
+
+func request(t *testing.T, method, path, body string) (int, string) {
+	return requestWithHeaders(t, method, path, body, nil)
+}
+
+func requestWithHeaders(t *testing.T, method, path, body string, headers map[string]string) (int, string) {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	bodyBytes, err := io.ReadAll(rec.Body)
+	assert.NoError(t, err, "failed to read response body")
+
+	return rec.Code, strings.Trim(string(bodyBytes), "\n")
+}
+
+func TestGetCode(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		output string
+		status int
+	}{
+		{
+			name:   "valid branch",
+			input:  "/BANKUS33ABC",
+			status: http.StatusOK,
+			output: `{"address":"1 Branch Street","bankName":"Bank Branch","countryISO2":"US","countryName":"UNITED STATES","isHeadquarter":false,"isPassive":false,"isReverseBilling":false,"isTest":false,"swiftCode":"BANKUS33ABC","bic":{"institution":"BANK","country":"US","location":"33","branch":"ABC"}}`,
+		},
+		{
+			name:   "valid headquarter",
+			input:  "/BANKUS33XXX",
+			status: http.StatusOK,
+			output: `{"address":"1 HQ Street","bankName":"Bank HQ","countryISO2":"US","countryName":"UNITED STATES","isHeadquarter":true,"isPassive":false,"isReverseBilling":false,"isTest":false,"swiftCode":"BANKUS33XXX","bic":{"institution":"BANK","country":"US","location":"33","branch":"XXX"},"branches":[{"address":"1 Branch Street","bankName":"Bank Branch","countryISO2":"US","isHeadquarter":false,"isPassive":false,"isReverseBilling":false,"isTest":false,"swiftCode":"BANKUS33ABC","bic":{"institution":"BANK","country":"US","location":"33","branch":"ABC"}}]}`,
+		},
+		{
+			name:   "valid headquarter no branches",
+			input:  "/BANKPLPWXXX",
+			status: http.StatusOK,
+			output: `{"address":"2 HQ Street","bankName":"Bank PL","countryISO2":"PL","countryName":"POLAND","isHeadquarter":true,"isPassive":false,"isReverseBilling":false,"isTest":false,"swiftCode":"BANKPLPWXXX","bic":{"institution":"BANK","country":"PL","location":"PW","branch":"XXX"},"branches":[]}`,
+		},
+		{
+			name:   "nonexistent code",
+			input:  "/NONEXISTENT",
+			status: http.StatusNotFound,
+			output: `{"message":"Not Found"}`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			status, body := request(t, http.MethodGet, apiPrefix+tc.input, "")
+			assert.Equal(t, tc.status, status)
+			assert.Equal(t, tc.output, body)
+		})
+	}
+}
+
+func TestGetCodeSuggestions(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		output string
+	}{
+		{
+			name:   "unknown branch",
+			input:  "/BANKUS33DEF",
+			output: `{"message":"Not Found","suggestions":[{"swiftCode":"BANKUS33XXX","bankName":"Bank HQ","reason":"headquarter"},{"swiftCode":"BANKUS33ABC","bankName":"Bank Branch","reason":"same-bic8"}]}`,
+		},
+		{
+			name:   "bic8",
+			input:  "/BANKPLPW",
+			output: `{"message":"Not Found","suggestions":[{"swiftCode":"BANKPLPWXXX","bankName":"Bank PL","reason":"headquarter"}]}`,
+		},
+		{
+			name:   "transposition in institution",
+			input:  "/BNAKPLPWXXX",
+			output: `{"message":"Not Found","suggestions":[{"swiftCode":"BANKPLPWXXX","bankName":"Bank PL","reason":"similar"}]}`,
+		},
+		{
+			name:   "letter O instead of zero",
+			input:  "/BANKGB2OXXX",
+			output: `{"message":"Not Found","suggestions":[{"swiftCode":"BANKGB20XXX","bankName":"Bank GB","reason":"similar"},{"swiftCode":"BANKGB21XXX","bankName":"Bank GB","reason":"similar"},{"swiftCode":"BANKGB22XXX","bankName":"Bank GB","reason":"similar"}]}`,
+		},
+		{
+			name:   "zero instead of letter O in location",
+			input:  "/BANKPL0WXXX",
+			output: `{"message":"Not Found","suggestions":[{"swiftCode":"BANKPLPWXXX","bankName":"Bank PL","reason":"similar"}]}`,
+		},
+		{
+			name:   "nothing close",
+			input:  "/ZZZZUS33XXX",
+			output: `{"message":"Not Found"}`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			status, body := request(t, http.MethodGet, apiPrefix+tc.input, "")
+			assert.Equal(t, http.StatusNotFound, status)
+			assert.Equal(t, tc.output, body)
+		})
+	}
+}
+
+func TestAsOf(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		status int
+		output string
+	}{
+		{
+			name:   "retired code",
+			input:  "/GONEUS33XXX",
+			status: http.StatusNotFound,
+			output: `{"message":"Not Found"}`,
+		},
+		{
+			name:   "retired code while valid",
+			input:  "/GONEUS33XXX?asOf=2023-12-31",
+			status: http.StatusOK,
+			output: `{"address":"","bankName":"Retired Bank","countryISO2":"US","countryName":"UNITED STATES","isHeadquarter":true,"isPassive":false,"isReverseBilling":false,"isTest":false,"swiftCode":"GONEUS33XXX","validFrom":"2020-01-01","validTo":"2024-01-01","bic":{"institution":"GONE","country":"US","location":"33","branch":"XXX"},"branches":[]}`,
+		},
+		{
+			name:   "retired code after end date",
+			input:  "/GONEUS33XXX?asOf=2024-01-01",
+			status: http.StatusNotFound,
+			output: `{"message":"Not Found"}`,
+		},
+		{
+			name:   "country while valid",
+			input:  "/country/US?asOf=2023-12-31&isTest=false",
+			status: http.StatusOK,
+			output: `{"countryISO2":"US","countryName":"UNITED STATES","swiftCodes":[{"address":"1 HQ Street","bankName":"Bank HQ","countryISO2":"US","isHeadquarter":true,"isPassive":false,"isReverseBilling":false,"isTest":false,"swiftCode":"BANKUS33XXX","bic":{"institution":"BANK","country":"US","location":"33","branch":"XXX"}},{"address":"1 Branch Street","bankName":"Bank Branch","countryISO2":"US","isHeadquarter":false,"isPassive":false,"isReverseBilling":false,"isTest":false,"swiftCode":"BANKUS33ABC","bic":{"institution":"BANK","country":"US","location":"33","branch":"ABC"}},{"address":"","bankName":"Retired Bank","countryISO2":"US","isHeadquarter":true,"isPassive":false,"isReverseBilling":false,"isTest":false,"swiftCode":"GONEUS33XXX","validFrom":"2020-01-01","validTo":"2024-01-01","bic":{"institution":"GONE","country":"US","location":"33","branch":"XXX"}}]}`,
+		},
+		{
+			name:   "invalid date",
+			input:  "/BANKUS33XXX?asOf=2023-13-01",
+			status: http.StatusBadRequest,
+			output: `{"message":"asOf must be a YYYY-MM-DD date"}`,
+		},
+		{
+			name:   "invalid date in country listing",
+			input:  "/country/US?asOf=yesterday",
+			status: http.StatusBadRequest,
+			output: `{"message":"asOf must be a YYYY-MM-DD date"}`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			status, body := request(t, http.MethodGet, apiPrefix+tc.input, "")
+			assert.Equal(t, tc.status, status)
+			assert.Equal(t, tc.output, body)
+		})
+	}
+}
+
+func TestGetByCountryCode(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		output string
+		status int
+	}{
+		{
+			name:   "valid country",
+			input:  "US",
+			status: http.StatusOK,
+			output: `{"countryISO2":"US","countryName":"UNITED STATES","swiftCodes":[{"address":"1 HQ Street","bankName":"Bank HQ","countryISO2":"US","isHeadquarter":true,"isPassive":false,"isReverseBilling":false,"isTest":false,"swiftCode":"BANKUS33XXX","bic":{"institution":"BANK","country":"US","location":"33","branch":"XXX"}},{"address":"1 Branch Street","bankName":"Bank Branch","countryISO2":"US","isHeadquarter":false,"isPassive":false,"isReverseBilling":false,"isTest":false,"swiftCode":"BANKUS33ABC","bic":{"institution":"BANK","country":"US","location":"33","branch":"ABC"}}]}`,
+		},
+		{
+			name:   "nonexistent country",
+			input:  "XX",
+			status: http.StatusNotFound,
+			output: `{"message":"Not Found"}`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			status, body := request(t, http.MethodGet, apiPrefix+"/country/"+tc.input, "")
+			assert.Equal(t, tc.status, status)
+			assert.Equal(t, tc.output, body)
+		})
+	}
+}
+
+func TestGetByCountryCodeFilter(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		output string
+		status int
+	}{
+		{
+			name:   "exclude test and passive",
+			input:  "GB?isTest=false&isPassive=false&format=csv",
+			status: http.StatusOK,
+			output: "swiftCode,bankName,address,countryISO2,countryName,isHeadquarter,isTest,isPassive,isReverseBilling\nBANKGB22XXX,Bank GB,,GB,UNITED KINGDOM,true,false,false,true",
+		},
+		{
+			name:   "only test",
+			input:  "GB?isTest=true&format=csv",
+			status: http.StatusOK,
+			output: "swiftCode,bankName,address,countryISO2,countryName,isHeadquarter,isTest,isPassive,isReverseBilling\nBANKGB20XXX,Bank GB,,GB,UNITED KINGDOM,true,true,false,false",
+		},
+		{
+			name:   "invalid filter",
+			input:  "GB?isPassive=maybe",
+			status: http.StatusBadRequest,
+			output: `{"message":"isPassive must be true or false"}`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			status, body := request(t, http.MethodGet, apiPrefix+"/country/"+tc.input, "")
+			assert.Equal(t, tc.status, status)
+			assert.Equal(t, tc.output, body)
+		})
+	}
+}
+
+func TestContentNegotiation(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		accept string
+		output string
+		status int
+	}{
+		{
+			name:   "csv headquarter",
+			input:  "/BANKUS33XXX",
+			accept: "text/csv",
+			status: http.StatusOK,
+			output: "swiftCode,bankName,address,countryISO2,countryName,isHeadquarter,isTest,isPassive,isReverseBilling\nBANKUS33XXX,Bank HQ,1 HQ Street,US,UNITED STATES,true,false,false,false\nBANKUS33ABC,Bank Branch,1 Branch Street,US,UNITED STATES,false,false,false,false",
+		},
+		{
+			name:   "xml branch",
+			input:  "/BANKUS33ABC",
+			accept: "application/xml",
+			status: http.StatusOK,
+			output: `<?xml version="1.0" encoding="UTF-8"?>` + "\n" + `<swiftCode><address>1 Branch Street</address><bankName>Bank Branch</bankName><countryISO2>US</countryISO2><countryName>UNITED STATES</countryName><isHeadquarter>false</isHeadquarter><isPassive>false</isPassive><isReverseBilling>false</isReverseBilling><isTest>false</isTest><swiftCode>BANKUS33ABC</swiftCode><bic><institution>BANK</institution><country>US</country><location>33</location><branch>ABC</branch></bic></swiftCode>`,
+		},
+		{
+			name:   "xml headquarter via query",
+			input:  "/BANKPLPWXXX?format=xml",
+			status: http.StatusOK,
+			output: `<?xml version="1.0" encoding="UTF-8"?>` + "\n" + `<swiftCode><address>2 HQ Street</address><bankName>Bank PL</bankName><countryISO2>PL</countryISO2><countryName>POLAND</countryName><isHeadquarter>true</isHeadquarter><isPassive>false</isPassive><isReverseBilling>false</isReverseBilling><isTest>false</isTest><swiftCode>BANKPLPWXXX</swiftCode><bic><institution>BANK</institution><country>PL</country><location>PW</location><branch>XXX</branch></bic><branches></branches></swiftCode>`,
+		},
+		{
+			name:   "csv country",
+			input:  "/country/PL",
+			accept: "text/html;q=0.9, text/csv;q=0.8",
+			status: http.StatusOK,
+			output: "swiftCode,bankName,address,countryISO2,countryName,isHeadquarter,isTest,isPassive,isReverseBilling\nBANKPLPWXXX,Bank PL,2 HQ Street,PL,POLAND,true,false,false,false",
+		},
+		{
+			name:   "xml country",
+			input:  "/country/PL",
+			accept: "text/xml",
+			status: http.StatusOK,
+			output: `<?xml version="1.0" encoding="UTF-8"?>` + "\n" + `<country><countryISO2>PL</countryISO2><countryName>POLAND</countryName><swiftCodes><swiftCode><address>2 HQ Street</address><bankName>Bank PL</bankName><countryISO2>PL</countryISO2><isHeadquarter>true</isHeadquarter><isPassive>false</isPassive><isReverseBilling>false</isReverseBilling><isTest>false</isTest><swiftCode>BANKPLPWXXX</swiftCode><bic><institution>BANK</institution><country>PL</country><location>PW</location><branch>XXX</branch></bic></swiftCode></swiftCodes></country>`,
+		},
+		{
+			name:   "wildcard",
+			input:  "/BANKUS33ABC",
+			accept: "*/*",
+			status: http.StatusOK,
+			output: `{"address":"1 Branch Street","bankName":"Bank Branch","countryISO2":"US","countryName":"UNITED STATES","isHeadquarter":false,"isPassive":false,"isReverseBilling":false,"isTest":false,"swiftCode":"BANKUS33ABC","bic":{"institution":"BANK","country":"US","location":"33","branch":"ABC"}}`,
+		},
+		{
+			name:   "csv suggestions",
+			input:  "/BANKUS33DEF",
+			accept: "text/csv",
+			status: http.StatusNotFound,
+			output: "swiftCode,bankName,address,countryISO2,countryName,isHeadquarter,isTest,isPassive,isReverseBilling\nBANKUS33XXX,Bank HQ,1 HQ Street,US,UNITED STATES,true,false,false,false\nBANKUS33ABC,Bank Branch,1 Branch Street,US,UNITED STATES,false,false,false,false",
+		},
+		{
+			name:   "xml suggestions",
+			input:  "/BANKPLPW?format=xml",
+			status: http.StatusNotFound,
+			output: `<?xml version="1.0" encoding="UTF-8"?>` + "\n" + `<notFound><message>Not Found</message><suggestion><swiftCode>BANKPLPWXXX</swiftCode><bankName>Bank PL</bankName><reason>headquarter</reason></suggestion></notFound>`,
+		},
+		{
+			name:   "unsupported accept",
+			input:  "/BANKUS33ABC",
+			accept: "text/html",
+			status: http.StatusNotAcceptable,
+			output: `{"message":"Not Acceptable"}`,
+		},
+		{
+			name:   "unsupported format",
+			input:  "/country/PL?format=yaml",
+			status: http.StatusNotAcceptable,
+			output: `{"message":"Not Acceptable"}`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			headers := map[string]string{}
+			if tc.accept != "" {
+				headers[echo.HeaderAccept] = tc.accept
+			}
+			status, body := requestWithHeaders(t, http.MethodGet, apiPrefix+tc.input, "", headers)
+			assert.Equal(t, tc.status, status)
+			assert.Equal(t, tc.output, body)
+		})
+	}
+}
+
+func TestAddAndDeleteCode(t *testing.T) {
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		input   string
+		ifMatch string
+		output  string
+		status  int
+	}{
+		{
+			name:   "add valid code",
+			method: http.MethodPost,
+			input:  `{"bankName":"Test Bank","address":"","countryISO2":"de","countryName":"Germany","isHeadquarter":true,"swiftCode":"testdeffxxx"}`,
+			status: http.StatusCreated,
+			output: `{"message":"Created"}`,
+		},
+		{
+			name:   "add duplicate code",
+			method: http.MethodPost,
+			input:  `{"bankName":"Test Bank","address":"","countryISO2":"DE","countryName":"Germany","isHeadquarter":true,"isPassive":false,"isReverseBilling":false,"isTest":false,"swiftCode":"TESTDEFFXXX"}`,
+			status: http.StatusConflict,
+			output: `{"message":"Swift code already exists"}`,
+		},
+		{
+			name:   "add invalid code",
+			method: http.MethodPost,
+			input:  `{"bankName":"Test Bank","countryISO2":"DE","countryName":"Germany","isHeadquarter":true,"swiftCode":"INVALID"}`,
+			status: http.StatusBadRequest,
+			output: `{"message":"Validation Error: swiftCode is invalid"}`,
+		},
+		{
+			name:    "update code before it became valid",
+			method:  http.MethodPut,
+			path:    "/TESTDEFFXXX",
+			input:   `{"bankName":"Test Bank AG","address":"","countryISO2":"DE","countryName":"Germany","isHeadquarter":true,"validFrom":"2000-01-01"}`,
+			ifMatch: "*",
+			status:  http.StatusBadRequest,
+			output:  `{"message":"validFrom can't be before that of the current record"}`,
+		},
+		{
+			name:    "update code",
+			method:  http.MethodPut,
+			path:    "/TESTDEFFXXX",
+			input:   `{"bankName":"Test Bank AG","address":"","countryISO2":"DE","countryName":"Germany","isHeadquarter":true}`,
+			ifMatch: "*",
+			status:  http.StatusOK,
+			output:  `{"message":"OK"}`,
+		},
+		{
+			name:   "delete without If-Match",
+			method: http.MethodDelete,
+			path:   "/TESTDEFFXXX",
+			status: http.StatusPreconditionRequired,
+			output: `{"message":"If-Match header is required"}`,
+		},
+		{
+			name:    "delete changed code",
+			method:  http.MethodDelete,
+			path:    "/TESTDEFFXXX",
+			ifMatch: `"stale"`,
+			status:  http.StatusPreconditionFailed,
+			output:  `{"message":"Swift code was changed"}`,
+		},
+		{
+			name:    "delete existing code",
+			method:  http.MethodDelete,
+			path:    "/TESTDEFFXXX",
+			ifMatch: "*",
+			status:  http.StatusOK,
+			output:  `{"message":"OK"}`,
+		},
+		{
+			name:    "delete already deleted code",
+			method:  http.MethodDelete,
+			path:    "/TESTDEFFXXX",
+			ifMatch: "*",
+			status:  http.StatusNotFound,
+			output:  `{"message":"Not Found"}`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			headers := map[string]string{}
+			if tc.ifMatch != "" {
+				headers["If-Match"] = tc.ifMatch
+			}
+			status, body := requestWithHeaders(t, tc.method, apiPrefix+tc.path, tc.input, headers)
+			assert.Equal(t, tc.status, status)
+			assert.Equal(t, tc.output, body)
+		})
+	}
+}
+
+func TestMain(m *testing.M) {
+	db := database.NewMemory()
+	codes := []models.SwiftCode{
+		{SwiftCode: "BANKUS33XXX", BankName: "Bank HQ", Address: "1 HQ Street", CountryISO2: "US", CountryName: "United States", IsHeadquarter: true},
+		{SwiftCode: "BANKUS33ABC", BankName: "Bank Branch", Address: "1 Branch Street", CountryISO2: "US", CountryName: "United States", IsHeadquarter: false},
+		{SwiftCode: "BANKPLPWXXX", BankName: "Bank PL", Address: "2 HQ Street", CountryISO2: "PL", CountryName: "Poland", IsHeadquarter: true},
+		{SwiftCode: "BANKGB20XXX", BankName: "Bank GB", Address: "", CountryISO2: "GB", CountryName: "United Kingdom", IsHeadquarter: true},
+		{SwiftCode: "BANKGB21XXX", BankName: "Bank GB", Address: "", CountryISO2: "GB", CountryName: "United Kingdom", IsHeadquarter: true},
+		{SwiftCode: "BANKGB22XXX", BankName: "Bank GB", Address: "", CountryISO2: "GB", CountryName: "United Kingdom", IsHeadquarter: true},
+		{SwiftCode: "GONEUS33XXX", BankName: "Retired Bank", Address: "", CountryISO2: "US", CountryName: "United States", IsHeadquarter: true, ValidFrom: "2020-01-01", ValidTo: "2024-01-01"},
+		{SwiftCode: "DEUTDEFFXXX", BankName: "Deutsche Test", Address: "", CountryISO2: "DE", CountryName: "Germany", IsHeadquarter: true, ValidFrom: "2020-01-01"},
+	}
+	for _, code := range codes {
+		if err := code.Validate(); err != nil {
+			log.Fatalf("Invalid seed code: %v\n", err)
+		}
+		if err := db.InsertCode(context.Background(), code); err != nil {
+			log.Fatalf("Failed to seed database: %v\n", err)
+		}
+	}
+
+	for _, bankCode := range []models.BankCode{
+		{CountryISO2: "PL", BankCode: "109", SwiftCode: "BANKPLPW"},
+		{CountryISO2: "DE", BankCode: "50070010", SwiftCode: "DEUTDEFF"},
+	} {
+		if err := bankCode.Validate(); err != nil {
+			log.Fatalf("Invalid seed bank code: %v\n", err)
+		}
+		if err := db.InsertNationalID(context.Background(), bankCode.NationalID()); err != nil {
+			log.Fatalf("Failed to seed database: %v\n", err)
+		}
+	}
+
+	for _, id := range []models.NationalID{
+		{Scheme: "aba", Identifier: "021000021", SwiftCode: "BANKUS33"},
+		{Scheme: "aba", Identifier: "021000021", SwiftCode: "BANKUS33ABC"},
+		{Scheme: "sort-code", Identifier: "601613", SwiftCode: "BANKGB20XXX"},
+	} {
+		if err := id.Validate(); err != nil {
+			log.Fatalf("Invalid seed national id: %v\n", err)
+		}
+		if err := db.InsertNationalID(context.Background(), id); err != nil {
+			log.Fatalf("Failed to seed database: %v\n", err)
+		}
+	}
+
+	e = echo.New()
+	h := handler.New(db, handler.Config{AdminAPIKey: adminKey})
+	h.Register(e)
+
+	m.Run()
+}
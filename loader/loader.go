@@ -1,77 +1,438 @@
 package loader
 
 import (
+	"bufio"
 	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"os"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/rtsncs/remitly-swift-api/database"
 	"github.com/rtsncs/remitly-swift-api/models"
-	"github.com/xuri/excelize/v2"
 )
 
-func LoadFromFile(path string) error {
+// Columns is the column layout of the SWIFT data spreadsheet.
+var Columns = []string{"COUNTRY ISO2 CODE", "SWIFT CODE", "CODE TYPE", "NAME", "ADDRESS", "TOWN NAME", "COUNTRY NAME", "TIME ZONE"}
+
+// layout holds the positions of the columns that are loaded.
+type layout struct {
+	countryISO2, swiftCode, name, address, countryName int
+}
+
+// spreadsheetLayout is the layout of Columns.
+var spreadsheetLayout = layout{countryISO2: 0, swiftCode: 1, name: 3, address: 4, countryName: 6}
+
+// newLayout finds the loaded columns by their names in header, so that files
+// may reorder them or leave out the others. Files whose header doesn't name
+// them all are read in the layout of Columns.
+func newLayout(header []string) layout {
+	index := map[string]int{}
+	for i, name := range header {
+		index[strings.ToUpper(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	var l layout
+	for _, column := range []struct {
+		name     string
+		position *int
+	}{
+		{"COUNTRY ISO2 CODE", &l.countryISO2},
+		{"SWIFT CODE", &l.swiftCode},
+		{"NAME", &l.name},
+		{"ADDRESS", &l.address},
+		{"COUNTRY NAME", &l.countryName},
+	} {
+		i, ok := index[column.name]
+		if !ok {
+			return spreadsheetLayout
+		}
+		*column.position = i
+	}
+	return l
+}
+
+// width is the number of columns a row needs to have.
+func (l layout) width() int {
+	return max(l.countryISO2, l.swiftCode, l.name, l.address, l.countryName) + 1
+}
+
+// Options control how a file of codes is loaded.
+type Options struct {
+	// ValidFrom is the date the codes in the file are valid from, formatted
+	// with models.DateLayout. Today when empty.
+	ValidFrom string
+	// Partial only adds and updates the codes in the file. Otherwise the
+	// file is the complete directory, and current codes missing from it are
+	// closed out as of ValidFrom.
+	Partial bool
+	// Progress, when set, is called periodically while a spreadsheet is
+	// parsed.
+	Progress func(Progress)
+}
+
+// maxRowErrors caps the row errors kept in a Result.
+const maxRowErrors = 1000
+
+// Result counts the records of a loaded file.
+type Result struct {
+	Total     int `json:"total"`
+	Inserted  int `json:"inserted"`
+	Updated   int `json:"updated"`
+	Unchanged int `json:"unchanged"`
+	Closed    int `json:"closed"`
+	Failed    int `json:"failed"`
+	// Errors holds the first maxRowErrors records that failed to load.
+	Errors []RowError `json:"errors"`
+}
+
+// RowError is a record that failed to load.
+type RowError struct {
+	Record string `json:"record"`
+	Error  string `json:"error"`
+}
+
+type loader struct {
+	Result
+	c         context.Context
+	db        database.Store
+	validFrom string
+	// seen holds the codes found in the file so far.
+	seen       map[string]bool
+	onProgress func(Progress)
+}
+
+func LoadFromFile(path string, opts Options) error {
 	c := context.Background()
 	db, err := database.Connect(c)
 	if err != nil {
 		return err
 	}
 	defer db.Close()
-	return LoadFromFileWithDatabase(path, db)
+	_, err = LoadFromFileWithOptions(path, db, opts)
+	return err
 }
 
-func LoadFromFileWithDatabase(path string, db database.Database) error {
-	c := context.Background()
+func LoadFromFileWithDatabase(path string, db database.Store) error {
+	_, err := LoadFromFileWithOptions(path, db, Options{})
+	return err
+}
+
+// LoadFromFileWithOptions loads codes from a spreadsheet, CSV, JSON or NDJSON
+// file. Codes that are already stored with different details are versioned:
+// the stored record is closed out and the new one is valid from
+// opts.ValidFrom. Unless opts.Partial is set, current codes missing from the
+// file are closed out as of the same date.
+func LoadFromFileWithOptions(path string, db database.Store, opts Options) (Result, error) {
+	validFrom := opts.ValidFrom
+	if validFrom == "" {
+		validFrom = models.Today()
+	}
+	validFromDate, err := time.Parse(models.DateLayout, validFrom)
+	if err != nil {
+		return Result{}, fmt.Errorf("Invalid valid from date: %w", err)
+	}
+	// Rows are compared with the stored codes, which replicas may not have
+	// caught up on.
+	l := loader{
+		Result:     Result{Errors: []RowError{}},
+		c:          database.WithPrimary(context.Background()),
+		db:         db,
+		validFrom:  validFrom,
+		seen:       map[string]bool{},
+		onProgress: opts.Progress,
+	}
+
+	if err := parseFile(path, &l); err != nil {
+		return l.Result, err
+	}
+	if !opts.Partial {
+		if err := l.closeMissing(validFromDate); err != nil {
+			return l.Result, err
+		}
+	}
+
+	log.Printf("Total rows: %d; Inserted %d; Failed: %d; Updated: %d; Unchanged: %d; Closed: %d\n",
+		l.Total, l.Inserted, l.Failed, l.Updated, l.Unchanged, l.Closed)
+	return l.Result, nil
+}
+
+// ReadFile parses a file of codes without loading it. The codes are
+// normalized and validated as they would be when loaded; invalid and
+// duplicate records are logged and left out.
+func ReadFile(path string) ([]models.SwiftCode, error) {
+	r := reader{seen: map[string]bool{}}
+	if err := parseFile(path, &r); err != nil {
+		return nil, err
+	}
+	log.Printf("Total rows: %d; Valid: %d; Failed: %d\n", r.total, len(r.codes), r.failed)
+	return r.codes, nil
+}
+
+// reader collects the valid codes parsed from a file.
+type reader struct {
+	codes  []models.SwiftCode
+	seen   map[string]bool
+	total  int
+	failed int
+}
+
+func (r *reader) code(desc string, code models.SwiftCode) {
+	r.total++
+	if err := code.Validate(); err != nil {
+		log.Printf("Invalid %s: %v\n", desc, err)
+		r.failed++
+		return
+	}
+	if r.seen[code.SwiftCode] {
+		log.Printf("Duplicate %s\n", desc)
+		r.failed++
+		return
+	}
+	r.seen[code.SwiftCode] = true
+	r.codes = append(r.codes, code)
+}
+
+func (r *reader) invalid(desc string, err error) {
+	r.total++
+	r.failed++
+	log.Printf("Invalid %s: %v\n", desc, err)
+}
+
+func (r *reader) progress(p Progress) {}
+
+// codeHandler receives the records parsed from a file of codes.
+type codeHandler interface {
+	code(desc string, code models.SwiftCode)
+	invalid(desc string, err error)
+	progress(p Progress)
+}
+
+// parseFile parses a spreadsheet, CSV, JSON or NDJSON file of codes, chosen
+// by the file extension.
+func parseFile(path string, h codeHandler) error {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return parseCSV(path, h)
+	case ".json":
+		return parseJSON(path, h)
+	case ".ndjson", ".jsonl":
+		return parseNDJSON(path, h)
+	default:
+		return parseXLSX(path, h)
+	}
+}
 
-	f, err := excelize.OpenFile(path)
+func parseCSV(path string, h codeHandler) error {
+	f, err := os.Open(path)
 	if err != nil {
 		return fmt.Errorf("Failed to open file: %w", err)
 	}
 	defer f.Close()
 	log.Printf("Parsing file: %s\n", path)
 
-	sheets := f.GetSheetList()
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1
+	header, err := r.Read()
+	if err != nil && err != io.EOF {
+		return fmt.Errorf("Failed to read header: %w", err)
+	}
+	l := newLayout(header)
 
-	inserted, total, failed := 0, 0, 0
-	for _, sheet := range sheets {
-		log.Printf("Parsing sheet: %s\n", sheet)
-		rows, err := f.GetRows(sheet)
+	for i := 2; ; i++ {
+		row, err := r.Read()
+		if err == io.EOF {
+			break
+		}
 		if err != nil {
-			log.Printf("Failed to get rows: %v\n", err)
+			h.invalid(fmt.Sprintf("row #%d", i), err)
 			continue
 		}
-		total += len(rows) - 1
-
-		for i, row := range rows[1:] {
-			printIndex := i + 2
-			if len(row) < 7 {
-				log.Printf("Invalid row #%d %v: row too short\n", printIndex, row)
-				failed++
-				continue
-			}
-			code := models.SwiftCode{
-				CountryISO2:   strings.ToUpper(row[0]),
-				SwiftCode:     row[1],
-				BankName:      row[3],
-				Address:       row[4],
-				CountryName:   strings.ToUpper(row[6]),
-				IsHeadquarter: strings.HasSuffix(row[1], "XXX"),
-			}
-			if err := code.Validate(); err != nil {
-				log.Printf("Invalid row #%d %v: %v\n", printIndex, row, err)
-				failed++
-				continue
-			}
-			if err := db.InsertCode(c, code); err != nil {
-				log.Printf("Failed to insert row #%d %v: %v\n", printIndex, row, err)
-				failed++
-			} else {
-				inserted++
-			}
+		parseRow(i, row, l, h)
+	}
+
+	return nil
+}
+
+func parseJSON(path string, h codeHandler) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("Failed to open file: %w", err)
+	}
+	defer f.Close()
+	log.Printf("Parsing file: %s\n", path)
+
+	dec := json.NewDecoder(bufio.NewReader(f))
+	if _, err := dec.Token(); err != nil {
+		return fmt.Errorf("Failed to parse file: %w", err)
+	}
+	for i := 1; dec.More(); i++ {
+		var code models.SwiftCode
+		if err := dec.Decode(&code); err != nil {
+			return fmt.Errorf("Failed to parse record #%d: %w", i, err)
 		}
+		h.code(fmt.Sprintf("record #%d %s", i, code.SwiftCode), code)
 	}
 
-	log.Printf("Total rows: %d; Inserted %d; Failed: %d\n", total, inserted, failed)
 	return nil
 }
+
+func parseNDJSON(path string, h codeHandler) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("Failed to open file: %w", err)
+	}
+	defer f.Close()
+	log.Printf("Parsing file: %s\n", path)
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for i := 1; scanner.Scan(); i++ {
+		line := scanner.Bytes()
+		if len(strings.TrimSpace(string(line))) == 0 {
+			continue
+		}
+		var code models.SwiftCode
+		if err := json.Unmarshal(line, &code); err != nil {
+			h.invalid(fmt.Sprintf("line #%d", i), err)
+			continue
+		}
+		h.code(fmt.Sprintf("line #%d %s", i, code.SwiftCode), code)
+	}
+
+	return scanner.Err()
+}
+
+func parseRow(printIndex int, row []string, l layout, h codeHandler) {
+	desc := fmt.Sprintf("row #%d %v", printIndex, row)
+	if len(row) < l.width() {
+		h.invalid(desc, errors.New("row too short"))
+		return
+	}
+	code := models.SwiftCode{
+		CountryISO2:   strings.ToUpper(row[l.countryISO2]),
+		SwiftCode:     row[l.swiftCode],
+		BankName:      row[l.name],
+		Address:       row[l.address],
+		CountryName:   strings.ToUpper(row[l.countryName]),
+		IsHeadquarter: strings.HasSuffix(row[l.swiftCode], "XXX"),
+	}
+	h.code(desc, code)
+}
+
+func (l *loader) invalid(desc string, err error) {
+	l.Total++
+	log.Printf("Invalid %s: %v\n", desc, err)
+	l.fail(desc, err)
+}
+
+func (l *loader) progress(p Progress) {
+	if l.onProgress != nil {
+		l.onProgress(p)
+	}
+}
+
+// fail counts a record that failed to load and keeps its error.
+func (l *loader) fail(desc string, err error) {
+	l.Failed++
+	if len(l.Errors) < maxRowErrors {
+		l.Errors = append(l.Errors, RowError{desc, err.Error()})
+	}
+}
+
+func (l *loader) code(desc string, code models.SwiftCode) {
+	l.Total++
+	swiftCode := strings.ToUpper(code.SwiftCode)
+	if l.seen[swiftCode] {
+		log.Printf("Duplicate %s\n", desc)
+		l.fail(desc, errors.New("duplicate code"))
+		return
+	}
+	l.seen[swiftCode] = true
+
+	if code.ValidFrom == "" {
+		code.ValidFrom = l.validFrom
+	}
+	if err := code.Validate(); err != nil {
+		log.Printf("Invalid %s: %v\n", desc, err)
+		l.fail(desc, err)
+		return
+	}
+
+	err := l.db.InsertCode(l.c, code)
+	if errors.Is(err, database.ErrDuplicate) {
+		err = l.updateCode(code)
+	} else if err == nil {
+		l.Inserted++
+	}
+	if err != nil {
+		log.Printf("Failed to insert %s: %v\n", desc, err)
+		l.fail(desc, err)
+	}
+}
+
+// updateCode replaces the stored record of a code with a new version when
+// its details changed.
+func (l *loader) updateCode(code models.SwiftCode) error {
+	stored, err := l.db.GetByCode(l.c, code.SwiftCode)
+	if err != nil {
+		return err
+	}
+	if sameDetails(stored, code) {
+		l.Unchanged++
+		return nil
+	}
+
+	if err := l.db.ReplaceCode(l.c, code, stored.Version); err != nil {
+		return err
+	}
+	l.Updated++
+	return nil
+}
+
+// closeMissing closes out the current codes that weren't in the file. Codes
+// that became valid after validTo are kept and reported as failed.
+func (l *loader) closeMissing(validTo time.Time) error {
+	if len(l.seen) == 0 {
+		log.Println("No codes in file, not closing out missing codes")
+		return nil
+	}
+
+	var missing []string
+	err := l.db.ForEachCode(l.c, "", func(code models.SwiftCode) error {
+		if !l.seen[code.SwiftCode] {
+			missing = append(missing, code.SwiftCode)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, code := range missing {
+		_, err := l.db.CloseByCode(l.c, code, validTo)
+		if errors.Is(err, database.ErrBackdated) {
+			log.Printf("Failed to close out %s: %v\n", code, err)
+			l.fail("missing code "+code, err)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("Failed to close out %s: %w", code, err)
+		}
+		l.Closed++
+	}
+	return nil
+}
+
+func sameDetails(a, b models.SwiftCode) bool {
+	return a.BankName == b.BankName &&
+		a.Address == b.Address &&
+		a.CountryISO2 == b.CountryISO2 &&
+		a.CountryName == b.CountryName &&
+		a.IsHeadquarter == b.IsHeadquarter
+}
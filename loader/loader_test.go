@@ -4,11 +4,16 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/rtsncs/remitly-swift-api/database"
+	"github.com/rtsncs/remitly-swift-api/models"
 	"github.com/stretchr/testify/assert"
 	"github.com/testcontainers/testcontainers-go"
 	"github.com/testcontainers/testcontainers-go/modules/postgres"
@@ -50,7 +55,7 @@ func TestLoadFromFile(t *testing.T) {
 	log.SetOutput(&logBuf)
 	t.Cleanup(func() { log.SetOutput(originalOutput) })
 
-	LoadFromFileWithDatabase(tmpFile.Name(), db)
+	LoadFromFileWithDatabase(tmpFile.Name(), &db)
 
 	logs := logBuf.String()
 	assert.Contains(t, logs, fmt.Sprintf("Total rows: %d; Inserted %d; Failed: %d", len(rows)-1, 3, 3))
@@ -69,6 +74,280 @@ func TestLoadFromFile(t *testing.T) {
 	assert.Equal(t, "BANKUS00NYC", branches[0].SwiftCode)
 }
 
+func TestLoadXLSXProgress(t *testing.T) {
+	file := excelize.NewFile()
+	header := []string{"COUNTRY ISO2 CODE", "SWIFT CODE", "CODE TYPE", "NAME", "ADDRESS", "TOWN NAME", "COUNTRY NAME", "TIME ZONE"}
+	sheets := map[string][][]string{
+		"Sheet1": {header, {"NZ", "PROGNZ22XXX", "BIC11", "Progress Bank", "", "", "NEW ZEALAND", ""}},
+		"Sheet2": {header, {"NZ", "PROGNZ22AKL", "BIC11", "Progress Bank", "", "", "NEW ZEALAND", ""}, {"NZ", "PROGNZ22WLG", "BIC11", "Progress Bank", "", "", "NEW ZEALAND", ""}},
+	}
+	_, err := file.NewSheet("Sheet2")
+	assert.NoError(t, err)
+	for sheet, rows := range sheets {
+		for i, row := range rows {
+			assert.NoError(t, file.SetSheetRow(sheet, fmt.Sprintf("A%d", i+1), &row))
+		}
+	}
+	// Only the first sheet declares its size.
+	assert.NoError(t, file.SetSheetDimension("Sheet1", "A1:H2"))
+	path := filepath.Join(t.TempDir(), "progress.xlsx")
+	assert.NoError(t, file.SaveAs(path))
+
+	originalOutput := log.Writer()
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log.SetOutput(originalOutput) })
+
+	var reports []Progress
+	store := database.NewMemory()
+	result, err := LoadFromFileWithOptions(path, store, Options{Progress: func(p Progress) { reports = append(reports, p) }})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, result.Inserted)
+
+	assert.Len(t, reports, 2)
+	assert.Equal(t, "Sheet1", reports[0].Sheet)
+	assert.Equal(t, 2, reports[0].Rows)
+	assert.Equal(t, 2, reports[0].TotalRows)
+	assert.Equal(t, 100.0, reports[0].Percent)
+	assert.Equal(t, "Sheet2", reports[1].Sheet)
+	assert.Equal(t, 3, reports[1].Rows)
+	assert.Zero(t, reports[1].TotalRows)
+	assert.Zero(t, reports[1].Percent)
+}
+
+func TestLoadBankCodesFromFile(t *testing.T) {
+	c := context.Background()
+
+	assert.NoError(t, db.InsertCode(c, models.SwiftCode{SwiftCode: "COBADEFFXXX", BankName: "Commerzbank", CountryISO2: "DE", CountryName: "GERMANY", IsHeadquarter: true}))
+
+	tmpFile, err := os.CreateTemp("", "bankcodes-*.csv")
+	assert.NoError(t, err, "failed to create temp file")
+	t.Cleanup(func() { os.Remove(tmpFile.Name()) })
+
+	_, err = tmpFile.WriteString("COUNTRY ISO2 CODE,BANK CODE,SWIFT CODE\n" +
+		"DE,37040044,COBADEFF\n" +
+		"de,3704 0044,COBADEFFXXX\n" +
+		"DE,,COBADEFFXXX\n" +
+		"DE,37040044\n")
+	assert.NoError(t, err)
+	assert.NoError(t, tmpFile.Close())
+
+	var logBuf bytes.Buffer
+	originalOutput := log.Writer()
+	log.SetOutput(&logBuf)
+	t.Cleanup(func() { log.SetOutput(originalOutput) })
+
+	assert.NoError(t, LoadBankCodesFromFileWithDatabase(tmpFile.Name(), &db))
+	assert.Contains(t, logBuf.String(), "Total rows: 4; Inserted 1; Failed: 3")
+
+	// German bank codes are found by their Bankleitzahl too.
+	codes, err := db.GetByNationalID(c, models.SchemeBLZ, "37040044")
+	assert.NoError(t, err)
+	assert.Len(t, codes, 1)
+	assert.Equal(t, "COBADEFFXXX", codes[0].SwiftCode)
+}
+
+func TestLoadNationalIDsFromFile(t *testing.T) {
+	c := context.Background()
+
+	assert.NoError(t, db.InsertCode(c, models.SwiftCode{SwiftCode: "NWBKGB2LXXX", BankName: "NatWest", CountryISO2: "GB", CountryName: "UNITED KINGDOM", IsHeadquarter: true}))
+
+	tmpFile, err := os.CreateTemp("", "nationalids-*.csv")
+	assert.NoError(t, err, "failed to create temp file")
+	t.Cleanup(func() { os.Remove(tmpFile.Name()) })
+
+	_, err = tmpFile.WriteString("SCHEME,NATIONAL ID,SWIFT CODE\n" +
+		"sort-code,60-16-13,NWBKGB2L\n" +
+		"aba,021000022,NWBKGB2LXXX\n" +
+		"bsb,062000,NWBKGB2LXXX\n" +
+		"sort-code,601613,NWBKGB2LXXX\n")
+	assert.NoError(t, err)
+	assert.NoError(t, tmpFile.Close())
+
+	var logBuf bytes.Buffer
+	originalOutput := log.Writer()
+	log.SetOutput(&logBuf)
+	t.Cleanup(func() { log.SetOutput(originalOutput) })
+
+	assert.NoError(t, LoadNationalIDsFromFileWithDatabase(tmpFile.Name(), &db))
+	assert.Contains(t, logBuf.String(), "Total rows: 4; Inserted 1; Failed: 3")
+
+	codes, err := db.GetByNationalID(c, models.SchemeSortCode, "601613")
+	assert.NoError(t, err)
+	assert.Len(t, codes, 1)
+	assert.Equal(t, "NWBKGB2LXXX", codes[0].SwiftCode)
+}
+
+func TestLoadBackdated(t *testing.T) {
+	c := context.Background()
+	store := database.NewMemory()
+	path := filepath.Join(t.TempDir(), "codes.csv")
+	write := func(name string) {
+		content := "COUNTRY ISO2 CODE,SWIFT CODE,CODE TYPE,NAME,ADDRESS,TOWN NAME,COUNTRY NAME,TIME ZONE\n" +
+			"NL,BACKNL2AXXX,BIC11," + name + ",,,NETHERLANDS,\n"
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+
+	var logBuf bytes.Buffer
+	originalOutput := log.Writer()
+	log.SetOutput(&logBuf)
+	t.Cleanup(func() { log.SetOutput(originalOutput) })
+
+	write("March Bank")
+	_, err := LoadFromFileWithOptions(path, store, Options{ValidFrom: "2026-03-01"})
+	assert.NoError(t, err)
+
+	// An older file can't retire the record that replaced it.
+	write("January Bank")
+	result, err := LoadFromFileWithOptions(path, store, Options{ValidFrom: "2026-01-01"})
+	assert.NoError(t, err)
+	assert.Equal(t, Result{Total: 1, Failed: 1, Errors: []RowError{
+		{"row #2 [NL BACKNL2AXXX BIC11 January Bank   NETHERLANDS ]", database.ErrBackdated.Error()},
+	}}, result)
+
+	current, err := store.GetByCode(c, "BACKNL2AXXX")
+	assert.NoError(t, err)
+	assert.Equal(t, "March Bank", current.BankName)
+	assert.Empty(t, current.ValidTo)
+}
+
+func TestLoadByHeader(t *testing.T) {
+	c := context.Background()
+	store := database.NewMemory()
+	path := filepath.Join(t.TempDir(), "codes.csv")
+	content := "SWIFT CODE,NAME,Country ISO2 Code,COUNTRY NAME,ADDRESS\n" +
+		"HEADNL2AXXX,Header Bank,nl,Netherlands,1 Main St\n" +
+		"HEADNL2A001,Header Bank,NL\n"
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+
+	var logBuf bytes.Buffer
+	originalOutput := log.Writer()
+	log.SetOutput(&logBuf)
+	t.Cleanup(func() { log.SetOutput(originalOutput) })
+
+	result, err := LoadFromFileWithOptions(path, store, Options{})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, result.Total)
+	assert.Equal(t, []RowError{{"row #3 [HEADNL2A001 Header Bank NL]", "row too short"}}, result.Errors)
+
+	code, err := store.GetByCode(c, "HEADNL2AXXX")
+	assert.NoError(t, err)
+	assert.Equal(t, "Header Bank", code.BankName)
+	assert.Equal(t, "1 Main St", code.Address)
+	assert.Equal(t, "NL", code.CountryISO2)
+	assert.Equal(t, "NETHERLANDS", code.CountryName)
+}
+
+func TestLoadFullDirectory(t *testing.T) {
+	c := context.Background()
+	store := database.NewMemory()
+	dir := t.TempDir()
+	write := func(name string, rows ...string) string {
+		path := filepath.Join(dir, name)
+		content := "COUNTRY ISO2 CODE,SWIFT CODE,CODE TYPE,NAME,ADDRESS,TOWN NAME,COUNTRY NAME,TIME ZONE\n" + strings.Join(rows, "\n") + "\n"
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+		return path
+	}
+
+	var logBuf bytes.Buffer
+	originalOutput := log.Writer()
+	log.SetOutput(&logBuf)
+	t.Cleanup(func() { log.SetOutput(originalOutput) })
+
+	january := write("january.csv",
+		"NL,SNAPNL2AXXX,BIC11,Snapshot Bank,,,NETHERLANDS,",
+		"NL,GONENL2AXXX,BIC11,Gone Bank,,,NETHERLANDS,",
+		"NL,SAMENL2AXXX,BIC11,Same Bank,,,NETHERLANDS,",
+	)
+	_, err := LoadFromFileWithOptions(january, store, Options{ValidFrom: "2026-01-01"})
+	assert.NoError(t, err)
+	assert.Contains(t, logBuf.String(), "Total rows: 3; Inserted 3; Failed: 0; Updated: 0; Unchanged: 0; Closed: 0")
+
+	february := write("february.csv",
+		"NL,SNAPNL2AXXX,BIC11,Renamed Bank,,,NETHERLANDS,",
+		"NL,SAMENL2AXXX,BIC11,Same Bank,,,NETHERLANDS,",
+		"NL,NEWWNL2AXXX,BIC11,New Bank,,,NETHERLANDS,",
+		"NL,NEWWNL2AXXX,BIC11,New Bank,,,NETHERLANDS,",
+	)
+	result, err := LoadFromFileWithOptions(february, store, Options{ValidFrom: "2026-02-01"})
+	assert.NoError(t, err)
+	assert.Contains(t, logBuf.String(), "Total rows: 4; Inserted 1; Failed: 1; Updated: 1; Unchanged: 1; Closed: 1")
+	assert.Equal(t, Result{Total: 4, Inserted: 1, Updated: 1, Unchanged: 1, Closed: 1, Failed: 1, Errors: []RowError{
+		{"row #5 [NL NEWWNL2AXXX BIC11 New Bank   NETHERLANDS ]", "duplicate code"},
+	}}, result)
+
+	midJanuary, _ := time.Parse(models.DateLayout, "2026-01-15")
+	old, err := store.GetByCodeAsOf(c, "SNAPNL2AXXX", midJanuary)
+	assert.NoError(t, err)
+	assert.Equal(t, "Snapshot Bank", old.BankName)
+	assert.Equal(t, "2026-02-01", old.ValidTo)
+
+	current, err := store.GetByCode(c, "SNAPNL2AXXX")
+	assert.NoError(t, err)
+	assert.Equal(t, "Renamed Bank", current.BankName)
+	assert.Equal(t, "2026-02-01", current.ValidFrom)
+
+	same, err := store.GetByCode(c, "SAMENL2AXXX")
+	assert.NoError(t, err)
+	assert.Equal(t, "2026-01-01", same.ValidFrom)
+
+	_, err = store.GetByCode(c, "GONENL2AXXX")
+	assert.ErrorIs(t, err, database.ErrNotFound)
+	gone, err := store.GetByCodeAsOf(c, "GONENL2AXXX", midJanuary)
+	assert.NoError(t, err)
+	assert.Equal(t, "2026-02-01", gone.ValidTo)
+
+	// The february import made an update, a new code and a closed one.
+	changes, err := store.GetChanges(c, 3, 10)
+	assert.NoError(t, err)
+	var recorded []string
+	for _, change := range changes {
+		recorded = append(recorded, string(change.Type)+" "+change.SwiftCode)
+	}
+	assert.Equal(t, []string{"updated SNAPNL2AXXX", "created NEWWNL2AXXX", "deleted GONENL2AXXX"}, recorded)
+}
+
+func TestLoadPartial(t *testing.T) {
+	c := context.Background()
+	store := database.NewMemory()
+	dir := t.TempDir()
+	write := func(name string, rows ...string) string {
+		path := filepath.Join(dir, name)
+		content := "COUNTRY ISO2 CODE,SWIFT CODE,CODE TYPE,NAME,ADDRESS,TOWN NAME,COUNTRY NAME,TIME ZONE\n" + strings.Join(rows, "\n") + "\n"
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+		return path
+	}
+
+	var logBuf bytes.Buffer
+	originalOutput := log.Writer()
+	log.SetOutput(&logBuf)
+	t.Cleanup(func() { log.SetOutput(originalOutput) })
+
+	march := write("march.csv",
+		"BE,KEPTBEBBXXX,BIC11,Kept Bank,,,BELGIUM,",
+		"BE,LATEBEBBXXX,BIC11,Late Bank,,,BELGIUM,",
+	)
+	_, err := LoadFromFileWithOptions(march, store, Options{ValidFrom: "2026-03-01"})
+	assert.NoError(t, err)
+
+	// A partial load keeps the codes it doesn't list.
+	corrections := write("corrections.csv", "BE,KEPTBEBBXXX,BIC11,Kept Bank,,,BELGIUM,")
+	result, err := LoadFromFileWithOptions(corrections, store, Options{ValidFrom: "2026-03-01", Partial: true})
+	assert.NoError(t, err)
+	assert.Zero(t, result.Closed)
+	_, err = store.GetByCode(c, "LATEBEBBXXX")
+	assert.NoError(t, err)
+
+	// An older file can't close out codes that became valid after it.
+	result, err = LoadFromFileWithOptions(corrections, store, Options{ValidFrom: "2026-02-01"})
+	assert.NoError(t, err)
+	assert.Equal(t, Result{Total: 1, Unchanged: 1, Failed: 1, Errors: []RowError{
+		{"missing code LATEBEBBXXX", database.ErrBackdated.Error()},
+	}}, result)
+	late, err := store.GetByCode(c, "LATEBEBBXXX")
+	assert.NoError(t, err)
+	assert.Empty(t, late.ValidTo)
+}
+
 func TestMain(m *testing.M) {
 	c := context.Background()
 
@@ -0,0 +1,108 @@
+package loader
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/rtsncs/remitly-swift-api/database"
+	"github.com/rtsncs/remitly-swift-api/models"
+)
+
+// BankCodeColumns is the column layout of the national bank code mapping file.
+var BankCodeColumns = []string{"COUNTRY ISO2 CODE", "BANK CODE", "SWIFT CODE"}
+
+// NationalIDColumns is the column layout of national directory files.
+var NationalIDColumns = []string{"SCHEME", "NATIONAL ID", "SWIFT CODE"}
+
+func LoadBankCodesFromFile(path string) error {
+	c := context.Background()
+	db, err := database.Connect(c)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	return LoadBankCodesFromFileWithDatabase(path, db)
+}
+
+// LoadBankCodesFromFileWithDatabase loads a CSV file mapping national bank
+// codes, as embedded in IBANs, to SWIFT codes. German bank codes are the
+// Bankleitzahl, so they are found by national ID lookups too.
+func LoadBankCodesFromFileWithDatabase(path string, db database.Store) error {
+	l := loader{c: context.Background(), db: db}
+	return l.loadMapping(path, len(BankCodeColumns), func(row []string) error {
+		bankCode := models.BankCode{CountryISO2: row[0], BankCode: row[1], SwiftCode: row[2]}
+		if err := bankCode.Validate(); err != nil {
+			return err
+		}
+		return l.db.InsertNationalID(l.c, bankCode.NationalID())
+	})
+}
+
+func LoadNationalIDsFromFile(path string) error {
+	c := context.Background()
+	db, err := database.Connect(c)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	return LoadNationalIDsFromFileWithDatabase(path, db)
+}
+
+// LoadNationalIDsFromFileWithDatabase loads a national directory CSV file
+// mapping sort codes, ABA routing numbers or BLZ to SWIFT codes.
+func LoadNationalIDsFromFileWithDatabase(path string, db database.Store) error {
+	l := loader{c: context.Background(), db: db}
+	return l.loadMapping(path, len(NationalIDColumns), func(row []string) error {
+		id := models.NationalID{Scheme: row[0], Identifier: row[1], SwiftCode: row[2]}
+		if err := id.Validate(); err != nil {
+			return err
+		}
+		return l.db.InsertNationalID(l.c, id)
+	})
+}
+
+func (l *loader) loadMapping(path string, columns int, insert func(row []string) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("Failed to open file: %w", err)
+	}
+	defer f.Close()
+	log.Printf("Parsing file: %s\n", path)
+
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1
+	if _, err := r.Read(); err != nil && err != io.EOF {
+		return fmt.Errorf("Failed to read header: %w", err)
+	}
+
+	for i := 2; ; i++ {
+		row, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		l.Total++
+		if err != nil {
+			l.Failed++
+			log.Printf("Invalid row #%d: %v\n", i, err)
+			continue
+		}
+		if len(row) < columns {
+			l.Failed++
+			log.Printf("Invalid row #%d %v: row too short\n", i, row)
+			continue
+		}
+		if err := insert(row); err != nil {
+			l.Failed++
+			log.Printf("Failed to insert row #%d %v: %v\n", i, row, err)
+			continue
+		}
+		l.Inserted++
+	}
+
+	log.Printf("Total rows: %d; Inserted %d; Failed: %d\n", l.Total, l.Inserted, l.Failed)
+	return nil
+}
@@ -0,0 +1,65 @@
+package loader
+
+import (
+	"log"
+	"time"
+)
+
+// progressInterval is how often progress is reported while a sheet is parsed.
+const progressInterval = 5 * time.Second
+
+// Progress of parsing a spreadsheet.
+type Progress struct {
+	Sheet string `json:"sheet"`
+	Rows  int    `json:"rows"`
+	// TotalRows is the number of rows the sheet declares, or 0 when it
+	// doesn't or the declaration turns out to be wrong.
+	TotalRows     int     `json:"totalRows,omitempty"`
+	Percent       float64 `json:"percent,omitempty"`
+	RowsPerSecond float64 `json:"rowsPerSecond"`
+}
+
+type progressReporter struct {
+	Progress
+	start, last time.Time
+	report      func(Progress)
+}
+
+func newProgressReporter(sheet string, totalRows int, report func(Progress)) *progressReporter {
+	now := time.Now()
+	return &progressReporter{
+		Progress: Progress{Sheet: sheet, TotalRows: totalRows},
+		start:    now,
+		last:     now,
+		report:   report,
+	}
+}
+
+func (p *progressReporter) row() {
+	p.Rows++
+	// The declared size was wrong, so the percentage can't be known.
+	if p.Rows > p.TotalRows {
+		p.TotalRows = 0
+	}
+	if now := time.Now(); now.Sub(p.last) >= progressInterval {
+		p.last = now
+		p.emit(now)
+	}
+}
+
+func (p *progressReporter) done() {
+	p.emit(time.Now())
+}
+
+func (p *progressReporter) emit(now time.Time) {
+	if elapsed := now.Sub(p.start).Seconds(); elapsed > 0 {
+		p.RowsPerSecond = float64(p.Rows) / elapsed
+	}
+	if p.TotalRows > 0 {
+		p.Percent = min(100, 100*float64(p.Rows)/float64(p.TotalRows))
+		log.Printf("Sheet %s: %d of %d rows (%.1f%%), %.0f rows/s\n", p.Sheet, p.Rows, p.TotalRows, p.Percent, p.RowsPerSecond)
+	} else {
+		log.Printf("Sheet %s: %d rows, %.0f rows/s\n", p.Sheet, p.Rows, p.RowsPerSecond)
+	}
+	p.report(p.Progress)
+}
@@ -0,0 +1,156 @@
+package loader
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"log"
+	"path"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// parseXLSX streams the rows of every sheet, so that memory use doesn't grow
+// with the size of the workbook.
+func parseXLSX(path string, h codeHandler) error {
+	f, err := excelize.OpenFile(path)
+	if err != nil {
+		return fmt.Errorf("Failed to open file: %w", err)
+	}
+	defer f.Close()
+	log.Printf("Parsing file: %s\n", path)
+
+	sizes := sheetSizes(path)
+	for _, sheet := range f.GetSheetList() {
+		log.Printf("Parsing sheet: %s\n", sheet)
+		if err := parseSheet(f, sheet, sizes[sheet], h); err != nil {
+			log.Printf("Failed to get rows: %v\n", err)
+		}
+	}
+
+	return nil
+}
+
+func parseSheet(f *excelize.File, sheet string, size int, h codeHandler) error {
+	rows, err := f.Rows(sheet)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	progress := newProgressReporter(sheet, size, h.progress)
+	l := spreadsheetLayout
+	for i := 1; rows.Next(); i++ {
+		row, err := rows.Columns()
+		if err != nil {
+			return err
+		}
+		// The first row is the header. Empty rows, which the iterator
+		// returns for formatted cells, are skipped.
+		if i == 1 {
+			l = newLayout(row)
+		} else if len(row) > 0 {
+			parseRow(i, row, l, h)
+		}
+		progress.row()
+	}
+	progress.done()
+
+	return rows.Error()
+}
+
+// sheetSizes reads the number of rows each sheet declares in its dimension,
+// without loading the sheets. Sheets that don't declare it are missing from
+// the result.
+func sheetSizes(name string) map[string]int {
+	sizes := map[string]int{}
+	r, err := zip.OpenReader(name)
+	if err != nil {
+		return sizes
+	}
+	defer r.Close()
+
+	var workbook struct {
+		Sheets []struct {
+			Name string `xml:"name,attr"`
+			ID   string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	var rels struct {
+		Relationships []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	if decodeZipFile(&r.Reader, "xl/workbook.xml", &workbook) != nil || decodeZipFile(&r.Reader, "xl/_rels/workbook.xml.rels", &rels) != nil {
+		return sizes
+	}
+
+	targets := map[string]string{}
+	for _, rel := range rels.Relationships {
+		target := strings.TrimPrefix(rel.Target, "/")
+		if !strings.HasPrefix(target, "xl/") {
+			target = path.Join("xl", target)
+		}
+		targets[rel.ID] = target
+	}
+	for _, sheet := range workbook.Sheets {
+		if rows := sheetDimensionRows(&r.Reader, targets[sheet.ID]); rows > 0 {
+			sizes[sheet.Name] = rows
+		}
+	}
+	return sizes
+}
+
+func decodeZipFile(r *zip.Reader, name string, v any) error {
+	f, err := r.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return xml.NewDecoder(f).Decode(v)
+}
+
+// sheetDimensionRows returns the last row of the dimension of a worksheet,
+// which precedes the sheet data, or 0 when it's missing. Writers that don't
+// maintain the dimension leave a single cell in it, which is ignored.
+func sheetDimensionRows(r *zip.Reader, name string) int {
+	f, err := r.Open(name)
+	if err != nil {
+		return 0
+	}
+	defer f.Close()
+
+	dec := xml.NewDecoder(f)
+	for {
+		token, err := dec.Token()
+		if err != nil {
+			return 0
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch start.Name.Local {
+		case "dimension":
+			for _, attr := range start.Attr {
+				if attr.Name.Local != "ref" {
+					continue
+				}
+				_, ref, ok := strings.Cut(attr.Value, ":")
+				if !ok {
+					return 0
+				}
+				_, row, err := excelize.CellNameToCoordinates(ref)
+				if err != nil {
+					return 0
+				}
+				return row
+			}
+			return 0
+		case "sheetData":
+			return 0
+		}
+	}
+}
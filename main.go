@@ -1,37 +1,142 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"log"
 	"os"
+	"strings"
 
+	"github.com/rtsncs/remitly-swift-api/config"
+	"github.com/rtsncs/remitly-swift-api/database"
+	"github.com/rtsncs/remitly-swift-api/differ"
+	"github.com/rtsncs/remitly-swift-api/exporter"
 	"github.com/rtsncs/remitly-swift-api/loader"
 	"github.com/rtsncs/remitly-swift-api/server"
 )
 
+const usage = "expected 'load', 'export', 'diff', 'serve' or 'config print' subcommand"
+
 func main() {
 	loadCmd := flag.NewFlagSet("load", flag.ExitOnError)
 	loadFile := loadCmd.String("file", "", "Path to the SWIFT data spreadsheet")
+	loadValidFrom := loadCmd.String("valid-from", "", "Date the codes in the file are valid from, YYYY-MM-DD (default today)")
+	loadBankCodes := loadCmd.String("bank-codes", "", "Path to a CSV file mapping national bank codes to SWIFT codes")
+	loadNationalIDs := loadCmd.String("national-ids", "", "Path to a national directory CSV file (sort codes, ABA routing numbers, BLZ)")
+
+	exportCmd := flag.NewFlagSet("export", flag.ExitOnError)
+	exportFormat := exportCmd.String("format", "xlsx", "Output format: xlsx, csv, json or ndjson")
+	exportFile := exportCmd.String("out", "", "Path to the output file")
+	exportCountry := exportCmd.String("country", "", "Only export codes from this country")
+
+	diffCmd := flag.NewFlagSet("diff", flag.ExitOnError)
+	diffOld := diffCmd.String("old", "", "Path to the old SWIFT data file")
+	diffNew := diffCmd.String("new", "", "Path to the new SWIFT data file")
+	diffFile := diffCmd.String("file", "", "Path to a SWIFT data file to compare with the database")
+	diffFormat := diffCmd.String("format", "text", "Output format: text or json")
 
 	serveCmd := flag.NewFlagSet("serve", flag.ExitOnError)
 
+	configCmd := flag.NewFlagSet("config print", flag.ExitOnError)
+	configFormat := configCmd.String("format", "yaml", "Output format: yaml or toml")
+
 	if len(os.Args) < 2 {
-		log.Fatalln("expected 'load' or 'serve' subcommand")
+		log.Fatalln(usage)
 	}
 
 	switch os.Args[1] {
 	case "load":
-		loadCmd.Parse(os.Args[2:])
-		if *loadFile == "" {
-			log.Fatalf("Usage: %s load -file=path/to/file.xlsx\n", os.Args[0])
+		cfg := loadConfig(loadCmd, os.Args[2:], config.GroupDatabase, config.GroupLoader)
+		if *loadFile == "" && *loadBankCodes == "" && *loadNationalIDs == "" {
+			log.Fatalf("Usage: %s load -file=path/to/file.xlsx [-bank-codes=path/to/file.csv] [-national-ids=path/to/file.csv]\n", os.Args[0])
+		}
+		db := connect(cfg)
+		defer db.Close()
+		if *loadFile != "" {
+			opts := loader.Options{ValidFrom: *loadValidFrom, Partial: cfg.Loader.Partial}
+			if _, err := loader.LoadFromFileWithOptions(*loadFile, db, opts); err != nil {
+				log.Fatal(err)
+			}
+		}
+		if *loadBankCodes != "" {
+			if err := loader.LoadBankCodesFromFileWithDatabase(*loadBankCodes, db); err != nil {
+				log.Fatal(err)
+			}
+		}
+		if *loadNationalIDs != "" {
+			if err := loader.LoadNationalIDsFromFileWithDatabase(*loadNationalIDs, db); err != nil {
+				log.Fatal(err)
+			}
+		}
+	case "export":
+		cfg := loadConfig(exportCmd, os.Args[2:], config.GroupDatabase)
+		if *exportFile == "" {
+			log.Fatalf("Usage: %s export -format=xlsx|csv|json|ndjson -out=path/to/file\n", os.Args[0])
+		}
+		format, err := exporter.ParseFormat(*exportFormat)
+		if err != nil {
+			log.Fatal(err)
+		}
+		db := connect(cfg)
+		defer db.Close()
+		if err := exporter.ExportToFileWithDatabase(*exportFile, format, strings.ToUpper(*exportCountry), db); err != nil {
+			log.Fatal(err)
+		}
+	case "diff":
+		cfg := loadConfig(diffCmd, os.Args[2:], config.GroupDatabase)
+		format, err := differ.ParseFormat(*diffFormat)
+		if err != nil {
+			log.Fatal(err)
 		}
-		if err := loader.LoadFromFile(*loadFile); err != nil {
+		var result differ.Result
+		switch {
+		case *diffOld != "" && *diffNew != "":
+			result, err = differ.DiffFiles(*diffOld, *diffNew)
+		case *diffFile != "":
+			db := connect(cfg)
+			defer db.Close()
+			result, err = differ.DiffFileWithDatabase(*diffFile, db)
+		default:
+			log.Fatalf("Usage: %s diff -old=path/to/old.xlsx -new=path/to/new.xlsx | -file=path/to/file.xlsx [-format=text|json]\n", os.Args[0])
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := result.Write(os.Stdout, format); err != nil {
 			log.Fatal(err)
 		}
 	case "serve":
-		serveCmd.Parse(os.Args[2:])
-		server.Run()
+		cfg := loadConfig(serveCmd, os.Args[2:], config.GroupServer, config.GroupDatabase, config.GroupLog, config.GroupAuth, config.GroupRateLimit)
+		if err := server.Run(cfg); err != nil {
+			log.Fatal(err)
+		}
+	case "config":
+		if len(os.Args) < 3 || os.Args[2] != "print" {
+			log.Fatalf("Usage: %s config print [-config=path/to/config.yaml] [-format=yaml|toml]\n", os.Args[0])
+		}
+		cfg := loadConfig(configCmd, os.Args[3:])
+		if err := cfg.Redacted().Write(os.Stdout, *configFormat); err != nil {
+			log.Fatal(err)
+		}
 	default:
-		log.Fatalln("expected 'load' or 'serve' subcommand")
+		log.Fatalln(usage)
+	}
+}
+
+// loadConfig parses the flags of a subcommand and loads the config with
+// them, adding flags for the settings in groups.
+func loadConfig(fs *flag.FlagSet, args []string, groups ...string) config.Config {
+	cfg, err := config.Load(fs, args, groups...)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return cfg
+}
+
+func connect(cfg config.Config) database.Store {
+	db, err := database.ConnectWithOptions(context.Background(), cfg.Database)
+	if err != nil {
+		log.Fatal(err)
 	}
+	return db
 }
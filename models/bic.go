@@ -0,0 +1,79 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+)
+
+// BIC is a business identifier code decoded according to ISO 9362. The flags
+// are copied onto SwiftCode, which is where responses carry them.
+type BIC struct {
+	Institution      string `json:"institution" xml:"institution"`
+	Country          string `json:"country" xml:"country"`
+	Location         string `json:"location" xml:"location"`
+	Branch           string `json:"branch,omitempty" xml:"branch,omitempty"`
+	IsTest           bool   `json:"-" xml:"-"`
+	IsPassive        bool   `json:"-" xml:"-"`
+	IsReverseBilling bool   `json:"-" xml:"-"`
+}
+
+func isAlpha(b byte) bool {
+	return b >= 'A' && b <= 'Z'
+}
+
+// IsAlphanumeric reports whether b is an uppercase ASCII letter or a digit,
+// the characters of BICs and IBANs.
+func IsAlphanumeric(b byte) bool {
+	return isAlpha(b) || (b >= '0' && b <= '9')
+}
+
+// ParseBIC decodes an 8 or 11 character uppercase BIC. Since ISO 9362:2014
+// the business party prefix may contain digits; the second character of the
+// location code marks test (0), passive (1) and reverse billing (2) BICs and
+// may not be the letter O.
+func ParseBIC(s string) (BIC, error) {
+	if len(s) != 8 && len(s) != 11 {
+		return BIC{}, errors.New("must be 8 or 11 characters long")
+	}
+	for i := range 4 {
+		if !IsAlphanumeric(s[i]) {
+			return BIC{}, errors.New("business party prefix must be alphanumeric")
+		}
+	}
+	for i := 4; i < 6; i++ {
+		if !isAlpha(s[i]) {
+			return BIC{}, errors.New("country code must consist of two letters")
+		}
+	}
+	for i := 6; i < 8; i++ {
+		if !IsAlphanumeric(s[i]) {
+			return BIC{}, errors.New("location code must be alphanumeric")
+		}
+	}
+	if s[7] == 'O' {
+		return BIC{}, errors.New("location code can't end with the letter O")
+	}
+
+	bic := BIC{
+		Institution:      s[:4],
+		Country:          s[4:6],
+		Location:         s[6:8],
+		IsTest:           s[7] == '0',
+		IsPassive:        s[7] == '1',
+		IsReverseBilling: s[7] == '2',
+	}
+
+	if len(s) == 11 {
+		for i := 8; i < 11; i++ {
+			if !IsAlphanumeric(s[i]) {
+				return BIC{}, errors.New("branch code must be alphanumeric")
+			}
+		}
+		if s[8] == 'X' && s[8:] != "XXX" {
+			return BIC{}, fmt.Errorf("branch code %q can't start with X", s[8:])
+		}
+		bic.Branch = s[8:]
+	}
+
+	return bic, nil
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseBIC(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    BIC
+		wantErr bool
+	}{
+		{
+			name:  "headquarter",
+			input: "BANKPLPWXXX",
+			want:  BIC{Institution: "BANK", Country: "PL", Location: "PW", Branch: "XXX"},
+		},
+		{
+			name:  "eight characters",
+			input: "DEUTDEFF",
+			want:  BIC{Institution: "DEUT", Country: "DE", Location: "FF"},
+		},
+		{
+			name:  "alphanumeric prefix",
+			input: "1BANDEFF123",
+			want:  BIC{Institution: "1BAN", Country: "DE", Location: "FF", Branch: "123"},
+		},
+		{
+			name:  "test",
+			input: "BANKUS00XXX",
+			want:  BIC{Institution: "BANK", Country: "US", Location: "00", Branch: "XXX", IsTest: true},
+		},
+		{
+			name:  "passive",
+			input: "BANKUS31NYC",
+			want:  BIC{Institution: "BANK", Country: "US", Location: "31", Branch: "NYC", IsPassive: true},
+		},
+		{
+			name:  "reverse billing",
+			input: "BANKUS22",
+			want:  BIC{Institution: "BANK", Country: "US", Location: "22", IsReverseBilling: true},
+		},
+		{name: "too short", input: "BANKUS2", wantErr: true},
+		{name: "too long", input: "BANKUS22XXXX", wantErr: true},
+		{name: "lowercase", input: "bankus22", wantErr: true},
+		{name: "digit in country", input: "BANK1S22", wantErr: true},
+		{name: "letter O in location", input: "BANKUSPO", wantErr: true},
+		{name: "symbol in branch", input: "BANKUS22A-C", wantErr: true},
+		{name: "branch starting with X", input: "BANKUS22XAB", wantErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			bic, err := ParseBIC(tc.input)
+			if tc.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.want, bic)
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// ChangeType is the kind of write a change records.
+type ChangeType string
+
+const (
+	ChangeCreated ChangeType = "created"
+	ChangeUpdated ChangeType = "updated"
+	ChangeDeleted ChangeType = "deleted"
+)
+
+// Change is an entry of the change feed, recorded along with every write to
+// a code.
+type Change struct {
+	// ID orders the changes. Changes are committed in the order of their
+	// IDs, so the last ID a consumer saw is where it resumes the feed.
+	ID        int64      `json:"id"`
+	Type      ChangeType `json:"type"`
+	SwiftCode string     `json:"swiftCode"`
+	// Version is the version of the code after the change.
+	Version int `json:"version"`
+	// Record is the new record of created and updated codes.
+	Record    *SwiftCode `json:"record,omitempty"`
+	ChangedAt time.Time  `json:"changedAt"`
+}
@@ -0,0 +1,253 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+type IBAN struct {
+	IBAN        string `json:"iban" xml:"iban"`
+	CountryISO2 string `json:"countryISO2" xml:"countryISO2"`
+	CheckDigits string `json:"checkDigits" xml:"checkDigits"`
+	BBAN        string `json:"bban" xml:"bban"`
+	BankCode    string `json:"bankCode" xml:"bankCode"`
+	BranchCode  string `json:"branchCode,omitempty" xml:"branchCode,omitempty"`
+}
+
+// BankCode maps a national bank code, as found in IBANs, to a SWIFT code.
+type BankCode struct {
+	CountryISO2 string `json:"countryISO2"`
+	BankCode    string `json:"bankCode"`
+	SwiftCode   string `json:"swiftCode"`
+}
+
+// BankCodeScheme returns the scheme of national identifiers the bank codes in
+// IBANs of the country are stored under: its national scheme when they are
+// identifiers of that scheme, as the Bankleitzahl is in German IBANs, or
+// iban- followed by the country code otherwise.
+func BankCodeScheme(countryISO2 string) string {
+	if scheme, ok := ibanSchemes[strings.ToUpper(countryISO2)]; ok {
+		return scheme
+	}
+	return "iban-" + strings.ToLower(countryISO2)
+}
+
+// ibanSchemes are the national schemes of the bank codes in IBANs.
+var ibanSchemes = map[string]string{
+	"DE": SchemeBLZ,
+}
+
+// branchSchemes are the national schemes of the branch codes in IBANs, as
+// the sort code is in British IBANs.
+var branchSchemes = map[string]string{
+	"GB": SchemeSortCode,
+}
+
+// NationalIDs returns the national identifiers the bank of the IBAN may be
+// mapped from, by scheme: its bank code, alone and followed by its branch
+// code, under BankCodeScheme, and its branch code under the national scheme
+// of branch codes of its country.
+func (iban IBAN) NationalIDs() map[string][]string {
+	scheme := BankCodeScheme(iban.CountryISO2)
+	ids := map[string][]string{scheme: {iban.BankCode}}
+	if iban.BranchCode == "" {
+		return ids
+	}
+	ids[scheme] = append(ids[scheme], iban.BankCode+iban.BranchCode)
+	if branchScheme, ok := branchSchemes[iban.CountryISO2]; ok {
+		ids[branchScheme] = append(ids[branchScheme], iban.BranchCode)
+	}
+	return ids
+}
+
+// NationalID returns the bank code as a national identifier.
+func (bc BankCode) NationalID() NationalID {
+	return NationalID{Scheme: BankCodeScheme(bc.CountryISO2), Identifier: bc.BankCode, SwiftCode: bc.SwiftCode}
+}
+
+type ibanFormat struct {
+	length int
+	// Positions of the bank and branch identifiers within the BBAN.
+	bankStart, bankEnd     int
+	branchStart, branchEnd int
+}
+
+// ibanFormats follows the SWIFT IBAN registry.
+var ibanFormats = map[string]ibanFormat{
+	"AD": {24, 0, 4, 4, 8},
+	"AE": {23, 0, 3, 0, 0},
+	"AL": {28, 0, 3, 3, 7},
+	"AT": {20, 0, 5, 0, 0},
+	"AZ": {28, 0, 4, 0, 0},
+	"BA": {20, 0, 3, 3, 6},
+	"BE": {16, 0, 3, 0, 0},
+	"BG": {22, 0, 4, 4, 8},
+	"BH": {22, 0, 4, 0, 0},
+	"BI": {27, 0, 5, 5, 10},
+	"BR": {29, 0, 8, 8, 13},
+	"BY": {28, 0, 4, 0, 0},
+	"CH": {21, 0, 5, 0, 0},
+	"CR": {22, 0, 4, 0, 0},
+	"CY": {28, 0, 3, 3, 8},
+	"CZ": {24, 0, 4, 0, 0},
+	"DE": {22, 0, 8, 0, 0},
+	"DJ": {27, 0, 5, 5, 10},
+	"DK": {18, 0, 4, 0, 0},
+	"DO": {28, 0, 4, 0, 0},
+	"EE": {20, 0, 2, 0, 0},
+	"EG": {29, 0, 4, 4, 8},
+	"ES": {24, 0, 4, 4, 8},
+	"FI": {18, 0, 3, 0, 0},
+	"FO": {18, 0, 4, 0, 0},
+	"FR": {27, 0, 5, 5, 10},
+	"GB": {22, 0, 4, 4, 10},
+	"GE": {22, 0, 2, 0, 0},
+	"GI": {23, 0, 4, 0, 0},
+	"GL": {18, 0, 4, 0, 0},
+	"GR": {27, 0, 3, 3, 7},
+	"GT": {28, 0, 4, 0, 0},
+	"HR": {21, 0, 7, 0, 0},
+	"HU": {28, 0, 3, 3, 7},
+	"IE": {22, 0, 4, 4, 10},
+	"IL": {23, 0, 3, 3, 6},
+	"IQ": {23, 0, 4, 4, 7},
+	"IS": {26, 0, 2, 2, 4},
+	"IT": {27, 1, 6, 6, 11},
+	"JO": {30, 0, 4, 4, 8},
+	"KW": {30, 0, 4, 0, 0},
+	"KZ": {20, 0, 3, 0, 0},
+	"LB": {28, 0, 4, 0, 0},
+	"LC": {32, 0, 4, 0, 0},
+	"LI": {21, 0, 5, 0, 0},
+	"LT": {20, 0, 5, 0, 0},
+	"LU": {20, 0, 3, 0, 0},
+	"LV": {21, 0, 4, 0, 0},
+	"LY": {25, 0, 3, 3, 6},
+	"MC": {27, 0, 5, 5, 10},
+	"MD": {24, 0, 2, 0, 0},
+	"ME": {22, 0, 3, 0, 0},
+	"MK": {19, 0, 3, 0, 0},
+	"MR": {27, 0, 5, 5, 10},
+	"MT": {31, 0, 4, 4, 9},
+	"MU": {30, 0, 6, 6, 8},
+	"NL": {18, 0, 4, 0, 0},
+	"NO": {15, 0, 4, 0, 0},
+	"PK": {24, 0, 4, 0, 0},
+	"PL": {28, 0, 3, 3, 8},
+	"PS": {29, 0, 4, 0, 0},
+	"PT": {25, 0, 4, 4, 8},
+	"QA": {29, 0, 4, 0, 0},
+	"RO": {24, 0, 4, 0, 0},
+	"RS": {22, 0, 3, 0, 0},
+	"RU": {33, 0, 9, 9, 14},
+	"SA": {24, 0, 2, 0, 0},
+	"SC": {31, 0, 6, 6, 8},
+	"SD": {18, 0, 2, 0, 0},
+	"SE": {24, 0, 3, 0, 0},
+	"SI": {19, 0, 5, 0, 0},
+	"SK": {24, 0, 4, 0, 0},
+	"SM": {27, 1, 6, 6, 11},
+	"SO": {23, 0, 4, 4, 7},
+	"ST": {25, 0, 4, 4, 8},
+	"SV": {28, 0, 4, 0, 0},
+	"TL": {23, 0, 3, 0, 0},
+	"TN": {24, 0, 2, 2, 5},
+	"TR": {26, 0, 5, 0, 0},
+	"UA": {29, 0, 6, 0, 0},
+	"VA": {22, 0, 3, 0, 0},
+	"VG": {24, 0, 4, 0, 0},
+	"XK": {20, 0, 2, 2, 4},
+}
+
+// ParseIBAN validates an IBAN, given in electronic or print format, against
+// the length registered for its country and the ISO 7064 mod 97-10 checksum,
+// and extracts the national bank identifier.
+func ParseIBAN(s string) (IBAN, error) {
+	s = strings.ToUpper(strings.ReplaceAll(s, " ", ""))
+	if len(s) < 4 {
+		return IBAN{}, errors.New("is too short")
+	}
+	for i := range len(s) {
+		if !IsAlphanumeric(s[i]) {
+			return IBAN{}, errors.New("must be alphanumeric")
+		}
+	}
+
+	country := s[:2]
+	format, ok := ibanFormats[country]
+	if !ok {
+		return IBAN{}, fmt.Errorf("has an unknown country code %q", country)
+	}
+	if len(s) != format.length {
+		return IBAN{}, fmt.Errorf("must be %d characters long for %s", format.length, country)
+	}
+	if !ibanChecksumValid(s) {
+		return IBAN{}, errors.New("has an invalid checksum")
+	}
+
+	bban := s[4:]
+	iban := IBAN{
+		IBAN:        s,
+		CountryISO2: country,
+		CheckDigits: s[2:4],
+		BBAN:        bban,
+		BankCode:    bban[format.bankStart:format.bankEnd],
+		BranchCode:  bban[format.branchStart:format.branchEnd],
+	}
+	return iban, nil
+}
+
+func ibanChecksumValid(s string) bool {
+	rearranged := s[4:] + s[:4]
+	remainder := 0
+	for i := range len(rearranged) {
+		ch := rearranged[i]
+		if isAlpha(ch) {
+			v := int(ch-'A') + 10
+			remainder = (remainder*100 + v) % 97
+		} else {
+			remainder = (remainder*10 + int(ch-'0')) % 97
+		}
+	}
+	return remainder == 1
+}
+
+func (bc *BankCode) Validate() error {
+	var fe FieldErrors
+
+	bc.CountryISO2 = strings.ToUpper(bc.CountryISO2)
+	bc.BankCode = strings.ToUpper(strings.ReplaceAll(bc.BankCode, " ", ""))
+	bc.SwiftCode = strings.ToUpper(bc.SwiftCode)
+
+	if bc.CountryISO2 == "" {
+		fe = append(fe, FieldError{"countryISO2", "is required"})
+	} else if !countryCodeRegex.MatchString(bc.CountryISO2) {
+		fe = append(fe, FieldError{"countryISO2", "must consist of two ASCII letters"})
+	}
+
+	if bc.BankCode == "" {
+		fe = append(fe, FieldError{"bankCode", "is required"})
+	} else {
+		for i := range len(bc.BankCode) {
+			if !IsAlphanumeric(bc.BankCode[i]) {
+				fe = append(fe, FieldError{"bankCode", "must be alphanumeric"})
+				break
+			}
+		}
+	}
+
+	if bc.SwiftCode == "" {
+		fe = append(fe, FieldError{"swiftCode", "is required"})
+	} else if _, err := ParseBIC(bc.SwiftCode); err != nil {
+		fe = append(fe, FieldError{"swiftCode", "is invalid"})
+	} else if len(bc.SwiftCode) == 8 {
+		bc.SwiftCode += "XXX"
+	}
+
+	if len(fe) > 0 {
+		return fe
+	}
+
+	return nil
+}
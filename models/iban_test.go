@@ -0,0 +1,83 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseIBAN(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    IBAN
+		wantErr bool
+	}{
+		{
+			name:  "germany",
+			input: "DE89370400440532013000",
+			want:  IBAN{IBAN: "DE89370400440532013000", CountryISO2: "DE", CheckDigits: "89", BBAN: "370400440532013000", BankCode: "37040044"},
+		},
+		{
+			name:  "print format",
+			input: "gb29 nwbk 6016 1331 9268 19",
+			want:  IBAN{IBAN: "GB29NWBK60161331926819", CountryISO2: "GB", CheckDigits: "29", BBAN: "NWBK60161331926819", BankCode: "NWBK", BranchCode: "601613"},
+		},
+		{
+			name:  "italy skips the check character",
+			input: "IT60X0542811101000000123456",
+			want:  IBAN{IBAN: "IT60X0542811101000000123456", CountryISO2: "IT", CheckDigits: "60", BBAN: "X0542811101000000123456", BankCode: "05428", BranchCode: "11101"},
+		},
+		{name: "bad checksum", input: "DE88370400440532013000", wantErr: true},
+		{name: "too short for country", input: "DE8937040044053201300", wantErr: true},
+		{name: "unknown country", input: "US89370400440532013000", wantErr: true},
+		{name: "symbol", input: "DE89-370400440532013000", wantErr: true},
+		{name: "empty", input: "", wantErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			iban, err := ParseIBAN(tc.input)
+			if tc.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.want, iban)
+			}
+		})
+	}
+}
+
+func TestBankCodeValidate(t *testing.T) {
+	bc := BankCode{CountryISO2: "de", BankCode: "3704 0044", SwiftCode: "cobadeff"}
+	assert.NoError(t, bc.Validate())
+	assert.Equal(t, BankCode{CountryISO2: "DE", BankCode: "37040044", SwiftCode: "COBADEFFXXX"}, bc)
+
+	bc = BankCode{CountryISO2: "DEU", BankCode: "37-04", SwiftCode: "INVALID"}
+	err := bc.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "countryISO2")
+	assert.Contains(t, err.Error(), "bankCode")
+	assert.Contains(t, err.Error(), "swiftCode")
+}
+
+func TestBankCodeNationalID(t *testing.T) {
+	bc := BankCode{CountryISO2: "DE", BankCode: "37040044", SwiftCode: "COBADEFFXXX"}
+	assert.Equal(t, NationalID{Scheme: SchemeBLZ, Identifier: "37040044", SwiftCode: "COBADEFFXXX"}, bc.NationalID())
+
+	bc = BankCode{CountryISO2: "PL", BankCode: "109", SwiftCode: "BANKPLPWXXX"}
+	assert.Equal(t, NationalID{Scheme: "iban-pl", Identifier: "109", SwiftCode: "BANKPLPWXXX"}, bc.NationalID())
+}
+
+func TestIBANNationalIDs(t *testing.T) {
+	assert.Equal(t, map[string][]string{
+		SchemeBLZ: {"37040044"},
+	}, IBAN{CountryISO2: "DE", BankCode: "37040044"}.NationalIDs())
+	assert.Equal(t, map[string][]string{
+		"iban-gb":      {"NWBK", "NWBK601613"},
+		SchemeSortCode: {"601613"},
+	}, IBAN{CountryISO2: "GB", BankCode: "NWBK", BranchCode: "601613"}.NationalIDs())
+	assert.Equal(t, map[string][]string{
+		"iban-pl": {"109", "10901014"},
+	}, IBAN{CountryISO2: "PL", BankCode: "109", BranchCode: "01014"}.NationalIDs())
+}
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"net/http"
+	"time"
+)
+
+// IdempotencyRecord holds the response to the first request a client made
+// with an Idempotency-Key, which is replayed when the request is retried.
+type IdempotencyRecord struct {
+	// Key identifies the client and the key it sent.
+	Key string
+	// RequestHash tells retries apart from other requests reusing the key.
+	RequestHash string
+	// Status is zero while the first request is being handled.
+	Status      int
+	ContentType string
+	// Header holds the response headers replayed along with the body.
+	Header    http.Header
+	Body      []byte
+	CreatedAt time.Time
+}
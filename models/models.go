@@ -3,12 +3,18 @@ package models
 import (
 	"regexp"
 	"strings"
+	"time"
 )
 
-var (
-	swiftCodeRegex   = regexp.MustCompile(`^[A-Z]{6}[A-Z0-9]{5}$`)
-	countryCodeRegex = regexp.MustCompile(`^[A-Z]{2}$`)
-)
+var countryCodeRegex = regexp.MustCompile(`^[A-Z]{2}$`)
+
+// DateLayout is the format of validity dates.
+const DateLayout = time.DateOnly
+
+// Today returns the current date in UTC, formatted with DateLayout.
+func Today() string {
+	return time.Now().UTC().Format(DateLayout)
+}
 
 type FieldError struct {
 	Name    string `json:"name"`
@@ -31,12 +37,29 @@ func (fe FieldErrors) Error() string {
 }
 
 type SwiftCode struct {
-	Address       string `json:"address"`
-	BankName      string `json:"bankName"`
-	CountryISO2   string `json:"countryISO2"`
-	CountryName   string `json:"countryName,omitempty"`
-	IsHeadquarter bool   `json:"isHeadquarter"`
-	SwiftCode     string `json:"swiftCode"`
+	Address       string `json:"address" xml:"address"`
+	BankName      string `json:"bankName" xml:"bankName"`
+	CountryISO2   string `json:"countryISO2" xml:"countryISO2"`
+	CountryName   string `json:"countryName,omitempty" xml:"countryName,omitempty"`
+	IsHeadquarter bool   `json:"isHeadquarter" xml:"isHeadquarter"`
+	// IsPassive, IsReverseBilling and IsTest are derived from the location
+	// code by Validate and by the database.
+	IsPassive        bool   `json:"isPassive" xml:"isPassive"`
+	IsReverseBilling bool   `json:"isReverseBilling" xml:"isReverseBilling"`
+	IsTest           bool   `json:"isTest" xml:"isTest"`
+	SwiftCode        string `json:"swiftCode" xml:"swiftCode"`
+	// ValidFrom and ValidTo bound the dates the code is valid on. ValidTo is
+	// exclusive and empty while the code is current. ValidFrom is empty for
+	// codes stored before validity was tracked.
+	ValidFrom string `json:"validFrom,omitempty" xml:"validFrom,omitempty"`
+	ValidTo   string `json:"validTo,omitempty" xml:"validTo,omitempty"`
+	// Version counts the changes to the code across all of its records, and
+	// with CreatedAt and UpdatedAt of the record makes up the validators of
+	// responses. They're only filled in by lookups of codes, branches and
+	// countries.
+	Version   int       `json:"-" xml:"-"`
+	CreatedAt time.Time `json:"-" xml:"-"`
+	UpdatedAt time.Time `json:"-" xml:"-"`
 }
 
 func (code *SwiftCode) Validate() error {
@@ -62,15 +85,31 @@ func (code *SwiftCode) Validate() error {
 
 	if code.SwiftCode == "" {
 		fe = append(fe, FieldError{"swiftCode", "is required"})
-	} else if !swiftCodeRegex.MatchString(code.SwiftCode) {
+	} else if bic, err := ParseBIC(code.SwiftCode); err != nil || bic.Branch == "" {
 		fe = append(fe, FieldError{"swiftCode", "is invalid"})
 	} else {
-		if code.SwiftCode[4:6] != code.CountryISO2 {
+		if bic.Country != code.CountryISO2 {
 			fe = append(fe, FieldError{"swiftCode", "doesn't match countryISO2"})
 		}
-		if (code.SwiftCode[8:] == "XXX") != code.IsHeadquarter {
+		if (bic.Branch == "XXX") != code.IsHeadquarter {
 			fe = append(fe, FieldError{"isHeadquarter", "doesn't match swiftCode"})
 		}
+		code.IsTest = bic.IsTest
+		code.IsPassive = bic.IsPassive
+		code.IsReverseBilling = bic.IsReverseBilling
+	}
+
+	if code.ValidFrom != "" {
+		if _, err := time.Parse(DateLayout, code.ValidFrom); err != nil {
+			fe = append(fe, FieldError{"validFrom", "must be a YYYY-MM-DD date"})
+		}
+	}
+	if code.ValidTo != "" {
+		if _, err := time.Parse(DateLayout, code.ValidTo); err != nil {
+			fe = append(fe, FieldError{"validTo", "must be a YYYY-MM-DD date"})
+		} else if code.ValidFrom != "" && code.ValidTo <= code.ValidFrom {
+			fe = append(fe, FieldError{"validTo", "must be after validFrom"})
+		}
 	}
 
 	if len(fe) > 0 {
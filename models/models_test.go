@@ -38,6 +38,17 @@ func TestValidateSwiftCode(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "valid code with numeric business party prefix",
+			input: SwiftCode{
+				BankName:      "Bank of Test",
+				CountryISO2:   "PL",
+				CountryName:   "Poland",
+				IsHeadquarter: true,
+				SwiftCode:     "1234PLPWXXX",
+			},
+			wantErr: false,
+		},
 		{
 			name:     "missing required fields",
 			input:    SwiftCode{},
@@ -68,6 +79,30 @@ func TestValidateSwiftCode(t *testing.T) {
 			wantErr:  true,
 			wantMsgs: []string{"swiftCode"},
 		},
+		{
+			name: "eight character swift code",
+			input: SwiftCode{
+				BankName:      "Bank",
+				CountryISO2:   "PL",
+				CountryName:   "Poland",
+				SwiftCode:     "BANKPLPW",
+				IsHeadquarter: true,
+			},
+			wantErr:  true,
+			wantMsgs: []string{"swiftCode"},
+		},
+		{
+			name: "letter O as second location character",
+			input: SwiftCode{
+				BankName:      "Bank",
+				CountryISO2:   "PL",
+				CountryName:   "Poland",
+				SwiftCode:     "BANKPLPOXXX",
+				IsHeadquarter: true,
+			},
+			wantErr:  true,
+			wantMsgs: []string{"swiftCode"},
+		},
 		{
 			name: "country code mismatch in swift",
 			input: SwiftCode{
@@ -92,6 +127,47 @@ func TestValidateSwiftCode(t *testing.T) {
 			wantErr:  true,
 			wantMsgs: []string{"isHeadquarter"},
 		},
+		{
+			name: "validity dates",
+			input: SwiftCode{
+				BankName:      "Bank",
+				CountryISO2:   "PL",
+				CountryName:   "Poland",
+				SwiftCode:     "BANKPLPWXXX",
+				IsHeadquarter: true,
+				ValidFrom:     "2026-01-01",
+				ValidTo:       "2026-03-01",
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid validity dates",
+			input: SwiftCode{
+				BankName:      "Bank",
+				CountryISO2:   "PL",
+				CountryName:   "Poland",
+				SwiftCode:     "BANKPLPWXXX",
+				IsHeadquarter: true,
+				ValidFrom:     "01.01.2026",
+				ValidTo:       "2026-02-30",
+			},
+			wantErr:  true,
+			wantMsgs: []string{"validFrom", "validTo"},
+		},
+		{
+			name: "validity ending before it starts",
+			input: SwiftCode{
+				BankName:      "Bank",
+				CountryISO2:   "PL",
+				CountryName:   "Poland",
+				SwiftCode:     "BANKPLPWXXX",
+				IsHeadquarter: true,
+				ValidFrom:     "2026-03-01",
+				ValidTo:       "2026-03-01",
+			},
+			wantErr:  true,
+			wantMsgs: []string{"validTo"},
+		},
 	}
 
 	for _, tc := range tests {
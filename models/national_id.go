@@ -0,0 +1,100 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Schemes of national bank identifiers.
+const (
+	SchemeSortCode = "sort-code"
+	SchemeABA      = "aba"
+	SchemeBLZ      = "blz"
+)
+
+// ErrUnknownScheme is returned for national identifier schemes other than
+// the ones listed above.
+var ErrUnknownScheme = errors.New("unknown scheme")
+
+// NationalID maps a national bank identifier, such as a UK sort code, a US
+// ABA routing number or a German Bankleitzahl, to a SWIFT code.
+type NationalID struct {
+	Scheme     string `json:"scheme"`
+	Identifier string `json:"identifier"`
+	SwiftCode  string `json:"swiftCode"`
+}
+
+var nationalIDLengths = map[string]int{
+	SchemeSortCode: 6,
+	SchemeABA:      9,
+	SchemeBLZ:      8,
+}
+
+// ParseNationalID normalizes a national identifier, dropping the spaces and
+// dashes it is often printed with, and checks its length and, for ABA
+// routing numbers, its check digit.
+func ParseNationalID(scheme, id string) (string, error) {
+	length, ok := nationalIDLengths[strings.ToLower(scheme)]
+	if !ok {
+		return "", ErrUnknownScheme
+	}
+
+	id = strings.NewReplacer(" ", "", "-", "").Replace(id)
+	if len(id) != length {
+		return "", fmt.Errorf("must be %d digits long", length)
+	}
+	for i := range len(id) {
+		if id[i] < '0' || id[i] > '9' {
+			return "", errors.New("must consist of digits")
+		}
+	}
+	if strings.ToLower(scheme) == SchemeABA && !abaChecksumValid(id) {
+		return "", errors.New("has an invalid check digit")
+	}
+
+	return id, nil
+}
+
+// abaChecksumValid checks the 3-7-1 weighted checksum of a routing number.
+func abaChecksumValid(id string) bool {
+	weights := [3]int{3, 7, 1}
+	sum := 0
+	for i := range len(id) {
+		sum += int(id[i]-'0') * weights[i%3]
+	}
+	return sum%10 == 0
+}
+
+func (n *NationalID) Validate() error {
+	var fe FieldErrors
+
+	n.Scheme = strings.ToLower(n.Scheme)
+	n.SwiftCode = strings.ToUpper(n.SwiftCode)
+
+	if n.Scheme == "" {
+		fe = append(fe, FieldError{"scheme", "is required"})
+	} else if id, err := ParseNationalID(n.Scheme, n.Identifier); errors.Is(err, ErrUnknownScheme) {
+		fe = append(fe, FieldError{"scheme", "must be one of sort-code, aba or blz"})
+	} else if n.Identifier == "" {
+		fe = append(fe, FieldError{"identifier", "is required"})
+	} else if err != nil {
+		fe = append(fe, FieldError{"identifier", err.Error()})
+	} else {
+		n.Identifier = id
+	}
+
+	if n.SwiftCode == "" {
+		fe = append(fe, FieldError{"swiftCode", "is required"})
+	} else if _, err := ParseBIC(n.SwiftCode); err != nil {
+		fe = append(fe, FieldError{"swiftCode", "is invalid"})
+	} else if len(n.SwiftCode) == 8 {
+		n.SwiftCode += "XXX"
+	}
+
+	if len(fe) > 0 {
+		return fe
+	}
+
+	return nil
+}
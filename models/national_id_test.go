@@ -0,0 +1,58 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseNationalID(t *testing.T) {
+	tests := []struct {
+		name    string
+		scheme  string
+		input   string
+		want    string
+		wantErr error
+	}{
+		{name: "sort code", scheme: "sort-code", input: "60-16-13", want: "601613"},
+		{name: "routing number", scheme: "aba", input: "021000021", want: "021000021"},
+		{name: "routing number uppercase scheme", scheme: "ABA", input: "011000015", want: "011000015"},
+		{name: "blz", scheme: "blz", input: "370 400 44", want: "37040044"},
+		{name: "bad check digit", scheme: "aba", input: "021000022", wantErr: assert.AnError},
+		{name: "too short", scheme: "sort-code", input: "60161", wantErr: assert.AnError},
+		{name: "letters", scheme: "blz", input: "3704004A", wantErr: assert.AnError},
+		{name: "unknown scheme", scheme: "bsb", input: "062000", wantErr: ErrUnknownScheme},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			id, err := ParseNationalID(tc.scheme, tc.input)
+			switch tc.wantErr {
+			case nil:
+				assert.NoError(t, err)
+				assert.Equal(t, tc.want, id)
+			case assert.AnError:
+				assert.Error(t, err)
+			default:
+				assert.ErrorIs(t, err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestNationalIDValidate(t *testing.T) {
+	id := NationalID{Scheme: "Sort-Code", Identifier: "60-16-13", SwiftCode: "nwbkgb2l"}
+	assert.NoError(t, id.Validate())
+	assert.Equal(t, NationalID{Scheme: "sort-code", Identifier: "601613", SwiftCode: "NWBKGB2LXXX"}, id)
+
+	id = NationalID{Scheme: "bsb", Identifier: "062000", SwiftCode: "INVALID"}
+	err := id.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "scheme")
+	assert.Contains(t, err.Error(), "swiftCode")
+
+	id = NationalID{Scheme: "aba", Identifier: "021000022", SwiftCode: "CHASUS33"}
+	err = id.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "identifier has an invalid check digit")
+}
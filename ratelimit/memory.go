@@ -0,0 +1,52 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often buckets that have filled up are dropped.
+const sweepInterval = time.Minute
+
+// Memory keeps the buckets in memory, so each server limits its clients on
+// its own.
+type Memory struct {
+	mu        sync.Mutex
+	buckets   map[string]*memoryBucket
+	lastSweep time.Time
+}
+
+type memoryBucket struct {
+	bucket
+	full time.Time
+}
+
+func NewMemory() *Memory {
+	return &Memory{buckets: make(map[string]*memoryBucket)}
+}
+
+func (m *Memory) Take(c context.Context, key string, limit Limit, now time.Time) (Result, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if now.Sub(m.lastSweep) >= sweepInterval {
+		for key, b := range m.buckets {
+			if !b.full.After(now) {
+				delete(m.buckets, key)
+			}
+		}
+		m.lastSweep = now
+	}
+
+	b, ok := m.buckets[key]
+	if !ok {
+		b = &memoryBucket{bucket: bucket{tokens: float64(limit.Requests), updated: now}}
+		m.buckets[key] = b
+	}
+	result := b.take(limit, now)
+	b.full = b.fullAt(limit)
+	return result, nil
+}
+
+func (m *Memory) Close() {}
@@ -0,0 +1,90 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Postgres keeps the buckets in a PostgreSQL table, so that servers sharing
+// the database share the limits of their clients.
+type Postgres struct {
+	pool *pgxpool.Pool
+
+	mu        sync.Mutex
+	lastSweep time.Time
+}
+
+func ConnectPostgres(c context.Context, connStr string) (*Postgres, error) {
+	pool, err := pgxpool.New(c, connStr)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to create rate limit connection pool: %w", err)
+	}
+	sql := `
+	CREATE TABLE IF NOT EXISTS rate_limits (
+		key TEXT PRIMARY KEY,
+		tokens DOUBLE PRECISION NOT NULL,
+		updated_at TIMESTAMPTZ NOT NULL,
+		full_at TIMESTAMPTZ NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS rate_limits_full_at_idx ON rate_limits (full_at);
+	`
+	if _, err := pool.Exec(c, sql); err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("Failed to create rate limit table: %w", err)
+	}
+	return &Postgres{pool: pool}, nil
+}
+
+func (p *Postgres) Take(c context.Context, key string, limit Limit, now time.Time) (Result, error) {
+	if err := p.sweep(c, now); err != nil {
+		return Result{}, err
+	}
+
+	var result Result
+	err := pgx.BeginFunc(c, p.pool, func(tx pgx.Tx) error {
+		// A new client gets a full bucket. The row lock makes concurrent
+		// requests of the client wait for each other.
+		sql := `
+		INSERT INTO rate_limits (key, tokens, updated_at, full_at) VALUES ($1, $2, $3, $3)
+		ON CONFLICT (key) DO NOTHING;
+		`
+		if _, err := tx.Exec(c, sql, key, float64(limit.Requests), now); err != nil {
+			return err
+		}
+		var b bucket
+		sql = `SELECT tokens, updated_at FROM rate_limits WHERE key = $1 FOR UPDATE;`
+		if err := tx.QueryRow(c, sql, key).Scan(&b.tokens, &b.updated); err != nil {
+			return err
+		}
+
+		result = b.take(limit, now)
+		sql = `UPDATE rate_limits SET tokens = $2, updated_at = $3, full_at = $4 WHERE key = $1;`
+		_, err := tx.Exec(c, sql, key, b.tokens, b.updated, b.fullAt(limit))
+		return err
+	})
+	return result, err
+}
+
+// sweep drops the buckets that have filled up, at most once per
+// sweepInterval.
+func (p *Postgres) sweep(c context.Context, now time.Time) error {
+	p.mu.Lock()
+	if now.Sub(p.lastSweep) < sweepInterval {
+		p.mu.Unlock()
+		return nil
+	}
+	p.lastSweep = now
+	p.mu.Unlock()
+
+	_, err := p.pool.Exec(c, `DELETE FROM rate_limits WHERE full_at <= $1;`, now)
+	return err
+}
+
+func (p *Postgres) Close() {
+	p.pool.Close()
+}
@@ -0,0 +1,128 @@
+// Package ratelimit implements token bucket rate limiting, with buckets kept
+// in memory or shared between servers in PostgreSQL.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Limit allows Requests requests per period of Per. Clients may use all of
+// them in a burst, after which they're refilled evenly over the period. The
+// zero Limit doesn't limit anything.
+type Limit struct {
+	Requests int
+	Per      time.Duration
+}
+
+// ParseLimit parses a limit written like "100/1m". An empty string or "0"
+// is the zero Limit.
+func ParseLimit(s string) (Limit, error) {
+	if s == "" || s == "0" {
+		return Limit{}, nil
+	}
+	requests, per, ok := strings.Cut(s, "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("limit %q must look like 100/1m", s)
+	}
+	n, err := strconv.Atoi(requests)
+	if err != nil || n <= 0 {
+		return Limit{}, fmt.Errorf("limit %q must allow a positive number of requests", s)
+	}
+	d, err := time.ParseDuration(per)
+	if err != nil || d <= 0 {
+		return Limit{}, fmt.Errorf("limit %q must have a positive period", s)
+	}
+	return Limit{Requests: n, Per: d}, nil
+}
+
+func (l Limit) String() string {
+	if l.IsZero() {
+		return ""
+	}
+	return fmt.Sprintf("%d/%s", l.Requests, l.Per)
+}
+
+func (l Limit) IsZero() bool {
+	return l.Requests == 0
+}
+
+func (l Limit) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+func (l *Limit) UnmarshalText(text []byte) error {
+	parsed, err := ParseLimit(string(text))
+	if err != nil {
+		return err
+	}
+	*l = parsed
+	return nil
+}
+
+// rate is the number of tokens added to a bucket per second.
+func (l Limit) rate() float64 {
+	return float64(l.Requests) / l.Per.Seconds()
+}
+
+// Result is the outcome of taking a token.
+type Result struct {
+	Allowed bool
+	// Remaining is the number of requests that can be made right away.
+	Remaining int
+	// RetryAfter is how long to wait for the next request to be allowed,
+	// zero when it is allowed.
+	RetryAfter time.Duration
+	// Reset is how long it takes for the bucket to fill up again.
+	Reset time.Duration
+}
+
+// Store keeps the buckets of the clients.
+type Store interface {
+	// Take takes a token from the bucket of key for a request made at now,
+	// creating a full bucket if key has none.
+	Take(c context.Context, key string, limit Limit, now time.Time) (Result, error)
+	Close()
+}
+
+// bucket holds the tokens left at a point in time.
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// take refills the bucket for the time passed since it was last updated and
+// takes a token from it if there is one.
+func (b *bucket) take(limit Limit, now time.Time) Result {
+	if elapsed := now.Sub(b.updated).Seconds(); elapsed > 0 {
+		b.tokens += elapsed * limit.rate()
+		b.updated = now
+	}
+	// Buckets never hold more than a burst, even if the limit was lowered
+	// since they were last used.
+	b.tokens = math.Min(float64(limit.Requests), b.tokens)
+
+	result := Result{Allowed: b.tokens >= 1}
+	if result.Allowed {
+		b.tokens--
+	} else {
+		result.RetryAfter = seconds((1 - b.tokens) / limit.rate())
+	}
+	result.Remaining = int(b.tokens)
+	result.Reset = b.fullAt(limit).Sub(now)
+	return result
+}
+
+// fullAt is when the bucket fills up again. A full bucket is the same as no
+// bucket, so buckets can be dropped from then on.
+func (b *bucket) fullAt(limit Limit) time.Time {
+	return b.updated.Add(seconds((float64(limit.Requests) - b.tokens) / limit.rate()))
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(math.Ceil(s * float64(time.Second)))
+}
@@ -0,0 +1,163 @@
+package ratelimit
+
+import (
+	"context"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/modules/postgres"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+var stores []namedStore
+
+type namedStore struct {
+	name  string
+	store Store
+}
+
+func forEachStore(t *testing.T, f func(t *testing.T, store Store)) {
+	for _, s := range stores {
+		t.Run(s.name, func(t *testing.T) { f(t, s.store) })
+	}
+}
+
+func TestParseLimit(t *testing.T) {
+	tests := []struct {
+		input string
+		limit Limit
+		err   bool
+	}{
+		{"100/1m", Limit{100, time.Minute}, false},
+		{"5/1s", Limit{5, time.Second}, false},
+		{"", Limit{}, false},
+		{"0", Limit{}, false},
+		{"100", Limit{}, true},
+		{"-1/1s", Limit{}, true},
+		{"10/0s", Limit{}, true},
+		{"10/soon", Limit{}, true},
+	}
+	for _, tc := range tests {
+		limit, err := ParseLimit(tc.input)
+		if tc.err {
+			assert.Error(t, err, tc.input)
+			continue
+		}
+		assert.NoError(t, err, tc.input)
+		assert.Equal(t, tc.limit, limit)
+		roundTrip, err := ParseLimit(limit.String())
+		assert.NoError(t, err)
+		assert.Equal(t, limit, roundTrip)
+	}
+}
+
+func TestTake(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		c := context.Background()
+		limit := Limit{Requests: 3, Per: 3 * time.Second}
+		now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+		key := "take-" + t.Name()
+
+		for remaining := 2; remaining >= 0; remaining-- {
+			result, err := store.Take(c, key, limit, now)
+			assert.NoError(t, err)
+			assert.True(t, result.Allowed)
+			assert.Equal(t, remaining, result.Remaining)
+			assert.Zero(t, result.RetryAfter)
+		}
+		assert.Equal(t, 3*time.Second, mustTake(t, store, key, limit, now).Reset)
+
+		result := mustTake(t, store, key, limit, now.Add(500*time.Millisecond))
+		assert.False(t, result.Allowed)
+		assert.Equal(t, 500*time.Millisecond, result.RetryAfter)
+		assert.Equal(t, 2500*time.Millisecond, result.Reset)
+
+		result = mustTake(t, store, key, limit, now.Add(time.Second))
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 0, result.Remaining)
+
+		// Other clients have buckets of their own.
+		result = mustTake(t, store, key+"-other", limit, now)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 2, result.Remaining)
+
+		// Buckets fill up to a burst at most.
+		result = mustTake(t, store, key, limit, now.Add(time.Hour))
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 2, result.Remaining)
+	})
+}
+
+func TestLoweredLimit(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+		key := "lowered-" + t.Name()
+		mustTake(t, store, key, Limit{Requests: 100, Per: time.Second}, now)
+
+		result := mustTake(t, store, key, Limit{Requests: 2, Per: time.Second}, now)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 1, result.Remaining)
+	})
+}
+
+func TestMemorySweep(t *testing.T) {
+	m := NewMemory()
+	limit := Limit{Requests: 10, Per: time.Minute}
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	mustTake(t, m, "a", limit, now)
+	mustTake(t, m, "b", limit, now.Add(58*time.Second))
+	assert.Len(t, m.buckets, 2)
+
+	// Bucket a is full again a few seconds after it was used.
+	mustTake(t, m, "c", limit, now.Add(time.Minute))
+	assert.Len(t, m.buckets, 2)
+	assert.NotContains(t, m.buckets, "a")
+}
+
+func mustTake(t *testing.T, store Store, key string, limit Limit, now time.Time) Result {
+	t.Helper()
+	result, err := store.Take(context.Background(), key, limit, now)
+	assert.NoError(t, err)
+	return result
+}
+
+func TestMain(m *testing.M) {
+	c := context.Background()
+
+	pgContainer, err := postgres.Run(
+		c,
+		"postgres:17",
+		testcontainers.WithWaitStrategy(
+			wait.ForLog("database system is ready to accept connections").WithOccurrence(2),
+			wait.ForListeningPort("5432/tcp"),
+		),
+	)
+	if err != nil {
+		log.Fatalf("Failed to start container: %v\n", err)
+	}
+	defer func() {
+		if err := testcontainers.TerminateContainer(pgContainer); err != nil {
+			log.Printf("Failed to terminate container: %v\n", err)
+		}
+	}()
+
+	connStr, err := pgContainer.ConnectionString(c)
+	if err != nil {
+		log.Fatalf("Failed to get connection string: %v\n", err)
+	}
+	pg, err := ConnectPostgres(c, connStr)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v\n", err)
+	}
+	defer pg.Close()
+
+	stores = []namedStore{
+		{"memory", NewMemory()},
+		{"postgres", pg},
+	}
+
+	m.Run()
+}
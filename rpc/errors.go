@@ -0,0 +1,83 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"log"
+	"runtime/debug"
+
+	"github.com/rtsncs/remitly-swift-api/database"
+	"github.com/rtsncs/remitly-swift-api/models"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// unaryRecover and streamRecover turn a panicking call into an INTERNAL
+// error, the way echo's Recover middleware turns a panicking request into a
+// 500, so that it doesn't take the server down.
+func unaryRecover(c context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = recovered(info.FullMethod, r)
+		}
+	}()
+	return handler(c, req)
+}
+
+func streamRecover(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = recovered(info.FullMethod, r)
+		}
+	}()
+	return handler(srv, stream)
+}
+
+func recovered(method string, r any) error {
+	log.Printf("%s panicked: %v\n%s", method, r, debug.Stack())
+	return status.Error(codes.Internal, "internal error")
+}
+
+func unaryErrors(c context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	resp, err := handler(c, req)
+	return resp, statusError(info.FullMethod, err)
+}
+
+func streamErrors(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	return statusError(info.FullMethod, handler(srv, stream))
+}
+
+// statusError maps the errors of the store and of validation to gRPC status
+// codes, the way the handler package maps them to HTTP statuses. Unexpected
+// errors are logged and hidden from clients.
+func statusError(method string, err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	var fieldErrors models.FieldErrors
+	switch {
+	case errors.As(err, &fieldErrors):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, database.ErrNotFound):
+		return status.Error(codes.NotFound, "not found")
+	case errors.Is(err, database.ErrDuplicate):
+		return status.Error(codes.AlreadyExists, "swift code already exists")
+	case errors.Is(err, database.ErrVersionMismatch):
+		return status.Error(codes.Aborted, "swift code was changed")
+	case errors.Is(err, database.ErrTimeout):
+		return status.Error(codes.DeadlineExceeded, "database timeout")
+	case errors.Is(err, database.ErrUnavailable):
+		return status.Error(codes.Unavailable, "database unavailable")
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	}
+	log.Printf("%s failed: %v\n", method, err)
+	return status.Error(codes.Internal, "internal error")
+}
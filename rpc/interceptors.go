@@ -0,0 +1,223 @@
+package rpc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/rtsncs/remitly-swift-api/database"
+	"github.com/rtsncs/remitly-swift-api/handler"
+	"github.com/rtsncs/remitly-swift-api/models"
+	"github.com/rtsncs/remitly-swift-api/rpc/swiftv1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// Metadata keys read by the interceptors, named like the HTTP headers of the
+// REST API.
+const (
+	metadataAPIKey         = "x-api-key"
+	metadataIdempotencyKey = "idempotency-key"
+	metadataRetryAfter     = "retry-after"
+)
+
+// contentTypeProto marks idempotency records holding a response of the gRPC
+// API, wrapped in an Any.
+const contentTypeProto = "application/grpc+proto"
+
+// writes are the methods that change codes.
+var writes = map[string]bool{
+	swiftv1.SwiftCodes_AddCode_FullMethodName:    true,
+	swiftv1.SwiftCodes_DeleteCode_FullMethodName: true,
+}
+
+// interceptors protect the calls of clients the way the handler package
+// protects their requests, and share its API keys, rate limit buckets and
+// idempotency records.
+type interceptors struct {
+	db      database.Store
+	clients *handler.Handler
+}
+
+// unary requires the admin API key for writes, limits the rate of calls and
+// makes writes sent with an idempotency key safe to retry.
+func (i interceptors) unary(c context.Context, req any, info *grpc.UnaryServerInfo, next grpc.UnaryHandler) (any, error) {
+	group := handler.LimitLookup
+	if writes[info.FullMethod] {
+		if err := i.authorize(c); err != nil {
+			return nil, err
+		}
+		group = handler.LimitWrite
+	}
+	if err := i.rateLimit(c, group); err != nil {
+		return nil, err
+	}
+	if !writes[info.FullMethod] {
+		return next(c, req)
+	}
+	return i.idempotent(c, req, info.FullMethod, next)
+}
+
+func (i interceptors) stream(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, next grpc.StreamHandler) error {
+	if err := i.rateLimit(stream.Context(), handler.LimitLookup); err != nil {
+		return err
+	}
+	return next(srv, stream)
+}
+
+// authorize rejects calls that don't carry the admin API key in the
+// x-api-key metadata.
+func (i interceptors) authorize(c context.Context) error {
+	if !i.clients.AdminEnabled() {
+		return status.Error(codes.PermissionDenied, "writes are disabled")
+	}
+	if !i.clients.IsAdminKey(apiKey(c)) {
+		return status.Error(codes.Unauthenticated, "invalid API key")
+	}
+	return nil
+}
+
+// rateLimit rejects calls of clients that used up their limit of the route
+// group with RESOURCE_EXHAUSTED, telling them when to retry in the
+// retry-after header.
+func (i interceptors) rateLimit(c context.Context, group string) error {
+	limit, result, err := i.clients.Take(c, group, apiKey(c), peerIP(c))
+	if err != nil {
+		// A failing store shouldn't take the API down with it.
+		log.Printf("Rate limit store failed: %v\n", err)
+		return nil
+	}
+	if limit.IsZero() || result.Allowed {
+		return nil
+	}
+	retryAfter := strconv.Itoa(int(math.Ceil(result.RetryAfter.Seconds())))
+	grpc.SetHeader(c, metadata.Pairs(metadataRetryAfter, retryAfter))
+	return status.Error(codes.ResourceExhausted, "too many requests")
+}
+
+// idempotent stores the response to a call sent with an idempotency key and
+// replays it when the client retries the call with the same key. Reusing a
+// key for a different call fails with INVALID_ARGUMENT, and retrying while
+// the first call is still being handled with ABORTED. Failed calls aren't
+// stored, so that they can be retried.
+func (i interceptors) idempotent(c context.Context, req any, method string, next grpc.UnaryHandler) (any, error) {
+	key := firstMetadata(c, metadataIdempotencyKey)
+	if key == "" {
+		return next(c, req)
+	}
+	if len(key) > handler.MaxIdempotencyKeySize {
+		return nil, status.Error(codes.InvalidArgument, "idempotency-key is too long")
+	}
+	body, err := proto.MarshalOptions{Deterministic: true}.Marshal(req.(proto.Message))
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	record := models.IdempotencyRecord{
+		Key:         i.clients.ClientID(apiKey(c), peerIP(c)) + ":" + key,
+		RequestHash: requestHash(method, body),
+		CreatedAt:   now,
+	}
+	err = i.db.InsertIdempotencyRecord(c, record, now.Add(-handler.IdempotencyTTL))
+	if errors.Is(err, database.ErrDuplicate) {
+		return i.replay(c, record)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// The call is over, but its record must still be settled, even when
+	// the handler panics.
+	settled := false
+	defer func() {
+		if !settled {
+			if err := i.db.DeleteIdempotencyRecord(context.WithoutCancel(c), record.Key); err != nil {
+				log.Printf("Failed to delete idempotency record: %v\n", err)
+			}
+		}
+	}()
+	resp, err := next(c, req)
+	if err != nil {
+		return nil, err
+	}
+	wrapped, err := anypb.New(resp.(proto.Message))
+	if err == nil {
+		record.Body, err = proto.Marshal(wrapped)
+	}
+	if err != nil {
+		return nil, err
+	}
+	record.Status = http.StatusOK
+	record.ContentType = contentTypeProto
+	if err := i.db.CompleteIdempotencyRecord(context.WithoutCancel(c), record); err != nil {
+		log.Printf("Failed to store idempotent response: %v\n", err)
+	}
+	settled = true
+	return resp, nil
+}
+
+// replay answers a retried call with the stored response of the first one.
+func (i interceptors) replay(c context.Context, record models.IdempotencyRecord) (any, error) {
+	stored, err := i.db.GetIdempotencyRecord(c, record.Key)
+	if err != nil && !errors.Is(err, database.ErrNotFound) {
+		return nil, err
+	}
+	if err == nil && stored.RequestHash != record.RequestHash {
+		return nil, status.Error(codes.InvalidArgument, "idempotency-key was already used for a different call")
+	}
+	// A record that's gone was of a call that failed just now.
+	if err != nil || stored.Status == 0 {
+		return nil, status.Error(codes.Aborted, "a call with this idempotency-key is still being processed")
+	}
+	var wrapped anypb.Any
+	if err := proto.Unmarshal(stored.Body, &wrapped); err != nil {
+		return nil, err
+	}
+	return wrapped.UnmarshalNew()
+}
+
+func requestHash(method string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func apiKey(c context.Context) string {
+	return firstMetadata(c, metadataAPIKey)
+}
+
+func firstMetadata(c context.Context, key string) string {
+	values := metadata.ValueFromIncomingContext(c, key)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// peerIP returns the IP address of the client, or its whole address when it
+// has no port.
+func peerIP(c context.Context) string {
+	p, ok := peer.FromContext(c)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
@@ -0,0 +1,201 @@
+// Package rpc serves the SWIFT code directory over gRPC, alongside the REST
+// API of the handler package and over the same database.
+package rpc
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative swiftv1/swift.proto
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/rtsncs/remitly-swift-api/database"
+	"github.com/rtsncs/remitly-swift-api/handler"
+	"github.com/rtsncs/remitly-swift-api/models"
+	"github.com/rtsncs/remitly-swift-api/rpc/swiftv1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+// maxBatchSize bounds the codes looked up by a single BatchLookup call.
+const maxBatchSize = 1000
+
+type Server struct {
+	swiftv1.UnimplementedSwiftCodesServer
+	db database.Store
+}
+
+func New(db database.Store) *Server {
+	return &Server{db: db}
+}
+
+// NewGRPCServer returns a gRPC server with the SwiftCodes service, health
+// checking and reflection registered. The health server reports the service
+// as serving until it is shut down. Calls are rate limited and writes
+// authorized by clients, the handler of the REST API, so that clients have
+// the same keys and limits on both APIs.
+func NewGRPCServer(db database.Store, clients *handler.Handler, opts ...grpc.ServerOption) (*grpc.Server, *health.Server) {
+	i := interceptors{db: db, clients: clients}
+	opts = append(opts,
+		grpc.ChainUnaryInterceptor(unaryRecover, unaryErrors, i.unary),
+		grpc.ChainStreamInterceptor(streamRecover, streamErrors, i.stream),
+	)
+	s := grpc.NewServer(opts...)
+	swiftv1.RegisterSwiftCodesServer(s, New(db))
+
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus(swiftv1.SwiftCodes_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(s, healthServer)
+	reflection.Register(s)
+	return s, healthServer
+}
+
+func (s *Server) GetCode(c context.Context, req *swiftv1.GetCodeRequest) (*swiftv1.GetCodeResponse, error) {
+	asOf, err := parseAsOf(req.GetAsOf())
+	if err != nil {
+		return nil, err
+	}
+	code, err := s.db.GetByCodeAsOf(c, req.GetSwiftCode(), asOf)
+	if err != nil {
+		return nil, err
+	}
+	response := &swiftv1.GetCodeResponse{Code: toProto(code)}
+	if code.IsHeadquarter {
+		branches, err := s.db.GetBranchesAsOf(c, code.SwiftCode, asOf)
+		if err != nil && !errors.Is(err, database.ErrNotFound) {
+			return nil, err
+		}
+		for _, branch := range branches {
+			branch.CountryName = code.CountryName
+			response.Branches = append(response.Branches, toProto(branch))
+		}
+	}
+	return response, nil
+}
+
+func (s *Server) BatchLookup(c context.Context, req *swiftv1.BatchLookupRequest) (*swiftv1.BatchLookupResponse, error) {
+	if len(req.GetSwiftCodes()) > maxBatchSize {
+		return nil, status.Errorf(codes.InvalidArgument, "at most %d codes can be looked up at once", maxBatchSize)
+	}
+	found, err := s.db.GetByCodes(c, req.GetSwiftCodes())
+	if err != nil {
+		return nil, err
+	}
+	byCode := make(map[string]models.SwiftCode, len(found))
+	for _, code := range found {
+		byCode[code.SwiftCode] = code
+	}
+	response := &swiftv1.BatchLookupResponse{}
+	for _, swiftCode := range req.GetSwiftCodes() {
+		code, ok := byCode[swiftCode]
+		if !ok {
+			response.NotFound = append(response.NotFound, swiftCode)
+			continue
+		}
+		response.Codes = append(response.Codes, toProto(code))
+	}
+	return response, nil
+}
+
+func (s *Server) ListByCountry(req *swiftv1.ListByCountryRequest, stream grpc.ServerStreamingServer[swiftv1.SwiftCode]) error {
+	asOf, err := parseAsOf(req.GetAsOf())
+	if err != nil {
+		return err
+	}
+	c := stream.Context()
+	name, err := s.db.GetCountryName(c, req.GetCountryIso2())
+	if err != nil {
+		return err
+	}
+	filter := database.Filter{
+		IsTest:           req.IsTest,
+		IsPassive:        req.IsPassive,
+		IsReverseBilling: req.IsReverseBilling,
+		AsOf:             asOf,
+	}
+	codes, err := s.db.GetByCountryCode(c, req.GetCountryIso2(), filter)
+	if err != nil && !errors.Is(err, database.ErrNotFound) {
+		return err
+	}
+	for _, code := range codes {
+		code.CountryName = name
+		if err := stream.Send(toProto(code)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Server) AddCode(c context.Context, req *swiftv1.AddCodeRequest) (*swiftv1.AddCodeResponse, error) {
+	if req.GetCode() == nil {
+		return nil, status.Error(codes.InvalidArgument, "code is required")
+	}
+	code := fromProto(req.GetCode())
+	if code.ValidFrom == "" {
+		code.ValidFrom = models.Today()
+	}
+	if err := code.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.db.InsertCode(c, code); err != nil {
+		return nil, err
+	}
+	return &swiftv1.AddCodeResponse{}, nil
+}
+
+func (s *Server) DeleteCode(c context.Context, req *swiftv1.DeleteCodeRequest) (*swiftv1.DeleteCodeResponse, error) {
+	if req.GetVersion() == 0 {
+		return nil, status.Error(codes.FailedPrecondition, "version is required")
+	}
+	today, _ := time.Parse(models.DateLayout, models.Today())
+	err := s.db.CloseByCodeAtVersion(c, req.GetSwiftCode(), int(req.GetVersion()), today)
+	if err != nil {
+		return nil, err
+	}
+	return &swiftv1.DeleteCodeResponse{}, nil
+}
+
+func parseAsOf(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	asOf, err := time.Parse(models.DateLayout, value)
+	if err != nil {
+		return time.Time{}, status.Error(codes.InvalidArgument, "as_of must be a YYYY-MM-DD date")
+	}
+	return asOf, nil
+}
+
+func toProto(code models.SwiftCode) *swiftv1.SwiftCode {
+	return &swiftv1.SwiftCode{
+		SwiftCode:        code.SwiftCode,
+		BankName:         code.BankName,
+		Address:          code.Address,
+		CountryIso2:      code.CountryISO2,
+		CountryName:      code.CountryName,
+		IsHeadquarter:    code.IsHeadquarter,
+		IsTest:           code.IsTest,
+		IsPassive:        code.IsPassive,
+		IsReverseBilling: code.IsReverseBilling,
+		ValidFrom:        code.ValidFrom,
+		ValidTo:          code.ValidTo,
+		Version:          int64(code.Version),
+	}
+}
+
+func fromProto(code *swiftv1.SwiftCode) models.SwiftCode {
+	return models.SwiftCode{
+		SwiftCode:     code.GetSwiftCode(),
+		BankName:      code.GetBankName(),
+		Address:       code.GetAddress(),
+		CountryISO2:   code.GetCountryIso2(),
+		CountryName:   code.GetCountryName(),
+		IsHeadquarter: code.GetIsHeadquarter(),
+		ValidFrom:     code.GetValidFrom(),
+		ValidTo:       code.GetValidTo(),
+	}
+}
@@ -0,0 +1,279 @@
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rtsncs/remitly-swift-api/database"
+	"github.com/rtsncs/remitly-swift-api/handler"
+	"github.com/rtsncs/remitly-swift-api/models"
+	"github.com/rtsncs/remitly-swift-api/ratelimit"
+	"github.com/rtsncs/remitly-swift-api/rpc/swiftv1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+)
+
+const adminKey = "secret"
+
+func dial(t *testing.T, db database.Store, config handler.Config) *grpc.ClientConn {
+	lis := bufconn.Listen(1 << 20)
+	clients := handler.New(db, config)
+	s, _ := NewGRPCServer(db, &clients)
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(c context.Context, _ string) (net.Conn, error) { return lis.DialContext(c) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func assertCode(t *testing.T, code codes.Code, err error) {
+	t.Helper()
+	assert.Equal(t, code, status.Code(err), "%v", err)
+}
+
+func TestSwiftCodes(t *testing.T) {
+	c := metadata.AppendToOutgoingContext(context.Background(), metadataAPIKey, adminKey)
+	client := swiftv1.NewSwiftCodesClient(dial(t, database.NewMemory(), handler.Config{AdminAPIKey: adminKey}))
+
+	add := func(code *swiftv1.SwiftCode) error {
+		_, err := client.AddCode(c, &swiftv1.AddCodeRequest{Code: code})
+		return err
+	}
+	hq := &swiftv1.SwiftCode{SwiftCode: "RPCSDEFFXXX", BankName: "RPC Bank", CountryIso2: "DE", CountryName: "Germany", IsHeadquarter: true}
+	require.NoError(t, add(hq))
+	require.NoError(t, add(&swiftv1.SwiftCode{SwiftCode: "RPCSDEFF001", BankName: "RPC Bank", Address: "Berlin", CountryIso2: "DE", CountryName: "Germany"}))
+	require.NoError(t, add(&swiftv1.SwiftCode{SwiftCode: "RPCSDEF0XXX", BankName: "RPC Test Bank", CountryIso2: "DE", CountryName: "Germany", IsHeadquarter: true}))
+	assertCode(t, codes.AlreadyExists, add(hq))
+	assertCode(t, codes.InvalidArgument, add(&swiftv1.SwiftCode{SwiftCode: "INVALID", BankName: "RPC Bank", CountryIso2: "DE", CountryName: "Germany"}))
+	assertCode(t, codes.InvalidArgument, add(nil))
+
+	got, err := client.GetCode(c, &swiftv1.GetCodeRequest{SwiftCode: "RPCSDEFFXXX"})
+	require.NoError(t, err)
+	assert.Equal(t, "RPC Bank", got.GetCode().GetBankName())
+	assert.Equal(t, "GERMANY", got.GetCode().GetCountryName())
+	assert.Equal(t, int64(1), got.GetCode().GetVersion())
+	require.Len(t, got.GetBranches(), 1)
+	assert.Equal(t, "RPCSDEFF001", got.GetBranches()[0].GetSwiftCode())
+	assert.Equal(t, "GERMANY", got.GetBranches()[0].GetCountryName())
+	_, err = client.GetCode(c, &swiftv1.GetCodeRequest{SwiftCode: "NONEDEFFXXX"})
+	assertCode(t, codes.NotFound, err)
+	_, err = client.GetCode(c, &swiftv1.GetCodeRequest{SwiftCode: "RPCSDEFFXXX", AsOf: "yesterday"})
+	assertCode(t, codes.InvalidArgument, err)
+
+	batch, err := client.BatchLookup(c, &swiftv1.BatchLookupRequest{SwiftCodes: []string{"RPCSDEFF001", "NONEDEFFXXX", "RPCSDEFFXXX"}})
+	require.NoError(t, err)
+	require.Len(t, batch.GetCodes(), 2)
+	assert.Equal(t, "RPCSDEFF001", batch.GetCodes()[0].GetSwiftCode())
+	assert.Equal(t, "RPCSDEFFXXX", batch.GetCodes()[1].GetSwiftCode())
+	assert.Equal(t, []string{"NONEDEFFXXX"}, batch.GetNotFound())
+	_, err = client.BatchLookup(c, &swiftv1.BatchLookupRequest{SwiftCodes: make([]string, maxBatchSize+1)})
+	assertCode(t, codes.InvalidArgument, err)
+
+	list := func(req *swiftv1.ListByCountryRequest) ([]string, error) {
+		stream, err := client.ListByCountry(c, req)
+		require.NoError(t, err)
+		var listed []string
+		for {
+			code, err := stream.Recv()
+			if err == io.EOF {
+				return listed, nil
+			}
+			if err != nil {
+				return listed, err
+			}
+			assert.Equal(t, "GERMANY", code.GetCountryName())
+			listed = append(listed, code.GetSwiftCode())
+		}
+	}
+	listed, err := list(&swiftv1.ListByCountryRequest{CountryIso2: "DE"})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"RPCSDEFFXXX", "RPCSDEFF001", "RPCSDEF0XXX"}, listed)
+	listed, err = list(&swiftv1.ListByCountryRequest{CountryIso2: "DE", IsTest: proto.Bool(true)})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"RPCSDEF0XXX"}, listed)
+	_, err = list(&swiftv1.ListByCountryRequest{CountryIso2: "XX"})
+	assertCode(t, codes.NotFound, err)
+
+	_, err = client.DeleteCode(c, &swiftv1.DeleteCodeRequest{SwiftCode: "RPCSDEFFXXX", Version: 2})
+	assertCode(t, codes.Aborted, err)
+	_, err = client.DeleteCode(c, &swiftv1.DeleteCodeRequest{SwiftCode: "RPCSDEFFXXX", Version: 1})
+	require.NoError(t, err)
+	_, err = client.DeleteCode(c, &swiftv1.DeleteCodeRequest{SwiftCode: "RPCSDEFFXXX", Version: 1})
+	assertCode(t, codes.NotFound, err)
+	_, err = client.DeleteCode(c, &swiftv1.DeleteCodeRequest{SwiftCode: "RPCSDEF0XXX"})
+	assertCode(t, codes.FailedPrecondition, err)
+}
+
+func TestAuthorization(t *testing.T) {
+	c := context.Background()
+	add := func(client swiftv1.SwiftCodesClient, key string) error {
+		code := &swiftv1.SwiftCode{SwiftCode: "AUTHDEFFXXX", BankName: "Auth Bank", CountryIso2: "DE", CountryName: "Germany", IsHeadquarter: true}
+		_, err := client.AddCode(metadata.AppendToOutgoingContext(c, metadataAPIKey, key), &swiftv1.AddCodeRequest{Code: code})
+		return err
+	}
+
+	disabled := swiftv1.NewSwiftCodesClient(dial(t, database.NewMemory(), handler.Config{}))
+	assertCode(t, codes.PermissionDenied, add(disabled, adminKey))
+
+	client := swiftv1.NewSwiftCodesClient(dial(t, database.NewMemory(), handler.Config{AdminAPIKey: adminKey}))
+	assertCode(t, codes.Unauthenticated, add(client, ""))
+	assertCode(t, codes.Unauthenticated, add(client, "guess"))
+	assert.NoError(t, add(client, adminKey))
+	_, err := client.GetCode(c, &swiftv1.GetCodeRequest{SwiftCode: "AUTHDEFFXXX"})
+	assert.NoError(t, err)
+}
+
+func TestRateLimit(t *testing.T) {
+	c := context.Background()
+	client := swiftv1.NewSwiftCodesClient(dial(t, database.NewMemory(), handler.Config{
+		RateLimits: handler.RateLimits{
+			Store:  ratelimit.NewMemory(),
+			Lookup: ratelimit.Limit{Requests: 1, Per: time.Minute},
+		},
+	}))
+
+	_, err := client.GetCode(c, &swiftv1.GetCodeRequest{SwiftCode: "NONEDEFFXXX"})
+	assertCode(t, codes.NotFound, err)
+	var header metadata.MD
+	_, err = client.GetCode(c, &swiftv1.GetCodeRequest{SwiftCode: "NONEDEFFXXX"}, grpc.Header(&header))
+	assertCode(t, codes.ResourceExhausted, err)
+	assert.Equal(t, []string{"60"}, header.Get(metadataRetryAfter))
+
+	// Streams take from the same bucket.
+	stream, err := client.ListByCountry(c, &swiftv1.ListByCountryRequest{CountryIso2: "DE"})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assertCode(t, codes.ResourceExhausted, err)
+}
+
+func TestIdempotency(t *testing.T) {
+	c := metadata.AppendToOutgoingContext(context.Background(), metadataAPIKey, adminKey)
+	db := database.NewMemory()
+	client := swiftv1.NewSwiftCodesClient(dial(t, db, handler.Config{AdminAPIKey: adminKey}))
+	add := func(c context.Context, swiftCode string) error {
+		code := &swiftv1.SwiftCode{SwiftCode: swiftCode, BankName: "Retry Bank", CountryIso2: "DE", CountryName: "Germany", IsHeadquarter: true}
+		_, err := client.AddCode(c, &swiftv1.AddCodeRequest{Code: code})
+		return err
+	}
+
+	retried := metadata.AppendToOutgoingContext(c, metadataIdempotencyKey, "add-1")
+	require.NoError(t, add(retried, "RTRYDEFFXXX"))
+	assert.NoError(t, add(retried, "RTRYDEFFXXX"))
+	assertCode(t, codes.AlreadyExists, add(c, "RTRYDEFFXXX"))
+	assertCode(t, codes.InvalidArgument, add(retried, "RTRYDEF0XXX"))
+	_, err := db.GetByCode(c, "RTRYDEF0XXX")
+	assert.ErrorIs(t, err, database.ErrNotFound)
+
+	// Failed calls can be retried with the same key.
+	failed := metadata.AppendToOutgoingContext(c, metadataIdempotencyKey, "add-2")
+	assertCode(t, codes.AlreadyExists, add(failed, "RTRYDEFFXXX"))
+	assertCode(t, codes.AlreadyExists, add(failed, "RTRYDEFFXXX"))
+
+	tooLong := metadata.AppendToOutgoingContext(c, metadataIdempotencyKey, strings.Repeat("k", handler.MaxIdempotencyKeySize+1))
+	assertCode(t, codes.InvalidArgument, add(tooLong, "RTRYDEF0XXX"))
+}
+
+// panickingStore panics on writes and on listings.
+type panickingStore struct {
+	*database.Memory
+}
+
+func (panickingStore) InsertCode(context.Context, models.SwiftCode) error {
+	panic("insert failed")
+}
+
+func (panickingStore) GetByCountryCode(context.Context, string, database.Filter) ([]models.SwiftCode, error) {
+	panic("listing failed")
+}
+
+func TestRecover(t *testing.T) {
+	var logBuf bytes.Buffer
+	originalOutput := log.Writer()
+	log.SetOutput(&logBuf)
+	t.Cleanup(func() { log.SetOutput(originalOutput) })
+
+	c := metadata.AppendToOutgoingContext(context.Background(), metadataAPIKey, adminKey)
+	db := panickingStore{database.NewMemory()}
+	require.NoError(t, db.Memory.InsertCode(c, models.SwiftCode{SwiftCode: "PANCDEFFXXX", BankName: "Panic Bank", CountryISO2: "DE", CountryName: "GERMANY", IsHeadquarter: true}))
+	client := swiftv1.NewSwiftCodesClient(dial(t, db, handler.Config{AdminAPIKey: adminKey}))
+
+	code := &swiftv1.SwiftCode{SwiftCode: "PANCDEF0XXX", BankName: "Panic Bank", CountryIso2: "DE", CountryName: "Germany", IsHeadquarter: true}
+	retried := metadata.AppendToOutgoingContext(c, metadataIdempotencyKey, "panic")
+	_, err := client.AddCode(retried, &swiftv1.AddCodeRequest{Code: code})
+	assertCode(t, codes.Internal, err)
+	assert.Contains(t, logBuf.String(), "AddCode panicked: insert failed")
+
+	// The record of the panicking call is gone, so it can be retried.
+	_, err = client.AddCode(retried, &swiftv1.AddCodeRequest{Code: code})
+	assertCode(t, codes.Internal, err)
+
+	stream, err := client.ListByCountry(c, &swiftv1.ListByCountryRequest{CountryIso2: "DE"})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assertCode(t, codes.Internal, err)
+
+	// The server is still up.
+	_, err = client.GetCode(c, &swiftv1.GetCodeRequest{SwiftCode: "PANCDEFFXXX"})
+	assert.NoError(t, err)
+}
+
+func TestStatusError(t *testing.T) {
+	tests := []struct {
+		err  error
+		code codes.Code
+	}{
+		{database.ErrTimeout, codes.DeadlineExceeded},
+		{database.ErrUnavailable, codes.Unavailable},
+		{context.Canceled, codes.Canceled},
+		{io.ErrUnexpectedEOF, codes.Internal},
+		{status.Error(codes.PermissionDenied, "denied"), codes.PermissionDenied},
+	}
+	for _, tc := range tests {
+		assertCode(t, tc.code, statusError("/test", tc.err))
+	}
+	assert.NoError(t, statusError("/test", nil))
+}
+
+func TestHealthAndReflection(t *testing.T) {
+	c := context.Background()
+	conn := dial(t, database.NewMemory(), handler.Config{})
+
+	health, err := healthpb.NewHealthClient(conn).Check(c, &healthpb.HealthCheckRequest{Service: "swift.v1.SwiftCodes"})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, health.GetStatus())
+
+	stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(c)
+	require.NoError(t, err)
+	err = stream.Send(&reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+	})
+	require.NoError(t, err)
+	resp, err := stream.Recv()
+	require.NoError(t, err)
+	var services []string
+	for _, service := range resp.GetListServicesResponse().GetService() {
+		services = append(services, service.GetName())
+	}
+	assert.Contains(t, services, "swift.v1.SwiftCodes")
+	assert.Contains(t, services, "grpc.health.v1.Health")
+}
@@ -0,0 +1,788 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.4
+// 	protoc        (unknown)
+// source: swiftv1/swift.proto
+
+package swiftv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type SwiftCode struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SwiftCode     string                 `protobuf:"bytes,1,opt,name=swift_code,json=swiftCode,proto3" json:"swift_code,omitempty"`
+	BankName      string                 `protobuf:"bytes,2,opt,name=bank_name,json=bankName,proto3" json:"bank_name,omitempty"`
+	Address       string                 `protobuf:"bytes,3,opt,name=address,proto3" json:"address,omitempty"`
+	CountryIso2   string                 `protobuf:"bytes,4,opt,name=country_iso2,json=countryIso2,proto3" json:"country_iso2,omitempty"`
+	CountryName   string                 `protobuf:"bytes,5,opt,name=country_name,json=countryName,proto3" json:"country_name,omitempty"`
+	IsHeadquarter bool                   `protobuf:"varint,6,opt,name=is_headquarter,json=isHeadquarter,proto3" json:"is_headquarter,omitempty"`
+	// is_test, is_passive and is_reverse_billing are derived from the location
+	// code and ignored by AddCode.
+	IsTest           bool `protobuf:"varint,7,opt,name=is_test,json=isTest,proto3" json:"is_test,omitempty"`
+	IsPassive        bool `protobuf:"varint,8,opt,name=is_passive,json=isPassive,proto3" json:"is_passive,omitempty"`
+	IsReverseBilling bool `protobuf:"varint,9,opt,name=is_reverse_billing,json=isReverseBilling,proto3" json:"is_reverse_billing,omitempty"`
+	// valid_from and valid_to are YYYY-MM-DD dates. valid_to is exclusive and
+	// empty while the code is current.
+	ValidFrom string `protobuf:"bytes,10,opt,name=valid_from,json=validFrom,proto3" json:"valid_from,omitempty"`
+	ValidTo   string `protobuf:"bytes,11,opt,name=valid_to,json=validTo,proto3" json:"valid_to,omitempty"`
+	// version counts the changes to the code. It is set by lookups and must be
+	// passed to DeleteCode.
+	Version       int64 `protobuf:"varint,12,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SwiftCode) Reset() {
+	*x = SwiftCode{}
+	mi := &file_swiftv1_swift_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SwiftCode) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SwiftCode) ProtoMessage() {}
+
+func (x *SwiftCode) ProtoReflect() protoreflect.Message {
+	mi := &file_swiftv1_swift_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SwiftCode.ProtoReflect.Descriptor instead.
+func (*SwiftCode) Descriptor() ([]byte, []int) {
+	return file_swiftv1_swift_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *SwiftCode) GetSwiftCode() string {
+	if x != nil {
+		return x.SwiftCode
+	}
+	return ""
+}
+
+func (x *SwiftCode) GetBankName() string {
+	if x != nil {
+		return x.BankName
+	}
+	return ""
+}
+
+func (x *SwiftCode) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *SwiftCode) GetCountryIso2() string {
+	if x != nil {
+		return x.CountryIso2
+	}
+	return ""
+}
+
+func (x *SwiftCode) GetCountryName() string {
+	if x != nil {
+		return x.CountryName
+	}
+	return ""
+}
+
+func (x *SwiftCode) GetIsHeadquarter() bool {
+	if x != nil {
+		return x.IsHeadquarter
+	}
+	return false
+}
+
+func (x *SwiftCode) GetIsTest() bool {
+	if x != nil {
+		return x.IsTest
+	}
+	return false
+}
+
+func (x *SwiftCode) GetIsPassive() bool {
+	if x != nil {
+		return x.IsPassive
+	}
+	return false
+}
+
+func (x *SwiftCode) GetIsReverseBilling() bool {
+	if x != nil {
+		return x.IsReverseBilling
+	}
+	return false
+}
+
+func (x *SwiftCode) GetValidFrom() string {
+	if x != nil {
+		return x.ValidFrom
+	}
+	return ""
+}
+
+func (x *SwiftCode) GetValidTo() string {
+	if x != nil {
+		return x.ValidTo
+	}
+	return ""
+}
+
+func (x *SwiftCode) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+type GetCodeRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	SwiftCode string                 `protobuf:"bytes,1,opt,name=swift_code,json=swiftCode,proto3" json:"swift_code,omitempty"`
+	// as_of looks up the record valid on a past YYYY-MM-DD date instead of the
+	// current one.
+	AsOf          string `protobuf:"bytes,2,opt,name=as_of,json=asOf,proto3" json:"as_of,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCodeRequest) Reset() {
+	*x = GetCodeRequest{}
+	mi := &file_swiftv1_swift_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCodeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCodeRequest) ProtoMessage() {}
+
+func (x *GetCodeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_swiftv1_swift_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCodeRequest.ProtoReflect.Descriptor instead.
+func (*GetCodeRequest) Descriptor() ([]byte, []int) {
+	return file_swiftv1_swift_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GetCodeRequest) GetSwiftCode() string {
+	if x != nil {
+		return x.SwiftCode
+	}
+	return ""
+}
+
+func (x *GetCodeRequest) GetAsOf() string {
+	if x != nil {
+		return x.AsOf
+	}
+	return ""
+}
+
+type GetCodeResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          *SwiftCode             `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	Branches      []*SwiftCode           `protobuf:"bytes,2,rep,name=branches,proto3" json:"branches,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCodeResponse) Reset() {
+	*x = GetCodeResponse{}
+	mi := &file_swiftv1_swift_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCodeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCodeResponse) ProtoMessage() {}
+
+func (x *GetCodeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_swiftv1_swift_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCodeResponse.ProtoReflect.Descriptor instead.
+func (*GetCodeResponse) Descriptor() ([]byte, []int) {
+	return file_swiftv1_swift_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetCodeResponse) GetCode() *SwiftCode {
+	if x != nil {
+		return x.Code
+	}
+	return nil
+}
+
+func (x *GetCodeResponse) GetBranches() []*SwiftCode {
+	if x != nil {
+		return x.Branches
+	}
+	return nil
+}
+
+type BatchLookupRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// At most 1000 codes.
+	SwiftCodes    []string `protobuf:"bytes,1,rep,name=swift_codes,json=swiftCodes,proto3" json:"swift_codes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchLookupRequest) Reset() {
+	*x = BatchLookupRequest{}
+	mi := &file_swiftv1_swift_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchLookupRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchLookupRequest) ProtoMessage() {}
+
+func (x *BatchLookupRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_swiftv1_swift_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchLookupRequest.ProtoReflect.Descriptor instead.
+func (*BatchLookupRequest) Descriptor() ([]byte, []int) {
+	return file_swiftv1_swift_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *BatchLookupRequest) GetSwiftCodes() []string {
+	if x != nil {
+		return x.SwiftCodes
+	}
+	return nil
+}
+
+type BatchLookupResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Codes         []*SwiftCode           `protobuf:"bytes,1,rep,name=codes,proto3" json:"codes,omitempty"`
+	NotFound      []string               `protobuf:"bytes,2,rep,name=not_found,json=notFound,proto3" json:"not_found,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchLookupResponse) Reset() {
+	*x = BatchLookupResponse{}
+	mi := &file_swiftv1_swift_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchLookupResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchLookupResponse) ProtoMessage() {}
+
+func (x *BatchLookupResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_swiftv1_swift_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchLookupResponse.ProtoReflect.Descriptor instead.
+func (*BatchLookupResponse) Descriptor() ([]byte, []int) {
+	return file_swiftv1_swift_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *BatchLookupResponse) GetCodes() []*SwiftCode {
+	if x != nil {
+		return x.Codes
+	}
+	return nil
+}
+
+func (x *BatchLookupResponse) GetNotFound() []string {
+	if x != nil {
+		return x.NotFound
+	}
+	return nil
+}
+
+type ListByCountryRequest struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	CountryIso2      string                 `protobuf:"bytes,1,opt,name=country_iso2,json=countryIso2,proto3" json:"country_iso2,omitempty"`
+	IsTest           *bool                  `protobuf:"varint,2,opt,name=is_test,json=isTest,proto3,oneof" json:"is_test,omitempty"`
+	IsPassive        *bool                  `protobuf:"varint,3,opt,name=is_passive,json=isPassive,proto3,oneof" json:"is_passive,omitempty"`
+	IsReverseBilling *bool                  `protobuf:"varint,4,opt,name=is_reverse_billing,json=isReverseBilling,proto3,oneof" json:"is_reverse_billing,omitempty"`
+	AsOf             string                 `protobuf:"bytes,5,opt,name=as_of,json=asOf,proto3" json:"as_of,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *ListByCountryRequest) Reset() {
+	*x = ListByCountryRequest{}
+	mi := &file_swiftv1_swift_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListByCountryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListByCountryRequest) ProtoMessage() {}
+
+func (x *ListByCountryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_swiftv1_swift_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListByCountryRequest.ProtoReflect.Descriptor instead.
+func (*ListByCountryRequest) Descriptor() ([]byte, []int) {
+	return file_swiftv1_swift_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ListByCountryRequest) GetCountryIso2() string {
+	if x != nil {
+		return x.CountryIso2
+	}
+	return ""
+}
+
+func (x *ListByCountryRequest) GetIsTest() bool {
+	if x != nil && x.IsTest != nil {
+		return *x.IsTest
+	}
+	return false
+}
+
+func (x *ListByCountryRequest) GetIsPassive() bool {
+	if x != nil && x.IsPassive != nil {
+		return *x.IsPassive
+	}
+	return false
+}
+
+func (x *ListByCountryRequest) GetIsReverseBilling() bool {
+	if x != nil && x.IsReverseBilling != nil {
+		return *x.IsReverseBilling
+	}
+	return false
+}
+
+func (x *ListByCountryRequest) GetAsOf() string {
+	if x != nil {
+		return x.AsOf
+	}
+	return ""
+}
+
+type AddCodeRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// valid_from defaults to today.
+	Code          *SwiftCode `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddCodeRequest) Reset() {
+	*x = AddCodeRequest{}
+	mi := &file_swiftv1_swift_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddCodeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddCodeRequest) ProtoMessage() {}
+
+func (x *AddCodeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_swiftv1_swift_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddCodeRequest.ProtoReflect.Descriptor instead.
+func (*AddCodeRequest) Descriptor() ([]byte, []int) {
+	return file_swiftv1_swift_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *AddCodeRequest) GetCode() *SwiftCode {
+	if x != nil {
+		return x.Code
+	}
+	return nil
+}
+
+type AddCodeResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddCodeResponse) Reset() {
+	*x = AddCodeResponse{}
+	mi := &file_swiftv1_swift_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddCodeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddCodeResponse) ProtoMessage() {}
+
+func (x *AddCodeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_swiftv1_swift_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddCodeResponse.ProtoReflect.Descriptor instead.
+func (*AddCodeResponse) Descriptor() ([]byte, []int) {
+	return file_swiftv1_swift_proto_rawDescGZIP(), []int{7}
+}
+
+type DeleteCodeRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	SwiftCode string                 `protobuf:"bytes,1,opt,name=swift_code,json=swiftCode,proto3" json:"swift_code,omitempty"`
+	// version is required, and makes the call fail with ABORTED if the code
+	// changed since it was looked up.
+	Version       int64 `protobuf:"varint,2,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteCodeRequest) Reset() {
+	*x = DeleteCodeRequest{}
+	mi := &file_swiftv1_swift_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteCodeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteCodeRequest) ProtoMessage() {}
+
+func (x *DeleteCodeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_swiftv1_swift_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteCodeRequest.ProtoReflect.Descriptor instead.
+func (*DeleteCodeRequest) Descriptor() ([]byte, []int) {
+	return file_swiftv1_swift_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *DeleteCodeRequest) GetSwiftCode() string {
+	if x != nil {
+		return x.SwiftCode
+	}
+	return ""
+}
+
+func (x *DeleteCodeRequest) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+type DeleteCodeResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteCodeResponse) Reset() {
+	*x = DeleteCodeResponse{}
+	mi := &file_swiftv1_swift_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteCodeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteCodeResponse) ProtoMessage() {}
+
+func (x *DeleteCodeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_swiftv1_swift_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteCodeResponse.ProtoReflect.Descriptor instead.
+func (*DeleteCodeResponse) Descriptor() ([]byte, []int) {
+	return file_swiftv1_swift_proto_rawDescGZIP(), []int{9}
+}
+
+var File_swiftv1_swift_proto protoreflect.FileDescriptor
+
+var file_swiftv1_swift_proto_rawDesc = string([]byte{
+	0x0a, 0x13, 0x73, 0x77, 0x69, 0x66, 0x74, 0x76, 0x31, 0x2f, 0x73, 0x77, 0x69, 0x66, 0x74, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x08, 0x73, 0x77, 0x69, 0x66, 0x74, 0x2e, 0x76, 0x31, 0x22,
+	0x88, 0x03, 0x0a, 0x09, 0x53, 0x77, 0x69, 0x66, 0x74, 0x43, 0x6f, 0x64, 0x65, 0x12, 0x1d, 0x0a,
+	0x0a, 0x73, 0x77, 0x69, 0x66, 0x74, 0x5f, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x09, 0x73, 0x77, 0x69, 0x66, 0x74, 0x43, 0x6f, 0x64, 0x65, 0x12, 0x1b, 0x0a, 0x09,
+	0x62, 0x61, 0x6e, 0x6b, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x08, 0x62, 0x61, 0x6e, 0x6b, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x61, 0x64, 0x64,
+	0x72, 0x65, 0x73, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x61, 0x64, 0x64, 0x72,
+	0x65, 0x73, 0x73, 0x12, 0x21, 0x0a, 0x0c, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x72, 0x79, 0x5f, 0x69,
+	0x73, 0x6f, 0x32, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x63, 0x6f, 0x75, 0x6e, 0x74,
+	0x72, 0x79, 0x49, 0x73, 0x6f, 0x32, 0x12, 0x21, 0x0a, 0x0c, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x72,
+	0x79, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x63, 0x6f,
+	0x75, 0x6e, 0x74, 0x72, 0x79, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x25, 0x0a, 0x0e, 0x69, 0x73, 0x5f,
+	0x68, 0x65, 0x61, 0x64, 0x71, 0x75, 0x61, 0x72, 0x74, 0x65, 0x72, 0x18, 0x06, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x0d, 0x69, 0x73, 0x48, 0x65, 0x61, 0x64, 0x71, 0x75, 0x61, 0x72, 0x74, 0x65, 0x72,
+	0x12, 0x17, 0x0a, 0x07, 0x69, 0x73, 0x5f, 0x74, 0x65, 0x73, 0x74, 0x18, 0x07, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x06, 0x69, 0x73, 0x54, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x69, 0x73, 0x5f,
+	0x70, 0x61, 0x73, 0x73, 0x69, 0x76, 0x65, 0x18, 0x08, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x69,
+	0x73, 0x50, 0x61, 0x73, 0x73, 0x69, 0x76, 0x65, 0x12, 0x2c, 0x0a, 0x12, 0x69, 0x73, 0x5f, 0x72,
+	0x65, 0x76, 0x65, 0x72, 0x73, 0x65, 0x5f, 0x62, 0x69, 0x6c, 0x6c, 0x69, 0x6e, 0x67, 0x18, 0x09,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x10, 0x69, 0x73, 0x52, 0x65, 0x76, 0x65, 0x72, 0x73, 0x65, 0x42,
+	0x69, 0x6c, 0x6c, 0x69, 0x6e, 0x67, 0x12, 0x1d, 0x0a, 0x0a, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x5f,
+	0x66, 0x72, 0x6f, 0x6d, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x76, 0x61, 0x6c, 0x69,
+	0x64, 0x46, 0x72, 0x6f, 0x6d, 0x12, 0x19, 0x0a, 0x08, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x5f, 0x74,
+	0x6f, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x54, 0x6f,
+	0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x0c, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x22, 0x44, 0x0a, 0x0e, 0x47, 0x65,
+	0x74, 0x43, 0x6f, 0x64, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a,
+	0x73, 0x77, 0x69, 0x66, 0x74, 0x5f, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x09, 0x73, 0x77, 0x69, 0x66, 0x74, 0x43, 0x6f, 0x64, 0x65, 0x12, 0x13, 0x0a, 0x05, 0x61,
+	0x73, 0x5f, 0x6f, 0x66, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x61, 0x73, 0x4f, 0x66,
+	0x22, 0x6b, 0x0a, 0x0f, 0x47, 0x65, 0x74, 0x43, 0x6f, 0x64, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x27, 0x0a, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x13, 0x2e, 0x73, 0x77, 0x69, 0x66, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x77, 0x69,
+	0x66, 0x74, 0x43, 0x6f, 0x64, 0x65, 0x52, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x12, 0x2f, 0x0a, 0x08,
+	0x62, 0x72, 0x61, 0x6e, 0x63, 0x68, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x13,
+	0x2e, 0x73, 0x77, 0x69, 0x66, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x77, 0x69, 0x66, 0x74, 0x43,
+	0x6f, 0x64, 0x65, 0x52, 0x08, 0x62, 0x72, 0x61, 0x6e, 0x63, 0x68, 0x65, 0x73, 0x22, 0x35, 0x0a,
+	0x12, 0x42, 0x61, 0x74, 0x63, 0x68, 0x4c, 0x6f, 0x6f, 0x6b, 0x75, 0x70, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x1f, 0x0a, 0x0b, 0x73, 0x77, 0x69, 0x66, 0x74, 0x5f, 0x63, 0x6f, 0x64,
+	0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0a, 0x73, 0x77, 0x69, 0x66, 0x74, 0x43,
+	0x6f, 0x64, 0x65, 0x73, 0x22, 0x5d, 0x0a, 0x13, 0x42, 0x61, 0x74, 0x63, 0x68, 0x4c, 0x6f, 0x6f,
+	0x6b, 0x75, 0x70, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x29, 0x0a, 0x05, 0x63,
+	0x6f, 0x64, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x73, 0x77, 0x69,
+	0x66, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x77, 0x69, 0x66, 0x74, 0x43, 0x6f, 0x64, 0x65, 0x52,
+	0x05, 0x63, 0x6f, 0x64, 0x65, 0x73, 0x12, 0x1b, 0x0a, 0x09, 0x6e, 0x6f, 0x74, 0x5f, 0x66, 0x6f,
+	0x75, 0x6e, 0x64, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x08, 0x6e, 0x6f, 0x74, 0x46, 0x6f,
+	0x75, 0x6e, 0x64, 0x22, 0xf5, 0x01, 0x0a, 0x14, 0x4c, 0x69, 0x73, 0x74, 0x42, 0x79, 0x43, 0x6f,
+	0x75, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x21, 0x0a, 0x0c,
+	0x63, 0x6f, 0x75, 0x6e, 0x74, 0x72, 0x79, 0x5f, 0x69, 0x73, 0x6f, 0x32, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0b, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x72, 0x79, 0x49, 0x73, 0x6f, 0x32, 0x12,
+	0x1c, 0x0a, 0x07, 0x69, 0x73, 0x5f, 0x74, 0x65, 0x73, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08,
+	0x48, 0x00, 0x52, 0x06, 0x69, 0x73, 0x54, 0x65, 0x73, 0x74, 0x88, 0x01, 0x01, 0x12, 0x22, 0x0a,
+	0x0a, 0x69, 0x73, 0x5f, 0x70, 0x61, 0x73, 0x73, 0x69, 0x76, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x08, 0x48, 0x01, 0x52, 0x09, 0x69, 0x73, 0x50, 0x61, 0x73, 0x73, 0x69, 0x76, 0x65, 0x88, 0x01,
+	0x01, 0x12, 0x31, 0x0a, 0x12, 0x69, 0x73, 0x5f, 0x72, 0x65, 0x76, 0x65, 0x72, 0x73, 0x65, 0x5f,
+	0x62, 0x69, 0x6c, 0x6c, 0x69, 0x6e, 0x67, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x48, 0x02, 0x52,
+	0x10, 0x69, 0x73, 0x52, 0x65, 0x76, 0x65, 0x72, 0x73, 0x65, 0x42, 0x69, 0x6c, 0x6c, 0x69, 0x6e,
+	0x67, 0x88, 0x01, 0x01, 0x12, 0x13, 0x0a, 0x05, 0x61, 0x73, 0x5f, 0x6f, 0x66, 0x18, 0x05, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x04, 0x61, 0x73, 0x4f, 0x66, 0x42, 0x0a, 0x0a, 0x08, 0x5f, 0x69, 0x73,
+	0x5f, 0x74, 0x65, 0x73, 0x74, 0x42, 0x0d, 0x0a, 0x0b, 0x5f, 0x69, 0x73, 0x5f, 0x70, 0x61, 0x73,
+	0x73, 0x69, 0x76, 0x65, 0x42, 0x15, 0x0a, 0x13, 0x5f, 0x69, 0x73, 0x5f, 0x72, 0x65, 0x76, 0x65,
+	0x72, 0x73, 0x65, 0x5f, 0x62, 0x69, 0x6c, 0x6c, 0x69, 0x6e, 0x67, 0x22, 0x39, 0x0a, 0x0e, 0x41,
+	0x64, 0x64, 0x43, 0x6f, 0x64, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x27, 0x0a,
+	0x04, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x73, 0x77,
+	0x69, 0x66, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x77, 0x69, 0x66, 0x74, 0x43, 0x6f, 0x64, 0x65,
+	0x52, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x22, 0x11, 0x0a, 0x0f, 0x41, 0x64, 0x64, 0x43, 0x6f, 0x64,
+	0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x4c, 0x0a, 0x11, 0x44, 0x65, 0x6c,
+	0x65, 0x74, 0x65, 0x43, 0x6f, 0x64, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d,
+	0x0a, 0x0a, 0x73, 0x77, 0x69, 0x66, 0x74, 0x5f, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x09, 0x73, 0x77, 0x69, 0x66, 0x74, 0x43, 0x6f, 0x64, 0x65, 0x12, 0x18, 0x0a,
+	0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07,
+	0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x22, 0x14, 0x0a, 0x12, 0x44, 0x65, 0x6c, 0x65, 0x74,
+	0x65, 0x43, 0x6f, 0x64, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x32, 0xe9, 0x02,
+	0x0a, 0x0a, 0x53, 0x77, 0x69, 0x66, 0x74, 0x43, 0x6f, 0x64, 0x65, 0x73, 0x12, 0x3e, 0x0a, 0x07,
+	0x47, 0x65, 0x74, 0x43, 0x6f, 0x64, 0x65, 0x12, 0x18, 0x2e, 0x73, 0x77, 0x69, 0x66, 0x74, 0x2e,
+	0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x43, 0x6f, 0x64, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x19, 0x2e, 0x73, 0x77, 0x69, 0x66, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74,
+	0x43, 0x6f, 0x64, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x4a, 0x0a, 0x0b,
+	0x42, 0x61, 0x74, 0x63, 0x68, 0x4c, 0x6f, 0x6f, 0x6b, 0x75, 0x70, 0x12, 0x1c, 0x2e, 0x73, 0x77,
+	0x69, 0x66, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x61, 0x74, 0x63, 0x68, 0x4c, 0x6f, 0x6f, 0x6b,
+	0x75, 0x70, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1d, 0x2e, 0x73, 0x77, 0x69, 0x66,
+	0x74, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x61, 0x74, 0x63, 0x68, 0x4c, 0x6f, 0x6f, 0x6b, 0x75, 0x70,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x46, 0x0a, 0x0d, 0x4c, 0x69, 0x73, 0x74,
+	0x42, 0x79, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x1e, 0x2e, 0x73, 0x77, 0x69, 0x66,
+	0x74, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x42, 0x79, 0x43, 0x6f, 0x75, 0x6e, 0x74,
+	0x72, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x13, 0x2e, 0x73, 0x77, 0x69, 0x66,
+	0x74, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x77, 0x69, 0x66, 0x74, 0x43, 0x6f, 0x64, 0x65, 0x30, 0x01,
+	0x12, 0x3e, 0x0a, 0x07, 0x41, 0x64, 0x64, 0x43, 0x6f, 0x64, 0x65, 0x12, 0x18, 0x2e, 0x73, 0x77,
+	0x69, 0x66, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x64, 0x64, 0x43, 0x6f, 0x64, 0x65, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x19, 0x2e, 0x73, 0x77, 0x69, 0x66, 0x74, 0x2e, 0x76, 0x31,
+	0x2e, 0x41, 0x64, 0x64, 0x43, 0x6f, 0x64, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x47, 0x0a, 0x0a, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x43, 0x6f, 0x64, 0x65, 0x12, 0x1b,
+	0x2e, 0x73, 0x77, 0x69, 0x66, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65,
+	0x43, 0x6f, 0x64, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1c, 0x2e, 0x73, 0x77,
+	0x69, 0x66, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x43, 0x6f, 0x64,
+	0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42, 0x39, 0x5a, 0x37, 0x67, 0x69, 0x74,
+	0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x72, 0x74, 0x73, 0x6e, 0x63, 0x73, 0x2f, 0x72,
+	0x65, 0x6d, 0x69, 0x74, 0x6c, 0x79, 0x2d, 0x73, 0x77, 0x69, 0x66, 0x74, 0x2d, 0x61, 0x70, 0x69,
+	0x2f, 0x72, 0x70, 0x63, 0x2f, 0x73, 0x77, 0x69, 0x66, 0x74, 0x76, 0x31, 0x3b, 0x73, 0x77, 0x69,
+	0x66, 0x74, 0x76, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+})
+
+var (
+	file_swiftv1_swift_proto_rawDescOnce sync.Once
+	file_swiftv1_swift_proto_rawDescData []byte
+)
+
+func file_swiftv1_swift_proto_rawDescGZIP() []byte {
+	file_swiftv1_swift_proto_rawDescOnce.Do(func() {
+		file_swiftv1_swift_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_swiftv1_swift_proto_rawDesc), len(file_swiftv1_swift_proto_rawDesc)))
+	})
+	return file_swiftv1_swift_proto_rawDescData
+}
+
+var file_swiftv1_swift_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
+var file_swiftv1_swift_proto_goTypes = []any{
+	(*SwiftCode)(nil),            // 0: swift.v1.SwiftCode
+	(*GetCodeRequest)(nil),       // 1: swift.v1.GetCodeRequest
+	(*GetCodeResponse)(nil),      // 2: swift.v1.GetCodeResponse
+	(*BatchLookupRequest)(nil),   // 3: swift.v1.BatchLookupRequest
+	(*BatchLookupResponse)(nil),  // 4: swift.v1.BatchLookupResponse
+	(*ListByCountryRequest)(nil), // 5: swift.v1.ListByCountryRequest
+	(*AddCodeRequest)(nil),       // 6: swift.v1.AddCodeRequest
+	(*AddCodeResponse)(nil),      // 7: swift.v1.AddCodeResponse
+	(*DeleteCodeRequest)(nil),    // 8: swift.v1.DeleteCodeRequest
+	(*DeleteCodeResponse)(nil),   // 9: swift.v1.DeleteCodeResponse
+}
+var file_swiftv1_swift_proto_depIdxs = []int32{
+	0, // 0: swift.v1.GetCodeResponse.code:type_name -> swift.v1.SwiftCode
+	0, // 1: swift.v1.GetCodeResponse.branches:type_name -> swift.v1.SwiftCode
+	0, // 2: swift.v1.BatchLookupResponse.codes:type_name -> swift.v1.SwiftCode
+	0, // 3: swift.v1.AddCodeRequest.code:type_name -> swift.v1.SwiftCode
+	1, // 4: swift.v1.SwiftCodes.GetCode:input_type -> swift.v1.GetCodeRequest
+	3, // 5: swift.v1.SwiftCodes.BatchLookup:input_type -> swift.v1.BatchLookupRequest
+	5, // 6: swift.v1.SwiftCodes.ListByCountry:input_type -> swift.v1.ListByCountryRequest
+	6, // 7: swift.v1.SwiftCodes.AddCode:input_type -> swift.v1.AddCodeRequest
+	8, // 8: swift.v1.SwiftCodes.DeleteCode:input_type -> swift.v1.DeleteCodeRequest
+	2, // 9: swift.v1.SwiftCodes.GetCode:output_type -> swift.v1.GetCodeResponse
+	4, // 10: swift.v1.SwiftCodes.BatchLookup:output_type -> swift.v1.BatchLookupResponse
+	0, // 11: swift.v1.SwiftCodes.ListByCountry:output_type -> swift.v1.SwiftCode
+	7, // 12: swift.v1.SwiftCodes.AddCode:output_type -> swift.v1.AddCodeResponse
+	9, // 13: swift.v1.SwiftCodes.DeleteCode:output_type -> swift.v1.DeleteCodeResponse
+	9, // [9:14] is the sub-list for method output_type
+	4, // [4:9] is the sub-list for method input_type
+	4, // [4:4] is the sub-list for extension type_name
+	4, // [4:4] is the sub-list for extension extendee
+	0, // [0:4] is the sub-list for field type_name
+}
+
+func init() { file_swiftv1_swift_proto_init() }
+func file_swiftv1_swift_proto_init() {
+	if File_swiftv1_swift_proto != nil {
+		return
+	}
+	file_swiftv1_swift_proto_msgTypes[5].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_swiftv1_swift_proto_rawDesc), len(file_swiftv1_swift_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   10,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_swiftv1_swift_proto_goTypes,
+		DependencyIndexes: file_swiftv1_swift_proto_depIdxs,
+		MessageInfos:      file_swiftv1_swift_proto_msgTypes,
+	}.Build()
+	File_swiftv1_swift_proto = out.File
+	file_swiftv1_swift_proto_goTypes = nil
+	file_swiftv1_swift_proto_depIdxs = nil
+}
@@ -0,0 +1,293 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: swiftv1/swift.proto
+
+package swiftv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	SwiftCodes_GetCode_FullMethodName       = "/swift.v1.SwiftCodes/GetCode"
+	SwiftCodes_BatchLookup_FullMethodName   = "/swift.v1.SwiftCodes/BatchLookup"
+	SwiftCodes_ListByCountry_FullMethodName = "/swift.v1.SwiftCodes/ListByCountry"
+	SwiftCodes_AddCode_FullMethodName       = "/swift.v1.SwiftCodes/AddCode"
+	SwiftCodes_DeleteCode_FullMethodName    = "/swift.v1.SwiftCodes/DeleteCode"
+)
+
+// SwiftCodesClient is the client API for SwiftCodes service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// SwiftCodes serves the SWIFT code directory over gRPC, backed by the same
+// database as the REST API.
+type SwiftCodesClient interface {
+	// GetCode looks up a code, with its branches if it is a headquarter.
+	GetCode(ctx context.Context, in *GetCodeRequest, opts ...grpc.CallOption) (*GetCodeResponse, error)
+	// BatchLookup looks up several codes at once. Codes that aren't stored are
+	// listed in not_found instead of failing the call.
+	BatchLookup(ctx context.Context, in *BatchLookupRequest, opts ...grpc.CallOption) (*BatchLookupResponse, error)
+	// ListByCountry streams the codes of a country.
+	ListByCountry(ctx context.Context, in *ListByCountryRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[SwiftCode], error)
+	AddCode(ctx context.Context, in *AddCodeRequest, opts ...grpc.CallOption) (*AddCodeResponse, error)
+	// DeleteCode retires the current record of a code as of today.
+	DeleteCode(ctx context.Context, in *DeleteCodeRequest, opts ...grpc.CallOption) (*DeleteCodeResponse, error)
+}
+
+type swiftCodesClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSwiftCodesClient(cc grpc.ClientConnInterface) SwiftCodesClient {
+	return &swiftCodesClient{cc}
+}
+
+func (c *swiftCodesClient) GetCode(ctx context.Context, in *GetCodeRequest, opts ...grpc.CallOption) (*GetCodeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetCodeResponse)
+	err := c.cc.Invoke(ctx, SwiftCodes_GetCode_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *swiftCodesClient) BatchLookup(ctx context.Context, in *BatchLookupRequest, opts ...grpc.CallOption) (*BatchLookupResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchLookupResponse)
+	err := c.cc.Invoke(ctx, SwiftCodes_BatchLookup_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *swiftCodesClient) ListByCountry(ctx context.Context, in *ListByCountryRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[SwiftCode], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &SwiftCodes_ServiceDesc.Streams[0], SwiftCodes_ListByCountry_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ListByCountryRequest, SwiftCode]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SwiftCodes_ListByCountryClient = grpc.ServerStreamingClient[SwiftCode]
+
+func (c *swiftCodesClient) AddCode(ctx context.Context, in *AddCodeRequest, opts ...grpc.CallOption) (*AddCodeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AddCodeResponse)
+	err := c.cc.Invoke(ctx, SwiftCodes_AddCode_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *swiftCodesClient) DeleteCode(ctx context.Context, in *DeleteCodeRequest, opts ...grpc.CallOption) (*DeleteCodeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteCodeResponse)
+	err := c.cc.Invoke(ctx, SwiftCodes_DeleteCode_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SwiftCodesServer is the server API for SwiftCodes service.
+// All implementations must embed UnimplementedSwiftCodesServer
+// for forward compatibility.
+//
+// SwiftCodes serves the SWIFT code directory over gRPC, backed by the same
+// database as the REST API.
+type SwiftCodesServer interface {
+	// GetCode looks up a code, with its branches if it is a headquarter.
+	GetCode(context.Context, *GetCodeRequest) (*GetCodeResponse, error)
+	// BatchLookup looks up several codes at once. Codes that aren't stored are
+	// listed in not_found instead of failing the call.
+	BatchLookup(context.Context, *BatchLookupRequest) (*BatchLookupResponse, error)
+	// ListByCountry streams the codes of a country.
+	ListByCountry(*ListByCountryRequest, grpc.ServerStreamingServer[SwiftCode]) error
+	AddCode(context.Context, *AddCodeRequest) (*AddCodeResponse, error)
+	// DeleteCode retires the current record of a code as of today.
+	DeleteCode(context.Context, *DeleteCodeRequest) (*DeleteCodeResponse, error)
+	mustEmbedUnimplementedSwiftCodesServer()
+}
+
+// UnimplementedSwiftCodesServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedSwiftCodesServer struct{}
+
+func (UnimplementedSwiftCodesServer) GetCode(context.Context, *GetCodeRequest) (*GetCodeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCode not implemented")
+}
+func (UnimplementedSwiftCodesServer) BatchLookup(context.Context, *BatchLookupRequest) (*BatchLookupResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchLookup not implemented")
+}
+func (UnimplementedSwiftCodesServer) ListByCountry(*ListByCountryRequest, grpc.ServerStreamingServer[SwiftCode]) error {
+	return status.Errorf(codes.Unimplemented, "method ListByCountry not implemented")
+}
+func (UnimplementedSwiftCodesServer) AddCode(context.Context, *AddCodeRequest) (*AddCodeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddCode not implemented")
+}
+func (UnimplementedSwiftCodesServer) DeleteCode(context.Context, *DeleteCodeRequest) (*DeleteCodeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteCode not implemented")
+}
+func (UnimplementedSwiftCodesServer) mustEmbedUnimplementedSwiftCodesServer() {}
+func (UnimplementedSwiftCodesServer) testEmbeddedByValue()                    {}
+
+// UnsafeSwiftCodesServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SwiftCodesServer will
+// result in compilation errors.
+type UnsafeSwiftCodesServer interface {
+	mustEmbedUnimplementedSwiftCodesServer()
+}
+
+func RegisterSwiftCodesServer(s grpc.ServiceRegistrar, srv SwiftCodesServer) {
+	// If the following call pancis, it indicates UnimplementedSwiftCodesServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&SwiftCodes_ServiceDesc, srv)
+}
+
+func _SwiftCodes_GetCode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCodeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwiftCodesServer).GetCode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SwiftCodes_GetCode_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwiftCodesServer).GetCode(ctx, req.(*GetCodeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SwiftCodes_BatchLookup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchLookupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwiftCodesServer).BatchLookup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SwiftCodes_BatchLookup_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwiftCodesServer).BatchLookup(ctx, req.(*BatchLookupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SwiftCodes_ListByCountry_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListByCountryRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SwiftCodesServer).ListByCountry(m, &grpc.GenericServerStream[ListByCountryRequest, SwiftCode]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SwiftCodes_ListByCountryServer = grpc.ServerStreamingServer[SwiftCode]
+
+func _SwiftCodes_AddCode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddCodeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwiftCodesServer).AddCode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SwiftCodes_AddCode_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwiftCodesServer).AddCode(ctx, req.(*AddCodeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SwiftCodes_DeleteCode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteCodeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwiftCodesServer).DeleteCode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SwiftCodes_DeleteCode_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwiftCodesServer).DeleteCode(ctx, req.(*DeleteCodeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// SwiftCodes_ServiceDesc is the grpc.ServiceDesc for SwiftCodes service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var SwiftCodes_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "swift.v1.SwiftCodes",
+	HandlerType: (*SwiftCodesServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetCode",
+			Handler:    _SwiftCodes_GetCode_Handler,
+		},
+		{
+			MethodName: "BatchLookup",
+			Handler:    _SwiftCodes_BatchLookup_Handler,
+		},
+		{
+			MethodName: "AddCode",
+			Handler:    _SwiftCodes_AddCode_Handler,
+		},
+		{
+			MethodName: "DeleteCode",
+			Handler:    _SwiftCodes_DeleteCode_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ListByCountry",
+			Handler:       _SwiftCodes_ListByCountry_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "swiftv1/swift.proto",
+}
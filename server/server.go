@@ -2,59 +2,172 @@ package server
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	"github.com/labstack/gommon/log"
+	"github.com/rtsncs/remitly-swift-api/config"
 	"github.com/rtsncs/remitly-swift-api/database"
 	"github.com/rtsncs/remitly-swift-api/handler"
+	"github.com/rtsncs/remitly-swift-api/ratelimit"
+	"github.com/rtsncs/remitly-swift-api/rpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
 )
 
-func Run() {
-	host := os.Getenv("HOST")
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
-	}
+var logLevels = map[string]log.Lvl{
+	"debug": log.DEBUG,
+	"info":  log.INFO,
+	"warn":  log.WARN,
+	"error": log.ERROR,
+	"off":   log.OFF,
+}
 
+// Run serves the API, and the gRPC API when it has a port, until SIGINT or
+// SIGTERM. It then reports the server as not ready, waits for the shutdown
+// delay for load balancers to notice and gives in-flight requests and
+// imports the drain timeout to finish. When either server fails, the other
+// is shut down the same way, without the delay. An error is returned when
+// the server fails or doesn't shut down cleanly.
+func Run(cfg config.Config) error {
 	e := echo.New()
-	e.Logger.SetLevel(log.INFO)
-	db, err := database.Connect(context.Background())
+	e.Logger.SetLevel(logLevels[cfg.Log.Level])
+	db, err := database.ConnectWithOptions(context.Background(), cfg.Database)
 	if err != nil {
-		e.Logger.Fatalf("Failed to connect to the database")
+		return fmt.Errorf("Failed to connect to the database: %w", err)
 	}
 	defer db.Close()
-	h := handler.New(&db)
+	limits, err := rateLimitStore(cfg)
+	if err != nil {
+		return fmt.Errorf("Failed to connect to the rate limit store: %w", err)
+	}
+	defer limits.Close()
+	h := handler.New(db, handler.Config{
+		AdminAPIKey:    cfg.Auth.AdminAPIKey,
+		ReadYourWrites: time.Duration(cfg.Server.ReadYourWrites),
+		RateLimits: handler.RateLimits{
+			Store:  limits,
+			Lookup: cfg.RateLimit.Lookup,
+			Write:  cfg.RateLimit.Write,
+			Admin:  cfg.RateLimit.Admin,
+			Keys:   cfg.RateLimit.Keys,
+		},
+	})
 	e.Logger.Info("Connected to the database")
+	// Clients are rate limited by IP address, so X-Forwarded-For is only
+	// trusted from proxies on private networks.
+	e.IPExtractor = echo.ExtractIPFromXFFHeader()
 	e.Use(middleware.Logger())
 	e.Use(middleware.Recover())
 
-	g := e.Group("/v1/swift-codes")
-	g.GET("/:code", h.GetCode)
-	g.GET("/country/:countryCode", h.GetByCountryCode)
-	g.POST("", h.AddCode)
-	g.DELETE("/:code", h.DeleteCode)
+	h.Register(e)
 
-	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
-	go func() {
-		if err := e.Start(host + ":" + port); err != nil && err != http.ErrServerClosed {
-			e.Logger.Fatalf("Server error: %v\n", err)
+	var grpcServer *grpc.Server
+	var grpcHealth *health.Server
+	grpcErr := make(chan error, 1)
+	if cfg.Server.GRPCPort != 0 {
+		lis, err := net.Listen("tcp", cfg.Server.GRPCAddress())
+		if err != nil {
+			return fmt.Errorf("Failed to listen for gRPC: %w", err)
 		}
+		grpcServer, grpcHealth = rpc.NewGRPCServer(db, &h)
+		go func() {
+			grpcErr <- grpcServer.Serve(lis)
+		}()
+		e.Logger.Infof("gRPC server started on %s", lis.Addr())
+	}
+
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- e.Start(cfg.Server.Address())
 	}()
 
-	<-ctx.Done()
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	// A failed server's error is put back to be reported with the others
+	// once everything has shut down.
+	failed := true
+	select {
+	case err := <-serverErr:
+		serverErr <- err
+	case err := <-grpcErr:
+		grpcErr <- err
+	case <-ctx.Done():
+		failed = false
+	}
+	// A second signal stops the process right away.
+	stop()
+
+	if failed {
+		e.Logger.Error("Server failed, shutting down")
+	} else {
+		e.Logger.Info("Shutdown signal received")
+	}
+	h.Drain()
+	if grpcHealth != nil {
+		grpcHealth.Shutdown()
+	}
+	if !failed {
+		time.Sleep(time.Duration(cfg.Server.ShutdownDelay))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.Server.DrainTimeout))
 	defer cancel()
-	e.Logger.Info("Shutdown signal received")
+	var errs []error
 	if err := e.Shutdown(ctx); err != nil {
-		e.Logger.Fatalf("Error during shutdown: %v\n", err)
-	} else {
-		e.Logger.Info("Shutting down the server")
+		errs = append(errs, fmt.Errorf("Failed to drain requests: %w", err))
+	}
+	if err := h.Shutdown(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("Failed to finish imports: %w", err))
+	}
+	if grpcServer != nil {
+		if err := stopGRPC(ctx, grpcServer); err != nil {
+			errs = append(errs, fmt.Errorf("Failed to drain gRPC calls: %w", err))
+		}
+		if err := <-grpcErr; err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+			errs = append(errs, fmt.Errorf("gRPC server error: %w", err))
+		}
+	}
+	if err := <-serverErr; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		errs = append(errs, fmt.Errorf("Server error: %w", err))
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	e.Logger.Info("Server stopped")
+	return nil
+}
+
+// stopGRPC waits for in-flight calls to finish, and cancels those still
+// running when ctx is done.
+func stopGRPC(ctx context.Context, s *grpc.Server) error {
+	stopped := make(chan struct{})
+	go func() {
+		s.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		s.Stop()
+		<-stopped
+		return ctx.Err()
+	}
+}
+
+func rateLimitStore(cfg config.Config) (ratelimit.Store, error) {
+	if cfg.RateLimit.Store == "postgres" {
+		return ratelimit.ConnectPostgres(context.Background(), cfg.Database.URL)
 	}
+	return ratelimit.NewMemory(), nil
 }
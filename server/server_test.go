@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/testcontainers/testcontainers-go/modules/compose"
@@ -17,6 +18,7 @@ import (
 var (
 	address   string
 	apiPrefix = "/v1/swift-codes"
+	today     = time.Now().UTC().Format(time.DateOnly)
 )
 
 func TestAddCode(t *testing.T) {
@@ -160,6 +162,7 @@ func TestDeleteCode(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			req, err := http.NewRequest(http.MethodDelete, address+apiPrefix+tc.input, nil)
 			assert.NoError(t, err, "failed to create request")
+			req.Header.Set("If-Match", "*")
 
 			resp, err := http.DefaultClient.Do(req)
 			assert.NoError(t, err, "failed to send request")
@@ -187,19 +190,19 @@ func TestGetCode(t *testing.T) {
 			name:   "valid branch",
 			input:  "/TESTUS33ABC",
 			status: http.StatusOK,
-			output: `{"address":"123 Test Street","bankName":"Test Bank","countryISO2":"US","countryName":"UNITED STATES","isHeadquarter":false,"swiftCode":"TESTUS33ABC"}`,
+			output: `{"address":"123 Test Street","bankName":"Test Bank","countryISO2":"US","countryName":"UNITED STATES","isHeadquarter":false,"isPassive":false,"isReverseBilling":false,"isTest":false,"swiftCode":"TESTUS33ABC","validFrom":"` + today + `","bic":{"institution":"TEST","country":"US","location":"33","branch":"ABC"}}`,
 		},
 		{
 			name:   "valid headquarter",
 			input:  "/TESTUS33XXX",
 			status: http.StatusOK,
-			output: `{"address":"123 Test Street","bankName":"Test Bank","countryISO2":"US","countryName":"UNITED STATES","isHeadquarter":true,"swiftCode":"TESTUS33XXX","branches":[{"address":"123 Test Street","bankName":"Test Bank","countryISO2":"US","isHeadquarter":false,"swiftCode":"TESTUS33ABC"}]}`,
+			output: `{"address":"123 Test Street","bankName":"Test Bank","countryISO2":"US","countryName":"UNITED STATES","isHeadquarter":true,"isPassive":false,"isReverseBilling":false,"isTest":false,"swiftCode":"TESTUS33XXX","validFrom":"` + today + `","bic":{"institution":"TEST","country":"US","location":"33","branch":"XXX"},"branches":[{"address":"123 Test Street","bankName":"Test Bank","countryISO2":"US","isHeadquarter":false,"isPassive":false,"isReverseBilling":false,"isTest":false,"swiftCode":"TESTUS33ABC","validFrom":"` + today + `","bic":{"institution":"TEST","country":"US","location":"33","branch":"ABC"}}]}`,
 		},
 		{
 			name:   "valid headquarter no branches",
 			input:  "/TESTUS23XXX",
 			status: http.StatusOK,
-			output: `{"address":"123 Test Street","bankName":"Test Bank","countryISO2":"US","countryName":"UNITED STATES","isHeadquarter":true,"swiftCode":"TESTUS23XXX","branches":[]}`,
+			output: `{"address":"123 Test Street","bankName":"Test Bank","countryISO2":"US","countryName":"UNITED STATES","isHeadquarter":true,"isPassive":false,"isReverseBilling":false,"isTest":false,"swiftCode":"TESTUS23XXX","validFrom":"` + today + `","bic":{"institution":"TEST","country":"US","location":"23","branch":"XXX"},"branches":[]}`,
 		},
 		{
 			name:   "nonexistent code",
@@ -237,7 +240,7 @@ func TestGetByCountryCode(t *testing.T) {
 			name:   "valid country",
 			input:  "US",
 			status: http.StatusOK,
-			output: `{"countryISO2":"US","countryName":"UNITED STATES","swiftCodes":[{"address":"123 Test Street","bankName":"Test Bank","countryISO2":"US","isHeadquarter":true,"swiftCode":"TESTUS33XXX"},{"address":"123 Test Street","bankName":"Test Bank","countryISO2":"US","isHeadquarter":false,"swiftCode":"TESTUS33ABC"},{"address":"123 Test Street","bankName":"Test Bank","countryISO2":"US","isHeadquarter":true,"swiftCode":"TESTUS23XXX"}]}`,
+			output: `{"countryISO2":"US","countryName":"UNITED STATES","swiftCodes":[{"address":"123 Test Street","bankName":"Test Bank","countryISO2":"US","isHeadquarter":true,"isPassive":false,"isReverseBilling":false,"isTest":false,"swiftCode":"TESTUS33XXX","validFrom":"` + today + `","bic":{"institution":"TEST","country":"US","location":"33","branch":"XXX"}},{"address":"123 Test Street","bankName":"Test Bank","countryISO2":"US","isHeadquarter":false,"isPassive":false,"isReverseBilling":false,"isTest":false,"swiftCode":"TESTUS33ABC","validFrom":"` + today + `","bic":{"institution":"TEST","country":"US","location":"33","branch":"ABC"}},{"address":"123 Test Street","bankName":"Test Bank","countryISO2":"US","isHeadquarter":true,"isPassive":false,"isReverseBilling":false,"isTest":false,"swiftCode":"TESTUS23XXX","validFrom":"` + today + `","bic":{"institution":"TEST","country":"US","location":"23","branch":"XXX"}}]}`,
 		},
 		{
 			name:   "nonexistent country",